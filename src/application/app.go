@@ -7,14 +7,17 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 
 	"photo-service/src/amazon"
 	"photo-service/src/handler"
+	"photo-service/src/interfaces"
 	"photo-service/src/internal/database"
 	"photo-service/src/repositories"
 	"photo-service/src/services"
@@ -26,7 +29,17 @@ type App struct {
 	database     *database.Queries
 	s3Connection *s3.Client
 	// kafkaClient *kafka.KafkaClient
-	// rdb    *redis.Client
+	rdb *redis.Client
+	// Swept for expired direct and resumable uploads, idempotency keys and trash while the
+	// server runs.
+	uploadIntents interfaces.IUploadIntentService
+	tusUploads    interfaces.ITusUploadService
+	idempotency   interfaces.IIdempotencyService
+	photos        interfaces.IPhotoService
+	// Runs queued background jobs, such as processing new photos.
+	workers *services.WorkerPool
+	// Streams events to connected clients.
+	events *services.EventBroker
 }
 
 func New() *App {
@@ -82,6 +95,44 @@ func New() *App {
 		log.Fatal("failed to create S3 client:", err)
 	}
 
+	// Text-search configuration used to index and query photo descriptions
+	searchLanguage := os.Getenv("SEARCH_LANGUAGE")
+	if searchLanguage == "" {
+		searchLanguage = "english"
+	}
+	// Every photo insert and search casts it to regconfig, so a typo would break them all
+	if err := conn.QueryRowContext(context.Background(), "SELECT $1::regconfig::text", searchLanguage).Scan(&searchLanguage); err != nil {
+		log.Fatal("invalid SEARCH_LANGUAGE:", err)
+	}
+
+	// Storage quota tiers, e.g. "free:5GiB:1000,pro:200GiB:0"; storage is unlimited without them
+	storageTiers, err := services.ParseStorageTiers(os.Getenv("STORAGE_TIERS"))
+	if err != nil {
+		log.Fatal("invalid STORAGE_TIERS:", err)
+	}
+
+	// Keys used to verify the bearer tokens of API requests
+	tokenVerifier, err := services.NewJWTVerifier(context.Background(), services.JWTConfig{
+		HS256Secret: []byte(os.Getenv("JWT_HS256_SECRET")),
+		JWKSFile:    os.Getenv("JWT_JWKS_FILE"),
+		JWKSURL:     os.Getenv("JWT_JWKS_URL"),
+		Issuer:      os.Getenv("JWT_ISSUER"),
+		Audience:    os.Getenv("JWT_AUDIENCE"),
+	})
+	if err != nil {
+		log.Fatal("failed to configure JWT authentication:", err)
+	}
+
+	// Redis is optional; it shares rate limits between instances of the service
+	var rdb *redis.Client
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisOptions, err := redis.ParseURL(redisURL)
+		if err != nil {
+			log.Fatal("invalid REDIS_URL:", err)
+		}
+		rdb = redis.NewClient(redisOptions)
+	}
+
 	// Initialize Kafka client
 	// kafkaBrokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
 	// kafkaClient, err := kafka.NewKafkaClient(kafkaBrokers)
@@ -89,22 +140,205 @@ func New() *App {
 	// 	log.Fatal("failed to create Kafka client:", err)
 	// }
 
+	// Token buckets of the rate limits, shared by all instances through Redis when it is set
+	var rateLimitStore interfaces.IRateLimitStore = services.NewMemoryRateLimitStore()
+	if rdb != nil {
+		rateLimitStore = services.NewRedisRateLimitStore(rdb)
+	}
+
 	// Initialize repositories
-	photoRepo := repositories.NewPhotoRepo(databaseConn)
+	photoRepo := repositories.NewPhotoRepo(conn, databaseConn, searchLanguage)
 	photoMetadataRepo := repositories.NewPhotoMetadataRepo(databaseConn)
+	privateZoneRepo := repositories.NewPrivateZoneRepo(databaseConn)
+	routeRepo := repositories.NewRouteRepo(databaseConn)
+	albumRepo := repositories.NewAlbumRepo(conn, databaseConn)
+	tagRepo := repositories.NewTagRepo(conn, databaseConn)
+	smartAlbumRepo := repositories.NewSmartAlbumRepo(databaseConn)
+	shareLinkRepo := repositories.NewShareLinkRepo(databaseConn)
+	albumMemberRepo := repositories.NewAlbumMemberRepo(databaseConn)
+	apiKeyRepo := repositories.NewAPIKeyRepo(databaseConn)
+	storageUsageRepo := repositories.NewStorageUsageRepo(databaseConn)
+	tusUploadRepo := repositories.NewTusUploadRepo(conn, databaseConn)
+	uploadIntentRepo := repositories.NewUploadIntentRepo(databaseConn)
+	idempotencyKeyRepo := repositories.NewIdempotencyKeyRepo(databaseConn)
+	jobRepo := repositories.NewJobRepo(databaseConn)
+	webhookRepo := repositories.NewWebhookRepo(conn, databaseConn)
+	eventRepo := repositories.NewEventRepo(conn, databaseConn, dbURL)
+	photoVersionRepo := repositories.NewPhotoVersionRepo(conn, databaseConn)
 
 	// Initialize services
+	storageQuotaService, err := services.NewStorageQuotaService(storageUsageRepo, storageTiers, os.Getenv("STORAGE_DEFAULT_TIER"))
+	if err != nil {
+		log.Fatal("failed to configure storage quotas:", err)
+	}
+	accessPolicy := services.NewAccessPolicy(photoRepo, albumRepo, albumMemberRepo)
 	s3UploaderService := services.NewS3Uploader(s3Conn, awsBucket)
-	photoService := services.NewPhotoService(photoRepo, s3UploaderService, photoMetadataRepo)
+	// Photo versions are kept as object versions when the bucket has versioning enabled,
+	// unless S3_BUCKET_VERSIONING is "off"; otherwise each version gets a key of its own
+	if os.Getenv("S3_BUCKET_VERSIONING") != "off" {
+		versioned, err := s3UploaderService.DetectVersioning(context.Background())
+		if err != nil {
+			log.Printf("Could not check bucket versioning, storing photo versions under their own keys: %v", err)
+		} else if versioned {
+			log.Printf("Bucket versioning is enabled, keeping photo versions as object versions")
+		}
+	}
+	webhookService := services.NewWebhookService(
+		webhookRepo,
+		time.Duration(envInt("WEBHOOK_TIMEOUT_SECONDS", 10))*time.Second,
+		envInt("WEBHOOK_DISABLE_AFTER", 5),
+		os.Getenv("WEBHOOK_ALLOW_INSECURE") == "true",
+	)
+	eventBroker := services.NewEventBroker(
+		eventRepo,
+		envInt("EVENTS_REPLAY_SIZE", 100),
+		time.Duration(envInt("EVENTS_REPLAY_MINUTES", 15))*time.Minute,
+	)
+	events := services.EventPublishers{eventBroker, webhookService}
+	photoService := services.NewPhotoService(
+		photoRepo,
+		s3UploaderService,
+		s3UploaderService,
+		photoMetadataRepo,
+		tagRepo,
+		accessPolicy,
+		storageQuotaService,
+		events,
+		time.Duration(envInt("PHOTO_TRASH_RETENTION_DAYS", 30))*24*time.Hour,
+	)
+	photoVersionService := services.NewPhotoVersionService(
+		photoVersionRepo,
+		s3UploaderService,
+		s3UploaderService,
+		accessPolicy,
+		storageQuotaService,
+		events,
+	)
+	geotagService := services.NewGeotagService(photoMetadataRepo)
+	privateZoneService := services.NewPrivateZoneService(privateZoneRepo)
+	routeService := services.NewRouteService(routeRepo)
+	albumService := services.NewAlbumService(albumRepo, albumMemberRepo, accessPolicy)
+	tagService := services.NewTagService(tagRepo, accessPolicy)
+	smartAlbumService := services.NewSmartAlbumService(smartAlbumRepo, photoRepo)
+	shareLinkService := services.NewShareLinkService(
+		shareLinkRepo,
+		photoRepo,
+		albumRepo,
+		s3UploaderService,
+		accessPolicy,
+		rateLimitStore,
+		interfaces.RateLimit{
+			Rate:  float64(envInt("SHARE_PASSWORD_RATE_PER_LINK", 10)) / 60,
+			Burst: envInt("SHARE_PASSWORD_BURST_PER_LINK", 10),
+		},
+	)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	tusUploadService := services.NewTusUploadService(
+		tusUploadRepo,
+		s3UploaderService,
+		photoService,
+		storageQuotaService,
+		int64(envInt("UPLOAD_MAX_RESUMABLE_MB", 200))<<20,
+		time.Duration(envInt("UPLOAD_RESUMABLE_EXPIRY_HOURS", 24))*time.Hour,
+	)
+	uploadIntentService := services.NewUploadIntentService(
+		uploadIntentRepo,
+		s3UploaderService,
+		photoService,
+		storageQuotaService,
+		int64(envInt("UPLOAD_MAX_DIRECT_MB", 200))<<20,
+		time.Duration(envInt("UPLOAD_PRESIGN_TTL_MINUTES", 15))*time.Minute,
+	)
+	idempotencyService := services.NewIdempotencyService(
+		idempotencyKeyRepo,
+		time.Duration(envInt("IDEMPOTENCY_KEY_TTL_HOURS", 24))*time.Hour,
+	)
+
+	// Background jobs
+	workerPool := services.NewWorkerPool(
+		jobRepo,
+		envInt("JOB_WORKERS", 4),
+		time.Duration(envInt("JOB_POLL_INTERVAL_MS", 1000))*time.Millisecond,
+	)
+	workerPool.Register(interfaces.JobKindProcessPhoto, services.ProcessPhotoJobs(photoService))
+	workerPool.Register(interfaces.JobKindDeliverWebhook, services.DeliverWebhookJobs(webhookService))
+
+	// Upload backpressure: requests per minute and burst per user and per IP, and the
+	// upload bytes this process buffers at once
+	photoUploadLimits := uploadLimits{
+		store: rateLimitStore,
+		perUser: interfaces.RateLimit{
+			Rate:  float64(envInt("UPLOAD_RATE_PER_USER", 30)) / 60,
+			Burst: envInt("UPLOAD_BURST_PER_USER", 10),
+		},
+		perIP: interfaces.RateLimit{
+			Rate:  float64(envInt("UPLOAD_RATE_PER_IP", 60)) / 60,
+			Burst: envInt("UPLOAD_BURST_PER_IP", 20),
+		},
+		inFlight:        services.NewInFlightLimiter(int64(envInt("UPLOAD_MAX_INFLIGHT_MB", 256)) << 20),
+		maxRequestBytes: int64(envInt("UPLOAD_MAX_REQUEST_MB", 32)) << 20,
+		maxBatchBytes:   int64(envInt("UPLOAD_MAX_BATCH_MB", 256)) << 20,
+		tusBufferBytes:  services.TusPartSize,
+	}
 
 	// Initialize handlers
 	photoHandler := handler.NewPhotoHandler(photoService)
+	geotagHandler := handler.NewGeotagHandler(geotagService)
+	privateZoneHandler := handler.NewPrivateZoneHandler(privateZoneService)
+	routeHandler := handler.NewRouteHandler(routeService)
+	albumHandler := handler.NewAlbumHandler(albumService)
+	tagHandler := handler.NewTagHandler(tagService)
+	smartAlbumHandler := handler.NewSmartAlbumHandler(smartAlbumService)
+	shareLinkHandler := handler.NewShareLinkHandler(shareLinkService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	storageHandler := handler.NewStorageHandler(storageQuotaService)
+	tusHandler := handler.NewTusHandler(tusUploadService)
+	uploadIntentHandler := handler.NewUploadIntentHandler(uploadIntentService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	eventStreamHandler := handler.NewEventStreamHandler(eventBroker)
+	photoVersionHandler := handler.NewPhotoVersionHandler(photoVersionService)
+
+	router := loadRoutes(
+		photoHandler,
+		geotagHandler,
+		privateZoneHandler,
+		routeHandler,
+		albumHandler,
+		tagHandler,
+		smartAlbumHandler,
+		shareLinkHandler,
+		apiKeyHandler,
+		storageHandler,
+		tusHandler,
+		uploadIntentHandler,
+		webhookHandler,
+		eventStreamHandler,
+		photoVersionHandler,
+		tokenVerifier,
+		apiKeyService,
+		idempotencyService,
+		photoUploadLimits,
+		shareLinkLimits{
+			store: rateLimitStore,
+			perIP: interfaces.RateLimit{
+				Rate:  float64(envInt("SHARE_RATE_PER_IP", 30)) / 60,
+				Burst: envInt("SHARE_BURST_PER_IP", 10),
+			},
+		},
+	)
 
 	app := &App{
-		router:       loadRoutes(photoHandler),
-		dbConn:       conn,
-		database:     databaseConn,
-		s3Connection: s3Conn,
+		router:        router,
+		dbConn:        conn,
+		database:      databaseConn,
+		s3Connection:  s3Conn,
+		rdb:           rdb,
+		uploadIntents: uploadIntentService,
+		tusUploads:    tusUploadService,
+		idempotency:   idempotencyService,
+		photos:        photoService,
+		workers:       workerPool,
+		events:        eventBroker,
 		// kafkaClient: kafkaClient,
 	}
 	return app
@@ -125,6 +359,45 @@ func (a *App) Start(ctx context.Context) error {
 
 	fmt.Println("Starting server on port", port)
 
+	a.workers.Start()
+	go a.events.Run(ctx)
+	// Event streams never end on their own; close them so shutdown does not wait on them.
+	server.RegisterOnShutdown(a.events.Close)
+
+	go runEvery(ctx, uploadIntentCleanupInterval, func(ctx context.Context) {
+		removed, err := a.uploadIntents.CleanupExpired(ctx)
+		if err != nil {
+			log.Printf("Error cleaning up expired upload intents: %v", err)
+		}
+		if removed > 0 {
+			log.Printf("Removed %d expired upload intents", removed)
+		}
+	})
+	go runEvery(ctx, uploadIntentCleanupInterval, func(ctx context.Context) {
+		removed, err := a.tusUploads.CleanupExpired(ctx)
+		if err != nil {
+			log.Printf("Error cleaning up expired resumable uploads: %v", err)
+		}
+		if removed > 0 {
+			log.Printf("Removed %d expired resumable uploads", removed)
+		}
+	})
+	go runEvery(ctx, idempotencyKeyPurgeInterval, func(ctx context.Context) {
+		if _, err := a.idempotency.PurgeExpired(ctx); err != nil {
+			log.Printf("Error purging expired idempotency keys: %v", err)
+		}
+	})
+
+	go runEvery(ctx, trashPurgeInterval, func(ctx context.Context) {
+		purged, err := a.photos.PurgeTrash(ctx)
+		if err != nil {
+			log.Printf("Error purging trashed photos: %v", err)
+		}
+		if purged > 0 {
+			log.Printf("Purged %d trashed photos", purged)
+		}
+	})
+
 	ch := make(chan error, 1)
 
 	go func() {
@@ -165,6 +438,22 @@ func (a *App) Shutdown() error {
 	// 	}
 	// }
 
+	// Let running jobs finish while the database is still open
+	if a.workers != nil {
+		timeout, cancel := context.WithTimeout(context.Background(), workerDrainTimeout)
+		defer cancel()
+		if err := a.workers.Shutdown(timeout); err != nil {
+			log.Printf("Jobs still running at shutdown will be retried: %v", err)
+		}
+	}
+
+	// Close the Redis client
+	if a.rdb != nil {
+		if err := a.rdb.Close(); err != nil {
+			return fmt.Errorf("error closing Redis client: %w", err)
+		}
+	}
+
 	// Close the database connection
 	if a.database != nil {
 		if err := a.dbConn.Close(); err != nil {
@@ -173,3 +462,42 @@ func (a *App) Shutdown() error {
 	}
 	return nil
 }
+
+const (
+	// uploadIntentCleanupInterval is how often expired direct and resumable uploads are
+	// removed.
+	uploadIntentCleanupInterval = 10 * time.Minute
+	// idempotencyKeyPurgeInterval is how often idempotency keys past retention are deleted.
+	idempotencyKeyPurgeInterval = time.Hour
+	// trashPurgeInterval is how often photos past the trash retention are purged.
+	trashPurgeInterval = time.Hour
+	// workerDrainTimeout is how long shutdown waits for running jobs.
+	workerDrainTimeout = 30 * time.Second
+)
+
+// runEvery runs task every interval until ctx is done.
+func runEvery(ctx context.Context, interval time.Duration, task func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			task(ctx)
+		}
+	}
+}
+
+// envInt reads a non-negative integer setting, or fallback when it is not set.
+func envInt(name string, fallback int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Fatalf("%s must be a non-negative integer", name)
+	}
+	return value
+}
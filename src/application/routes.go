@@ -6,18 +6,63 @@ import (
 	"github.com/go-chi/cors"
 
 	"photo-service/src/handler"
+	"photo-service/src/interfaces"
 )
 
-func loadRoutes(photoHandler *handler.PhotoHandler) *chi.Mux {
+// uploadLimits configures the backpressure on photo uploads.
+type uploadLimits struct {
+	store           interfaces.IRateLimitStore
+	perUser         interfaces.RateLimit
+	perIP           interfaces.RateLimit
+	inFlight        interfaces.IInFlightLimiter
+	maxRequestBytes int64
+	maxBatchBytes   int64
+	// tusBufferBytes is what a resumable upload write holds while it streams its body.
+	tusBufferBytes int64
+}
+
+// shareLinkLimits configures the rate limit on opening public share links.
+type shareLinkLimits struct {
+	store interfaces.IRateLimitStore
+	perIP interfaces.RateLimit
+}
+
+func loadRoutes(
+	photoHandler *handler.PhotoHandler,
+	geotagHandler *handler.GeotagHandler,
+	privateZoneHandler *handler.PrivateZoneHandler,
+	routeHandler *handler.RouteHandler,
+	albumHandler *handler.AlbumHandler,
+	tagHandler *handler.TagHandler,
+	smartAlbumHandler *handler.SmartAlbumHandler,
+	shareLinkHandler *handler.ShareLinkHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	storageHandler *handler.StorageHandler,
+	tusHandler *handler.TusHandler,
+	uploadIntentHandler *handler.UploadIntentHandler,
+	webhookHandler *handler.WebhookHandler,
+	eventStreamHandler *handler.EventStreamHandler,
+	photoVersionHandler *handler.PhotoVersionHandler,
+	tokenVerifier interfaces.ITokenVerifier,
+	apiKeyService interfaces.IAPIKeyService,
+	idempotencyService interfaces.IIdempotencyService,
+	uploadLimits uploadLimits,
+	shareLinkLimits shareLinkLimits,
+) *chi.Mux {
 	router := chi.NewRouter()
 
 	router.Use(middleware.Logger)
 
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"*"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins: []string{"https://*", "http://*"},
+		AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"*"},
+		ExposedHeaders: []string{
+			"Link", "Location", "Retry-After",
+			"Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size",
+			"Upload-Offset", "Upload-Length", "Upload-Metadata", "X-Photo-Id",
+			"Idempotent-Replayed",
+		},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -27,8 +72,73 @@ func loadRoutes(photoHandler *handler.PhotoHandler) *chi.Mux {
 	// Starndard health check endpoint
 	v1Router.Get("/health", handler.HandlerReadiness)
 
-	v1Router.Route("/photos", func(router chi.Router) {
-		loadPhotoRoutes(router, photoHandler)
+	// Public share links; the token is the only credential. Visitors are limited per IP since
+	// there is no user to limit, which also slows down guessing link passwords.
+	v1Router.With(
+		handler.RateLimit(shareLinkLimits.store, "share-link", interfaces.RateLimit{}, shareLinkLimits.perIP),
+	).Get("/s/{token}", shareLinkHandler.OpenShareLink)
+
+	// Administration, for admin tokens and API keys with the admin scope.
+	v1Router.Route("/admin", func(router chi.Router) {
+		router.Use(handler.Authenticate(tokenVerifier, apiKeyService))
+		router.Use(handler.RequireScope(interfaces.ScopeAdmin))
+		router.Route("/api-keys", func(router chi.Router) {
+			router.Post("/", apiKeyHandler.CreateAPIKey)
+			router.Get("/", apiKeyHandler.ListAPIKeys)
+			router.Delete("/{id}", apiKeyHandler.RevokeAPIKey)
+		})
+		router.Put("/users/{id}/storage-tier", storageHandler.SetTier)
+	})
+
+	// Everything else acts on behalf of the user named by the bearer token or API key.
+	v1Router.Group(func(v1Router chi.Router) {
+		v1Router.Use(handler.Authenticate(tokenVerifier, apiKeyService))
+		// Photo queries are POSTed for their JSON body but only read.
+		v1Router.Use(handler.RequirePhotoScopes("/v1/photos/query"))
+
+		v1Router.Route("/photos", func(router chi.Router) {
+			loadPhotoRoutes(router, photoHandler, tagHandler, shareLinkHandler, uploadIntentHandler, photoVersionHandler, idempotencyService, uploadLimits)
+		})
+
+		v1Router.Route("/trash", func(router chi.Router) {
+			router.Get("/", photoHandler.ListTrash)
+			router.Delete("/", photoHandler.EmptyTrash)
+		})
+
+		v1Router.Route("/uploads", func(router chi.Router) {
+			loadUploadRoutes(router, tusHandler, uploadLimits)
+		})
+
+		v1Router.Route("/users/{id}", func(router chi.Router) {
+			loadUserRoutes(router, geotagHandler, routeHandler, storageHandler)
+		})
+
+		v1Router.Route("/private-zones", func(router chi.Router) {
+			loadPrivateZoneRoutes(router, privateZoneHandler)
+		})
+
+		v1Router.Route("/albums", func(router chi.Router) {
+			loadAlbumRoutes(router, albumHandler, shareLinkHandler)
+		})
+
+		v1Router.Route("/smart-albums", func(router chi.Router) {
+			loadSmartAlbumRoutes(router, smartAlbumHandler)
+		})
+
+		v1Router.Route("/shares", func(router chi.Router) {
+			router.Get("/", shareLinkHandler.ListShareLinks)
+			router.Delete("/{id}", shareLinkHandler.RevokeShareLink)
+		})
+
+		v1Router.Route("/tags", func(router chi.Router) {
+			router.Get("/", tagHandler.SearchTags)
+		})
+
+		v1Router.Route("/webhooks", func(router chi.Router) {
+			loadWebhookRoutes(router, webhookHandler)
+		})
+
+		v1Router.Get("/events", eventStreamHandler.StreamEvents)
 	})
 
 	router.Mount("/v1", v1Router)
@@ -36,6 +146,115 @@ func loadRoutes(photoHandler *handler.PhotoHandler) *chi.Mux {
 	return router
 }
 
-func loadPhotoRoutes(router chi.Router, photoHandler *handler.PhotoHandler) {
-	router.Post("/upload", photoHandler.CreatePhoto)
+func loadPhotoRoutes(
+	router chi.Router,
+	photoHandler *handler.PhotoHandler,
+	tagHandler *handler.TagHandler,
+	shareLinkHandler *handler.ShareLinkHandler,
+	uploadIntentHandler *handler.UploadIntentHandler,
+	photoVersionHandler *handler.PhotoVersionHandler,
+	idempotencyService interfaces.IIdempotencyService,
+	uploadLimits uploadLimits,
+) {
+	router.Get("/", photoHandler.ListPhotos)
+	router.Get("/search", photoHandler.SearchPhotos)
+	router.Post("/query", photoHandler.QueryPhotos)
+	router.With(
+		handler.RateLimit(uploadLimits.store, "upload", uploadLimits.perUser, uploadLimits.perIP),
+		handler.LimitInFlightBytes(uploadLimits.inFlight, uploadLimits.maxRequestBytes),
+		handler.Idempotent(idempotencyService, "photos.upload"),
+	).Post("/upload", photoHandler.CreatePhoto)
+	router.With(
+		handler.RateLimit(uploadLimits.store, "upload", uploadLimits.perUser, uploadLimits.perIP),
+		handler.LimitInFlightBytes(uploadLimits.inFlight, uploadLimits.maxBatchBytes),
+		handler.Idempotent(idempotencyService, "photos.batch"),
+	).Post("/batch", photoHandler.BatchUploadPhotos)
+	router.With(
+		handler.RateLimit(uploadLimits.store, "upload", uploadLimits.perUser, uploadLimits.perIP),
+	).Post("/upload-intents", uploadIntentHandler.CreateUploadIntent)
+	router.Post("/upload-intents/{id}/complete", uploadIntentHandler.CompleteUploadIntent)
+	router.Get("/{id}", photoHandler.GetPhoto)
+	router.Delete("/{id}", photoHandler.DeletePhoto)
+	router.Post("/{id}/restore", photoHandler.RestorePhoto)
+	router.With(
+		handler.RateLimit(uploadLimits.store, "upload", uploadLimits.perUser, uploadLimits.perIP),
+		handler.LimitInFlightBytes(uploadLimits.inFlight, uploadLimits.maxRequestBytes),
+	).Put("/{id}/file", photoVersionHandler.ReplacePhotoFile)
+	router.Get("/{id}/versions", photoVersionHandler.ListVersions)
+	router.Post("/{id}/versions/{version}/rollback", photoVersionHandler.RollbackPhoto)
+	router.Get("/{id}/nearby", photoHandler.GetNearbyPhotos)
+	router.Patch("/{id}/metadata", photoHandler.UpdatePhotoMetadata)
+	router.Post("/{id}/tags", tagHandler.AddPhotoTags)
+	router.Delete("/{id}/tags", tagHandler.RemovePhotoTags)
+	router.Post("/{id}/shares", shareLinkHandler.SharePhoto)
+}
+
+// loadUploadRoutes serves resumable uploads with the tus protocol.
+func loadUploadRoutes(router chi.Router, tusHandler *handler.TusHandler, uploadLimits uploadLimits) {
+	router.Use(handler.TusResumable)
+	router.Options("/", tusHandler.Options)
+	router.With(
+		handler.RateLimit(uploadLimits.store, "upload", uploadLimits.perUser, uploadLimits.perIP),
+	).Post("/", tusHandler.CreateUpload)
+	router.Head("/{id}", tusHandler.HeadUpload)
+	router.With(
+		handler.ReserveInFlightBytes(uploadLimits.inFlight, uploadLimits.tusBufferBytes),
+	).Patch("/{id}", tusHandler.PatchUpload)
+	router.Delete("/{id}", tusHandler.TerminateUpload)
+}
+
+func loadUserRoutes(
+	router chi.Router,
+	geotagHandler *handler.GeotagHandler,
+	routeHandler *handler.RouteHandler,
+	storageHandler *handler.StorageHandler,
+) {
+	router.Post("/geotag/gpx", geotagHandler.GeotagFromGPX)
+	router.Get("/routes", routeHandler.GetRoutes)
+	router.Get("/usage", storageHandler.GetUsage)
+}
+
+func loadPrivateZoneRoutes(router chi.Router, privateZoneHandler *handler.PrivateZoneHandler) {
+	router.Post("/", privateZoneHandler.CreatePrivateZone)
+	router.Get("/", privateZoneHandler.ListPrivateZones)
+	router.Get("/{id}", privateZoneHandler.GetPrivateZone)
+	router.Put("/{id}", privateZoneHandler.UpdatePrivateZone)
+	router.Delete("/{id}", privateZoneHandler.DeletePrivateZone)
+}
+
+func loadAlbumRoutes(router chi.Router, albumHandler *handler.AlbumHandler, shareLinkHandler *handler.ShareLinkHandler) {
+	router.Post("/", albumHandler.CreateAlbum)
+	router.Get("/", albumHandler.ListAlbums)
+	router.Get("/{id}", albumHandler.GetAlbum)
+	router.Patch("/{id}", albumHandler.UpdateAlbum)
+	router.Delete("/{id}", albumHandler.DeleteAlbum)
+	router.Get("/{id}/photos", albumHandler.ListAlbumPhotos)
+	router.Post("/{id}/photos", albumHandler.AddPhotos)
+	router.Put("/{id}/photos/order", albumHandler.ReorderPhotos)
+	router.Delete("/{id}/photos/{photoId}", albumHandler.RemovePhoto)
+	router.Put("/{id}/cover", albumHandler.SetCover)
+	router.Post("/{id}/shares", shareLinkHandler.ShareAlbum)
+	router.Get("/{id}/members", albumHandler.ListMembers)
+	router.Put("/{id}/members/{userId}", albumHandler.SetMember)
+	router.Delete("/{id}/members/{userId}", albumHandler.RemoveMember)
+}
+
+func loadSmartAlbumRoutes(router chi.Router, smartAlbumHandler *handler.SmartAlbumHandler) {
+	router.Post("/", smartAlbumHandler.CreateSmartAlbum)
+	router.Get("/", smartAlbumHandler.ListSmartAlbums)
+	router.Get("/{id}", smartAlbumHandler.GetSmartAlbum)
+	router.Patch("/{id}", smartAlbumHandler.UpdateSmartAlbum)
+	router.Delete("/{id}", smartAlbumHandler.DeleteSmartAlbum)
+	router.Get("/{id}/photos", smartAlbumHandler.ListSmartAlbumPhotos)
+	router.Get("/{id}/count", smartAlbumHandler.CountSmartAlbumPhotos)
+}
+
+func loadWebhookRoutes(router chi.Router, webhookHandler *handler.WebhookHandler) {
+	router.Post("/", webhookHandler.CreateWebhook)
+	router.Get("/", webhookHandler.ListWebhooks)
+	router.Get("/{id}", webhookHandler.GetWebhook)
+	router.Patch("/{id}", webhookHandler.UpdateWebhook)
+	router.Delete("/{id}", webhookHandler.DeleteWebhook)
+	router.Get("/{id}/deliveries", webhookHandler.ListDeliveries)
+	router.Post("/{id}/deliveries/{deliveryId}/redeliver", webhookHandler.Redeliver)
 }
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAlbumPhotosLimit = 50
+	maxAlbumPhotosLimit     = 200
+)
+
+type AlbumHandler struct {
+	albumService interfaces.IAlbumService
+}
+
+func NewAlbumHandler(albumService interfaces.IAlbumService) *AlbumHandler {
+	return &AlbumHandler{albumService: albumService}
+}
+
+type CreateAlbumRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// UpdateAlbumRequest leaves absent fields unchanged; a null description clears it.
+type UpdateAlbumRequest struct {
+	Title       *string         `json:"title"`
+	Description json.RawMessage `json:"description"`
+}
+
+type AlbumPhotosRequest struct {
+	PhotoIDs []uuid.UUID `json:"photo_ids"`
+}
+
+type SetAlbumCoverRequest struct {
+	PhotoID uuid.UUID `json:"photo_id"`
+}
+
+func (h *AlbumHandler) CreateAlbum(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	var body CreateAlbumRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	album, err := h.albumService.CreateAlbum(r.Context(), interfaces.CreateAlbumRequest{
+		UserID:      userID,
+		Title:       body.Title,
+		Description: body.Description,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusCreated, album)
+}
+
+func (h *AlbumHandler) ListAlbums(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	albums, err := h.albumService.ListAlbums(r.Context(), userID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"albums": albums})
+}
+
+func (h *AlbumHandler) GetAlbum(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	album, err := h.albumService.GetAlbum(r.Context(), userID, albumID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, album)
+}
+
+func (h *AlbumHandler) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	var body UpdateAlbumRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	serviceRequest := interfaces.UpdateAlbumRequest{
+		UserID:         userID,
+		AlbumID:        albumID,
+		Title:          body.Title,
+		SetDescription: len(body.Description) > 0,
+	}
+	if serviceRequest.SetDescription {
+		if err := json.Unmarshal(body.Description, &serviceRequest.Description); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "description must be a string or null")
+			return
+		}
+	}
+	album, err := h.albumService.UpdateAlbum(r.Context(), serviceRequest)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, album)
+}
+
+func (h *AlbumHandler) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.albumService.DeleteAlbum(r.Context(), userID, albumID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AlbumHandler) ListAlbumPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultAlbumPhotosLimit, 1, maxAlbumPhotosLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := intQueryParam(r, "offset", 0, 0, 1<<30)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	photos, err := h.albumService.ListAlbumPhotos(r.Context(), interfaces.ListAlbumPhotosRequest{
+		UserID:  userID,
+		AlbumID: albumID,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"photos": photos})
+}
+
+func (h *AlbumHandler) AddPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	var body AlbumPhotosRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	album, err := h.albumService.AddPhotos(r.Context(), interfaces.AlbumPhotosRequest{
+		UserID:   userID,
+		AlbumID:  albumID,
+		PhotoIDs: body.PhotoIDs,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, album)
+}
+
+func (h *AlbumHandler) RemovePhoto(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	photoID, err := uuidURLParam(r, "photoId")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.albumService.RemovePhoto(r.Context(), userID, albumID, photoID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AlbumHandler) ReorderPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	var body AlbumPhotosRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	err := h.albumService.ReorderPhotos(r.Context(), interfaces.AlbumPhotosRequest{
+		UserID:   userID,
+		AlbumID:  albumID,
+		PhotoIDs: body.PhotoIDs,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AlbumHandler) SetCover(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	var body SetAlbumCoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	album, err := h.albumService.SetCover(r.Context(), userID, albumID, body.PhotoID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, album)
+}
+
+type SetAlbumMemberRequest struct {
+	Role interfaces.AlbumRole `json:"role"`
+}
+
+func (h *AlbumHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	members, err := h.albumService.ListMembers(r.Context(), userID, albumID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"members": members})
+}
+
+// SetMember shares the album with the user in the path, or changes their role.
+func (h *AlbumHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	memberID, err := uuidURLParam(r, "userId")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body SetAlbumMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	member, err := h.albumService.SetMember(r.Context(), interfaces.SetAlbumMemberRequest{
+		UserID:   userID,
+		AlbumID:  albumID,
+		MemberID: memberID,
+		Role:     body.Role,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, member)
+}
+
+func (h *AlbumHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	memberID, err := uuidURLParam(r, "userId")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.albumService.RemoveMember(r.Context(), userID, albumID, memberID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
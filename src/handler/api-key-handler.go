@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService interfaces.IAPIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService interfaces.IAPIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+type CreateAPIKeyRequest struct {
+	Name       string             `json:"name"`
+	Scopes     []interfaces.Scope `json:"scopes"`
+	OwnerID    *uuid.UUID         `json:"owner_id"`
+	OnBehalfOf bool               `json:"on_behalf_of"`
+	ExpiresAt  *time.Time         `json:"expires_at"`
+}
+
+// CreateAPIKey mints a key; the response is the only time the key is shown. A key with an
+// owner_id acts as that user. Without one, the key only acts as a user when it is minted
+// with "on_behalf_of": true, which lets it act as any user named in X-On-Behalf-Of.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var body CreateAPIKeyRequest
+	if err := decodeStrictJSON(r, &body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	serviceRequest := interfaces.CreateAPIKeyRequest{
+		Name:       body.Name,
+		Scopes:     body.Scopes,
+		OwnerID:    body.OwnerID,
+		OnBehalfOf: body.OnBehalfOf,
+		ExpiresAt:  body.ExpiresAt,
+	}
+	if principal, ok := principalFromContext(r.Context()); ok && principal.UserID != uuid.Nil {
+		serviceRequest.CreatedBy = &principal.UserID
+	}
+	key, err := h.apiKeyService.CreateAPIKey(r.Context(), serviceRequest)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	util.RespondWithJSON(w, http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.ListAPIKeys(r.Context())
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"api_keys": keys})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.apiKeyService.RevokeAPIKey(r.Context(), keyID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// onBehalfOfHeader names the user an API key minted to act on behalf of users acts as.
+const onBehalfOfHeader = "X-On-Behalf-Of"
+
+type principalContextKey struct{}
+
+// Authenticate requires credentials on every request: a user's bearer token or an
+// `Authorization: ApiKey ...` key. The resulting principal is stored in the request
+// context, where callerID and RequireScope read it.
+func Authenticate(tokenVerifier interfaces.ITokenVerifier, apiKeyService interfaces.IAPIKeyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, credential, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			credential = strings.TrimSpace(credential)
+			if credential == "" {
+				respondUnauthorized(w, "Missing credentials")
+				return
+			}
+
+			var principal interfaces.Principal
+			var err error
+			switch {
+			case strings.EqualFold(scheme, "Bearer"):
+				principal, err = tokenVerifier.VerifyToken(r.Context(), credential)
+			case strings.EqualFold(scheme, "ApiKey"):
+				principal, err = apiKeyService.VerifyAPIKey(r.Context(), credential)
+				if err == nil {
+					err = resolveOnBehalfOf(r, &principal)
+				}
+			default:
+				respondUnauthorized(w, "Unsupported authorization scheme")
+				return
+			}
+			if err != nil {
+				respondUnauthorized(w, err.Error())
+				return
+			}
+			ctx := context.WithValue(r.Context(), principalContextKey{}, principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// resolveOnBehalfOf lets a key minted to act on behalf of users act as the user named in
+// the X-On-Behalf-Of header. Other keys cannot act as anyone else.
+func resolveOnBehalfOf(r *http.Request, principal *interfaces.Principal) error {
+	raw := r.Header.Get(onBehalfOfHeader)
+	if raw == "" {
+		return nil
+	}
+	userID, err := uuid.Parse(raw)
+	if err != nil || userID == uuid.Nil {
+		return fmt.Errorf("%w: %s must be a user ID", interfaces.ErrUnauthorized, onBehalfOfHeader)
+	}
+	if principal.UserID != uuid.Nil && principal.UserID != userID {
+		return fmt.Errorf("%w: this API key is bound to another user", interfaces.ErrUnauthorized)
+	}
+	if principal.UserID == uuid.Nil && !principal.OnBehalfOf {
+		return fmt.Errorf("%w: this API key cannot act on behalf of users", interfaces.ErrUnauthorized)
+	}
+	principal.UserID = userID
+	return nil
+}
+
+// RequireScope rejects principals without the scope with 403.
+func RequireScope(scope interfaces.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := principalFromContext(r.Context())
+			if !principal.HasScope(scope) {
+				util.RespondWithError(w, http.StatusForbidden, "missing scope "+string(scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePhotoScopes needs photos:read for reads and photos:write for everything else.
+// Routes with a pattern in readOnly, such as POST routes that take a query as their body,
+// only need photos:read whatever their method.
+func RequirePhotoScopes(readOnly ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		read := RequireScope(interfaces.ScopePhotosRead)(next)
+		write := RequireScope(interfaces.ScopePhotosWrite)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				read.ServeHTTP(w, r)
+			default:
+				if routeMatches(r, readOnly) {
+					read.ServeHTTP(w, r)
+					return
+				}
+				write.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// routeMatches tells whether the request is routed to one of the patterns. Middleware
+// runs before the route is chosen, so the request is matched against the router here.
+func routeMatches(r *http.Request, patterns []string) bool {
+	rctx := chi.RouteContext(r.Context())
+	if len(patterns) == 0 || rctx == nil || rctx.Routes == nil {
+		return false
+	}
+	match := chi.NewRouteContext()
+	if !rctx.Routes.Match(match, r.Method, r.URL.Path) {
+		return false
+	}
+	return slices.Contains(patterns, match.RoutePattern())
+}
+
+func respondUnauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="photo-service", ApiKey realm="photo-service"`)
+	util.RespondWithError(w, http.StatusUnauthorized, msg)
+}
+
+func principalFromContext(ctx context.Context) (interfaces.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(interfaces.Principal)
+	return principal, ok
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/google/uuid"
+)
+
+// eventStreamHeartbeat is how often an idle stream sends a comment, so proxies do not
+// close it.
+const eventStreamHeartbeat = 25 * time.Second
+
+// eventResetType is the event telling a client that events were missed.
+const eventResetType = "reset"
+
+type EventStreamHandler struct {
+	eventStreamService interfaces.IEventStreamService
+}
+
+func NewEventStreamHandler(eventStreamService interfaces.IEventStreamService) *EventStreamHandler {
+	return &EventStreamHandler{eventStreamService: eventStreamService}
+}
+
+// streamedEvent is the data of a streamed event.
+type streamedEvent struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// StreamEvents sends the caller's events as server-sent events until the client leaves.
+// A client reconnecting with Last-Event-ID (or ?last_event_id=) first receives the events
+// it missed, or a reset event when they are no longer kept.
+func (h *EventStreamHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	var lastSeq int64
+	if lastID != "" {
+		lastSeq, err = strconv.ParseInt(lastID, 10, 64)
+		if err != nil || lastSeq < 0 {
+			util.RespondWithError(w, http.StatusBadRequest, "Last-Event-ID must be an event id")
+			return
+		}
+	}
+
+	subscription := h.eventStreamService.Subscribe(userID, lastSeq)
+	defer subscription.Close()
+
+	controller := http.NewResponseController(w)
+	// Streams outlive the server's write timeout.
+	_ = controller.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if subscription.Reset {
+		fmt.Fprintf(w, "id: %d\nevent: %s\ndata: {}\n\n", subscription.ResetSeq, eventResetType)
+	}
+	for _, event := range subscription.Replay {
+		if err := writeStreamedEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			if err := writeStreamedEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeStreamedEvent(w io.Writer, event interfaces.SequencedEvent) error {
+	data, err := json.Marshal(streamedEvent{
+		ID:        event.Event.ID,
+		Type:      event.Event.Type,
+		CreatedAt: event.Event.CreatedAt,
+		Data:      event.Event.Data,
+	})
+	if err != nil {
+		log.Printf("Error encoding event %d: %v", event.Seq, err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Event.Type, data)
+	return err
+}
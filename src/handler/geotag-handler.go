@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+)
+
+const defaultGeotagMaxGap = 5 * time.Minute
+
+type GeotagHandler struct {
+	geotagService interfaces.IGeotagService
+}
+
+func NewGeotagHandler(geotagService interfaces.IGeotagService) *GeotagHandler {
+	return &GeotagHandler{geotagService: geotagService}
+}
+
+// GeotagFromGPX accepts a multipart form with a "gpx" file and optional "offset", "max_gap"
+// (Go durations such as "-2h" or "90s") and "dry_run" fields.
+func (h *GeotagHandler) GeotagFromGPX(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerUserParam(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseMultipartForm(10 << 20); err != nil { // Limit to 10MB
+		util.RespondWithError(w, http.StatusBadRequest, "Error parsing form data")
+		return
+	}
+
+	var err error
+	offset := time.Duration(0)
+	if raw := r.FormValue("offset"); raw != "" {
+		if offset, err = time.ParseDuration(raw); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "offset must be a duration such as -2h or 90s")
+			return
+		}
+	}
+	maxGap := defaultGeotagMaxGap
+	if raw := r.FormValue("max_gap"); raw != "" {
+		if maxGap, err = time.ParseDuration(raw); err != nil || maxGap <= 0 {
+			util.RespondWithError(w, http.StatusBadRequest, "max_gap must be a positive duration such as 5m")
+			return
+		}
+	}
+	dryRun := false
+	if raw := r.FormValue("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+	}
+
+	file, _, err := r.FormFile("gpx")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Error retrieving the file")
+		return
+	}
+	defer file.Close()
+	gpxData, err := io.ReadAll(file)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Error reading file")
+		return
+	}
+
+	result, err := h.geotagService.GeotagFromGPX(r.Context(), interfaces.GeotagFromGPXRequest{
+		UserID:      userID,
+		GPXData:     gpxData,
+		ClockOffset: offset,
+		MaxGap:      maxGap,
+		DryRun:      dryRun,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotent makes requests with an Idempotency-Key header run at most once per caller and
+// key. The response is stored and replayed, marked with Idempotent-Replayed, to retries
+// of the same request; reusing the key for a different request answers 409. Server
+// errors are not stored, so they can be retried. Requests without the header are served
+// as usual.
+func Idempotent(service interfaces.IIdempotencyService, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				util.RespondWithError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+			userID, err := callerID(r)
+			if err != nil {
+				respondWithServiceError(w, err)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					util.RespondWithError(w, http.StatusRequestEntityTooLarge, "Request body is too large")
+					return
+				}
+				util.RespondWithError(w, http.StatusBadRequest, "Error reading request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			id := interfaces.IdempotencyKeyID{OwnerID: userID, Scope: scope, Key: key}
+			stored, err := service.Begin(r.Context(), id, requestFingerprint(r, body))
+			if err != nil {
+				if errors.Is(err, interfaces.ErrIdempotencyKeyInUse) {
+					w.Header().Set("Retry-After", "1")
+				}
+				respondWithServiceError(w, err)
+				return
+			}
+			if stored != nil {
+				if stored.ContentType != "" {
+					w.Header().Set("Content-Type", stored.ContentType)
+				}
+				if stored.Location != "" {
+					w.Header().Set("Location", stored.Location)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.Status)
+				w.Write(stored.Body)
+				return
+			}
+
+			// The outcome is recorded even when the client has gone away, since that is
+			// when it retries.
+			ctx := context.WithoutCancel(r.Context())
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				if !completed {
+					if err := service.Release(ctx, id); err != nil {
+						log.Printf("Error releasing idempotency key: %v", err)
+					}
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+			if recorder.status >= http.StatusInternalServerError {
+				return
+			}
+			completed = service.Complete(ctx, id, interfaces.StoredResponse{
+				Status:      recorder.status,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Location:    recorder.Header().Get("Location"),
+				Body:        recorder.body.Bytes(),
+			}) == nil
+		})
+	}
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// requestFingerprint hashes what a request asks for: its method, path and body. Multipart
+// bodies are hashed part by part, leaving out the boundary, which clients pick at random
+// for every attempt.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	writeField(h, []byte(r.Method))
+	writeField(h, []byte(r.URL.Path))
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		writeField(h, []byte(mediaType))
+		if hashMultipart(h, body, params["boundary"]) {
+			return hex.EncodeToString(h.Sum(nil))
+		}
+		// Not valid multipart after all: fall back to the raw body.
+		h.Reset()
+		writeField(h, []byte(r.Method))
+		writeField(h, []byte(r.URL.Path))
+	}
+	writeField(h, []byte(r.Header.Get("Content-Type")))
+	writeField(h, body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashMultipart(h hash.Hash, body []byte, boundary string) bool {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return false
+		}
+		writeField(h, []byte(part.FormName()))
+		writeField(h, []byte(part.FileName()))
+		writeField(h, []byte(part.Header.Get("Content-Type")))
+		writeField(h, data)
+	}
+}
+
+// writeField writes a length-prefixed value, so adjacent fields cannot run into each other.
+func writeField(h hash.Hash, value []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(value)))
+	h.Write(length[:])
+	h.Write(value)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"sync"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxBatchPhotos bounds the photo parts of one batch upload.
+	maxBatchPhotos = 200
+	// batchUploadConcurrency is how many photos of a batch are stored at once.
+	batchUploadConcurrency = 4
+	// batchFormMemory is how much of a batch is kept in memory; larger parts are spooled
+	// to temporary files until their turn comes.
+	batchFormMemory = 32 << 20
+)
+
+// BatchUploadResult is the outcome of one photo part. Failed parts carry the status the
+// single upload would have answered with and a short error code.
+type BatchUploadResult struct {
+	Index    int    `json:"index"`
+	FileName string `json:"file_name"`
+	Status   int    `json:"status"`
+	PhotoID  string `json:"photo_id,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BatchUploadPhotos stores every `photo` part of a multipart request. The description of
+// the n-th photo (counting from 0) is read from the `description[n]` field. Photos are
+// stored independently, so the response is 207 with one result per photo, in request order.
+func (h *PhotoHandler) BatchUploadPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	if err := r.ParseMultipartForm(batchFormMemory); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Error parsing form data")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["photo"]
+	if len(files) == 0 {
+		util.RespondWithError(w, http.StatusBadRequest, "No photo parts in the request")
+		return
+	}
+	if len(files) > maxBatchPhotos {
+		util.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("A batch holds at most %d photos", maxBatchPhotos))
+		return
+	}
+
+	results := make([]BatchUploadResult, len(files))
+	slots := make(chan struct{}, batchUploadConcurrency)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, file *multipart.FileHeader) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = h.uploadBatchPhoto(r, userID, i, file)
+		}(i, file)
+	}
+	wg.Wait()
+
+	util.RespondWithJSON(w, http.StatusMultiStatus, map[string]interface{}{"results": results})
+}
+
+func (h *PhotoHandler) uploadBatchPhoto(r *http.Request, userID uuid.UUID, index int, header *multipart.FileHeader) BatchUploadResult {
+	result := BatchUploadResult{Index: index, FileName: header.Filename}
+	file, err := header.Open()
+	if err != nil {
+		result.Status, result.Code, result.Error = http.StatusBadRequest, "invalid_file", "Error retrieving the file"
+		return result
+	}
+	defer file.Close()
+	fileBytes, err := h.fileToBytes(file)
+	if err != nil {
+		result.Status, result.Code, result.Error = http.StatusBadRequest, "invalid_file", "Error reading file"
+		return result
+	}
+
+	photoID, err := h.photoService.CreatePhoto(r.Context(), interfaces.CreatePhotoRequest{
+		UserID:      userID,
+		Description: r.FormValue(fmt.Sprintf("description[%d]", index)),
+		FileName:    header.Filename,
+		FileData:    fileBytes,
+	})
+	if err != nil {
+		result.Status, result.Code = serviceErrorStatus(err)
+		result.Error = "Internal server error"
+		if result.Status != http.StatusInternalServerError {
+			result.Error = err.Error()
+		}
+		return result
+	}
+	result.Status, result.PhotoID = http.StatusAccepted, photoID
+	return result
+}
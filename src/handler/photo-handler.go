@@ -6,10 +6,10 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"time"
 
 	"photo-service/src/interfaces"
-
-	"github.com/google/uuid"
+	"photo-service/src/util"
 )
 
 type PhotoHandler struct {
@@ -21,7 +21,6 @@ func NewPhotoHandler(photoService interfaces.IPhotoService) *PhotoHandler {
 }
 
 type CreatePhotoRequest struct {
-	UserID      string `json:"user_id"`
 	Description string `json:"description"`
 	URL         string `json:"url"`
 }
@@ -32,17 +31,16 @@ func (h *PhotoHandler) CreatePhoto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract fields from the form data
-	userIDStr := r.FormValue("userId")
-	description := r.FormValue("description")
-
-	// Validate and parse the user ID as a UUID
-	userID, err := uuid.Parse(userIDStr)
+	// The owner is always the authenticated caller
+	userID, err := callerID(r)
 	if err != nil {
-		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		respondWithServiceError(w, err)
 		return
 	}
 
+	// Extract fields from the form data
+	description := r.FormValue("description")
+
 	// Retrieve the file from the form data
 	file, handler, err := r.FormFile("photo")
 	if err != nil {
@@ -67,17 +65,309 @@ func (h *PhotoHandler) CreatePhoto(w http.ResponseWriter, r *http.Request) {
 
 	photoID, err := h.photoService.CreatePhoto(r.Context(), serviceRequest)
 	if err != nil {
-		http.Error(w, "Error creating photo", http.StatusInternalServerError)
+		respondWithServiceError(w, err)
 		return
 	}
 
-	// Return a success response with the photo ID
-	response := map[string]string{"photo_id": photoID}
+	// The photo is stored; its metadata is extracted in the background. Clients poll the
+	// photo until its status is no longer processing.
+	response := map[string]string{"photo_id": photoID, "status": interfaces.PhotoStatusProcessing}
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.Header().Set("Location", "/v1/photos/"+photoID)
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(response)
 }
 
+const (
+	defaultListPhotosLimit = 50
+	maxListPhotosLimit     = 200
+)
+
+// ListPhotos lists the caller's photos. Repeated ?tag= parameters filter by tag; match=all
+// (the default) requires every tag, match=any at least one.
+func (h *PhotoHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultListPhotosLimit, 1, maxListPhotosLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := intQueryParam(r, "offset", 0, 0, 1<<30)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var matchAll bool
+	switch r.URL.Query().Get("match") {
+	case "", "all":
+		matchAll = true
+	case "any":
+		matchAll = false
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, "match must be all or any")
+		return
+	}
+
+	photos, err := h.photoService.ListPhotos(r.Context(), interfaces.ListPhotosRequest{
+		UserID:   userID,
+		Tags:     r.URL.Query()["tag"],
+		MatchAll: matchAll,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"photos": photos})
+}
+
+// SearchPhotos runs a full-text search over the caller's photo descriptions. q supports
+// "quoted phrases" and prefix* words.
+func (h *PhotoHandler) SearchPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultListPhotosLimit, 1, maxListPhotosLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := intQueryParam(r, "offset", 0, 0, 1<<30)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results, err := h.photoService.SearchPhotos(r.Context(), interfaces.SearchPhotosRequest{
+		UserID: userID,
+		Query:  r.URL.Query().Get("q"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"photos": results})
+}
+
+// QueryPhotos runs a JSON filter document over the caller's photos.
+func (h *PhotoHandler) QueryPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	var query interfaces.PhotoQuery
+	if err := decodeStrictJSON(r, &query); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.photoService.QueryPhotos(r.Context(), interfaces.QueryPhotosRequest{UserID: userID, Query: query})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, page)
+}
+
+// GetPhoto returns a photo the caller owns or that is in an album shared with them.
+func (h *PhotoHandler) GetPhoto(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	photo, err := h.photoService.GetPhoto(r.Context(), userID, photoID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, photo)
+}
+
+// DeletePhoto moves one of the caller's photos to the trash.
+func (h *PhotoHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.photoService.DeletePhoto(r.Context(), userID, photoID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTrash lists the caller's deleted photos with the time each will be purged.
+func (h *PhotoHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultListPhotosLimit, 1, maxListPhotosLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := intQueryParam(r, "offset", 0, 0, 1<<30)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	photos, err := h.photoService.ListTrash(r.Context(), interfaces.ListTrashRequest{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"photos": photos})
+}
+
+// RestorePhoto takes one of the caller's photos out of the trash.
+func (h *PhotoHandler) RestorePhoto(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.photoService.RestorePhoto(r.Context(), userID, photoID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EmptyTrash deletes the photos in the caller's trash for good.
+func (h *PhotoHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	purged, err := h.photoService.EmptyTrash(r.Context(), userID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"purged": purged})
+}
+
+const (
+	defaultNearbyRadiusMeters = 1000
+	maxNearbyRadiusMeters     = 50000
+	defaultNearbyLimit        = 20
+	maxNearbyLimit            = 100
+)
+
+func (h *PhotoHandler) GetNearbyPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	photoID, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	radius, err := floatQueryParam(r, "radius_m", defaultNearbyRadiusMeters, maxNearbyRadiusMeters)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultNearbyLimit, 1, maxNearbyLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	timeWindow, err := durationQueryParam(r, "time_window")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	photos, err := h.photoService.GetNearbyPhotos(r.Context(), interfaces.GetNearbyPhotosRequest{
+		ViewerID:     userID,
+		PhotoID:      photoID,
+		RadiusMeters: radius,
+		TimeWindow:   timeWindow,
+		Limit:        limit,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"photos": photos})
+}
+
+// UpdatePhotoMetadataRequest distinguishes absent fields (left unchanged) from null (cleared).
+type UpdatePhotoMetadataRequest struct {
+	Location   json.RawMessage `json:"location"`
+	CapturedAt json.RawMessage `json:"captured_at"`
+}
+
+func (h *PhotoHandler) UpdatePhotoMetadata(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	photoID, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var body UpdatePhotoMetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	serviceRequest := interfaces.UpdatePhotoMetadataRequest{
+		UserID:        userID,
+		PhotoID:       photoID,
+		SetLocation:   len(body.Location) > 0,
+		SetCapturedAt: len(body.CapturedAt) > 0,
+	}
+	if serviceRequest.SetLocation {
+		if err := json.Unmarshal(body.Location, &serviceRequest.Location); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "location must be null or an object with latitude and longitude")
+			return
+		}
+	}
+	if serviceRequest.SetCapturedAt {
+		var capturedAt *time.Time
+		if err := json.Unmarshal(body.CapturedAt, &capturedAt); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "captured_at must be null or an RFC 3339 timestamp")
+			return
+		}
+		if capturedAt != nil {
+			utc := capturedAt.UTC()
+			capturedAt = &utc
+		}
+		serviceRequest.CapturedAt = capturedAt
+	}
+
+	metadata, err := h.photoService.UpdatePhotoMetadata(r.Context(), serviceRequest)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, metadata)
+}
+
 func (h *PhotoHandler) fileToBytes(file multipart.File) ([]byte, error) {
 	fileBytes, err := io.ReadAll(file)
 	if err != nil {
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PhotoVersionHandler struct {
+	photoVersionService interfaces.IPhotoVersionService
+}
+
+func NewPhotoVersionHandler(photoVersionService interfaces.IPhotoVersionService) *PhotoVersionHandler {
+	return &PhotoVersionHandler{photoVersionService: photoVersionService}
+}
+
+// ReplacePhotoFile stores the "photo" file of a multipart form as the photo's new current
+// version. Like an upload, the photo is processing until the metadata of the new file has
+// been extracted.
+func (h *PhotoVersionHandler) ReplacePhotoFile(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Error parsing form data")
+		return
+	}
+	file, header, err := r.FormFile("photo")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Error retrieving the file")
+		return
+	}
+	defer file.Close()
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		util.RespondWithError(w, http.StatusInternalServerError, "Error reading file")
+		return
+	}
+
+	version, err := h.photoVersionService.ReplacePhotoFile(r.Context(), interfaces.ReplacePhotoFileRequest{
+		UserID:   userID,
+		PhotoID:  photoID,
+		FileName: header.Filename,
+		FileData: fileBytes,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, version)
+}
+
+// ListVersions lists the versions of the caller's photo, newest first.
+func (h *PhotoVersionHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	versions, err := h.photoVersionService.ListVersions(r.Context(), userID, photoID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, versions)
+}
+
+// RollbackPhoto makes an earlier version of the caller's photo current again.
+func (h *PhotoVersionHandler) RollbackPhoto(w http.ResponseWriter, r *http.Request) {
+	userID, photoID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		util.RespondWithError(w, http.StatusBadRequest, "invalid version")
+		return
+	}
+	rolledBack, err := h.photoVersionService.RollbackPhoto(r.Context(), userID, photoID, version)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, rolledBack)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+)
+
+type PrivateZoneHandler struct {
+	privateZoneService interfaces.IPrivateZoneService
+}
+
+func NewPrivateZoneHandler(privateZoneService interfaces.IPrivateZoneService) *PrivateZoneHandler {
+	return &PrivateZoneHandler{privateZoneService: privateZoneService}
+}
+
+// SavePrivateZoneRequest is either a circle ("center" and "radius_m") or a "polygon".
+type SavePrivateZoneRequest struct {
+	Name         string                     `json:"name"`
+	Center       *interfaces.PhotoLocation  `json:"center"`
+	RadiusMeters *float64                   `json:"radius_m"`
+	Polygon      []interfaces.PhotoLocation `json:"polygon"`
+}
+
+func (h *PrivateZoneHandler) CreatePrivateZone(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	var body SavePrivateZoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	zone, err := h.privateZoneService.CreatePrivateZone(r.Context(), interfaces.SavePrivateZoneRequest{
+		UserID:       userID,
+		Name:         body.Name,
+		Center:       body.Center,
+		RadiusMeters: body.RadiusMeters,
+		Polygon:      body.Polygon,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusCreated, zone)
+}
+
+func (h *PrivateZoneHandler) ListPrivateZones(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	zones, err := h.privateZoneService.ListPrivateZones(r.Context(), userID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"private_zones": zones})
+}
+
+func (h *PrivateZoneHandler) GetPrivateZone(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	zoneID, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	zone, err := h.privateZoneService.GetPrivateZone(r.Context(), userID, zoneID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, zone)
+}
+
+func (h *PrivateZoneHandler) UpdatePrivateZone(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	zoneID, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body SavePrivateZoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	zone, err := h.privateZoneService.UpdatePrivateZone(r.Context(), interfaces.SavePrivateZoneRequest{
+		UserID:       userID,
+		ZoneID:       zoneID,
+		Name:         body.Name,
+		Center:       body.Center,
+		RadiusMeters: body.RadiusMeters,
+		Polygon:      body.Polygon,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, zone)
+}
+
+func (h *PrivateZoneHandler) DeletePrivateZone(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	zoneID, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.privateZoneService.DeletePrivateZone(r.Context(), userID, zoneID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/google/uuid"
+)
+
+// inFlightRetryAfter is what clients turned away for lack of upload capacity are told to
+// wait; uploads in flight finish within seconds.
+const inFlightRetryAfter = time.Second
+
+type rateLimitBucket struct {
+	key   string
+	limit interfaces.RateLimit
+}
+
+// RateLimit limits requests with one token bucket per client IP and one per authenticated
+// user, named after scope so different routes can have separate limits. A limit with a
+// zero Rate is off. When the store fails, requests are let through.
+func RateLimit(store interfaces.IRateLimitStore, scope string, perUser, perIP interfaces.RateLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buckets := []rateLimitBucket{{scope + ":ip:" + clientIP(r), perIP}}
+			if principal, ok := principalFromContext(r.Context()); ok && principal.UserID != uuid.Nil {
+				buckets = append(buckets, rateLimitBucket{scope + ":user:" + principal.UserID.String(), perUser})
+			}
+			for _, bucket := range buckets {
+				if bucket.limit.Rate <= 0 {
+					continue
+				}
+				allowed, retryAfter, err := store.Take(r.Context(), bucket.key, bucket.limit)
+				if err != nil {
+					log.Printf("Error checking rate limit: %v", err)
+					continue
+				}
+				if !allowed {
+					respondTooManyRequests(w, retryAfter, "Rate limit exceeded, slow down")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LimitInFlightBytes bounds the request bodies being read at once. A request reserves its
+// Content-Length, or maxRequestBytes when the length is not known, and is turned away with
+// 429 while the reservation does not fit. Bodies are cut off at the reserved size.
+func LimitInFlightBytes(limiter interfaces.IInFlightLimiter, maxRequestBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxRequestBytes {
+				util.RespondWithError(w, http.StatusRequestEntityTooLarge, "Request body is too large")
+				return
+			}
+			reserved := maxRequestBytes
+			if r.ContentLength >= 0 {
+				reserved = r.ContentLength
+			}
+			if !limiter.Acquire(reserved) {
+				respondTooManyRequests(w, inFlightRetryAfter, "Too many uploads in progress, try again shortly")
+				return
+			}
+			defer limiter.Release(reserved)
+			r.Body = http.MaxBytesReader(w, r.Body, reserved)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ReserveInFlightBytes counts n bytes against the in-flight limit while a request is served,
+// for handlers that stream the body through a buffer of that size instead of reading it
+// whole. Requests are turned away with 429 while the reservation does not fit.
+func ReserveInFlightBytes(limiter interfaces.IInFlightLimiter, n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.Acquire(n) {
+				respondTooManyRequests(w, inFlightRetryAfter, "Too many uploads in progress, try again shortly")
+				return
+			}
+			defer limiter.Release(n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func respondTooManyRequests(w http.ResponseWriter, retryAfter time.Duration, msg string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	util.RespondWithError(w, http.StatusTooManyRequests, msg)
+}
+
+// clientIP is the address the request came from. Behind a proxy that is the proxy, unless
+// a middleware such as chi's RealIP has rewritten RemoteAddr.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
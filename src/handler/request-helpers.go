@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// callerID returns the authenticated user the request is made on behalf of.
+func callerID(r *http.Request) (uuid.UUID, error) {
+	principal, ok := principalFromContext(r.Context())
+	if !ok {
+		return uuid.Nil, fmt.Errorf("%w: not authenticated", interfaces.ErrUnauthorized)
+	}
+	if principal.UserID == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("%w: this API key is not bound to a user; name one with %s", interfaces.ErrUnauthorized, onBehalfOfHeader)
+	}
+	return principal.UserID, nil
+}
+
+// callerAndIDParam reads the caller and the {id} path parameter, responding with an error
+// when either is missing or invalid.
+func callerAndIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+// callerUserParam reads the {id} user path parameter, which must be the caller.
+func callerUserParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, id, ok := callerAndIDParam(w, r)
+	if !ok {
+		return uuid.Nil, false
+	}
+	if id != userID {
+		util.RespondWithError(w, http.StatusForbidden, "cannot act on behalf of another user")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// uuidURLParam parses a UUID path parameter.
+func uuidURLParam(r *http.Request, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s format", name)
+	}
+	return id, nil
+}
+
+// intQueryParam parses an optional integer query parameter, bounded to [min, max].
+func intQueryParam(r *http.Request, name string, fallback, min, max int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min || value > max {
+		return 0, fmt.Errorf("%s must be an integer between %d and %d", name, min, max)
+	}
+	return value, nil
+}
+
+// floatQueryParam parses an optional float query parameter, bounded to (0, max].
+func floatQueryParam(r *http.Request, name string, fallback, max float64) (float64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value <= 0 || value > max {
+		return 0, fmt.Errorf("%s must be a number greater than 0 and at most %g", name, max)
+	}
+	return value, nil
+}
+
+// durationQueryParam parses an optional Go duration query parameter such as "90m" or "24h".
+func durationQueryParam(r *http.Request, name string) (*time.Duration, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		return nil, fmt.Errorf("%s must be a positive duration such as 30m or 24h", name)
+	}
+	return &value, nil
+}
+
+// decodeStrictJSON decodes a request body, rejecting unknown fields so a misspelled
+// filter in a query document is reported instead of silently ignored.
+func decodeStrictJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("Invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+// respondWithServiceError maps service errors to HTTP responses.
+func respondWithServiceError(w http.ResponseWriter, err error) {
+	var rateLimited *interfaces.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		respondTooManyRequests(w, rateLimited.RetryAfter, err.Error())
+		return
+	}
+	status, _ := serviceErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		util.RespondWithError(w, status, "Internal server error")
+		return
+	}
+	util.RespondWithError(w, status, err.Error())
+}
+
+// serviceErrorStatus returns the HTTP status and a short machine-readable code for a
+// service error.
+func serviceErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, interfaces.ErrNotFound):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, interfaces.ErrInvalidArgument):
+		return http.StatusBadRequest, "invalid_argument"
+	case errors.Is(err, interfaces.ErrUnauthorized):
+		return http.StatusUnauthorized, "unauthorized"
+	case errors.Is(err, interfaces.ErrForbidden):
+		return http.StatusForbidden, "forbidden"
+	case errors.Is(err, interfaces.ErrConflict):
+		return http.StatusConflict, "conflict"
+	case errors.Is(err, interfaces.ErrQuotaExceeded):
+		return http.StatusInsufficientStorage, "quota_exceeded"
+	case errors.Is(err, interfaces.ErrRateLimited):
+		return http.StatusTooManyRequests, "rate_limited"
+	default:
+		return http.StatusInternalServerError, "internal"
+	}
+}
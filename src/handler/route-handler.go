@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+)
+
+type RouteHandler struct {
+	routeService interfaces.IRouteService
+}
+
+func NewRouteHandler(routeService interfaces.IRouteService) *RouteHandler {
+	return &RouteHandler{routeService: routeService}
+}
+
+// GetRoutes returns a GeoJSON FeatureCollection with one LineString per day between "from"
+// and "to", or a single one with group_by=trip.
+func (h *RouteHandler) GetRoutes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerUserParam(w, r)
+	if !ok {
+		return
+	}
+	from, err := timeQueryParam(r, "from", false)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := timeQueryParam(r, "to", true)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	perTrip := false
+	switch r.URL.Query().Get("group_by") {
+	case "", "day":
+	case "trip":
+		perTrip = true
+	default:
+		util.RespondWithError(w, http.StatusBadRequest, "group_by must be day or trip")
+		return
+	}
+
+	routes, err := h.routeService.GetRoutes(r.Context(), interfaces.GetRoutesRequest{
+		UserID:  userID,
+		From:    from,
+		To:      to,
+		PerTrip: perTrip,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, routes)
+}
+
+// timeQueryParam parses a required date (2006-01-02) or RFC 3339 timestamp. A date used as
+// an upper bound covers the whole day.
+func timeQueryParam(r *http.Request, name string, endOfDay bool) (time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, fmt.Errorf("%s is required", name)
+	}
+	if day, err := time.Parse("2006-01-02", raw); err == nil {
+		if endOfDay {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day, nil
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be a date (2006-01-02) or an RFC 3339 timestamp", name)
+	}
+	return value.UTC(), nil
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSharedAlbumLimit = 50
+	maxSharedAlbumLimit     = 200
+	// sharePasswordHeader carries the password of a protected link, keeping it out of URLs and logs.
+	sharePasswordHeader = "X-Share-Password"
+)
+
+type ShareLinkHandler struct {
+	shareLinkService interfaces.IShareLinkService
+}
+
+func NewShareLinkHandler(shareLinkService interfaces.IShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{shareLinkService: shareLinkService}
+}
+
+type CreateShareLinkRequest struct {
+	ExpiresAt     *time.Time `json:"expires_at"`
+	Password      string     `json:"password"`
+	AllowDownload bool       `json:"allow_download"`
+}
+
+func (h *ShareLinkHandler) SharePhoto(w http.ResponseWriter, r *http.Request) {
+	h.createShareLink(w, r, func(request *interfaces.CreateShareLinkRequest, id uuid.UUID) {
+		request.PhotoID = &id
+	})
+}
+
+func (h *ShareLinkHandler) ShareAlbum(w http.ResponseWriter, r *http.Request) {
+	h.createShareLink(w, r, func(request *interfaces.CreateShareLinkRequest, id uuid.UUID) {
+		request.AlbumID = &id
+	})
+}
+
+func (h *ShareLinkHandler) createShareLink(w http.ResponseWriter, r *http.Request, setTarget func(*interfaces.CreateShareLinkRequest, uuid.UUID)) {
+	userID, targetID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	var body CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	serviceRequest := interfaces.CreateShareLinkRequest{
+		UserID:        userID,
+		Password:      body.Password,
+		AllowDownload: body.AllowDownload,
+		ExpiresAt:     body.ExpiresAt,
+	}
+	setTarget(&serviceRequest, targetID)
+
+	link, err := h.shareLinkService.CreateShareLink(r.Context(), serviceRequest)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusCreated, link)
+}
+
+func (h *ShareLinkHandler) ListShareLinks(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	links, err := h.shareLinkService.ListShareLinks(r.Context(), userID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"shares": links})
+}
+
+func (h *ShareLinkHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	userID, linkID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.shareLinkService.RevokeShareLink(r.Context(), userID, linkID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// OpenShareLink serves a share link to anyone holding its token. Album links page through
+// their photos with ?limit= and ?offset=.
+func (h *ShareLinkHandler) OpenShareLink(w http.ResponseWriter, r *http.Request) {
+	limit, err := intQueryParam(r, "limit", defaultSharedAlbumLimit, 1, maxSharedAlbumLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := intQueryParam(r, "offset", 0, 0, 1<<30)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	content, err := h.shareLinkService.OpenShareLink(r.Context(), interfaces.OpenShareLinkRequest{
+		Token:    chi.URLParam(r, "token"),
+		Password: r.Header.Get(sharePasswordHeader),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	// The response holds short-lived presigned URLs and may be password protected.
+	w.Header().Set("Cache-Control", "no-store")
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, content)
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+)
+
+const (
+	defaultSmartAlbumPhotosLimit = 50
+	maxSmartAlbumPhotosLimit     = 200
+)
+
+type SmartAlbumHandler struct {
+	smartAlbumService interfaces.ISmartAlbumService
+}
+
+func NewSmartAlbumHandler(smartAlbumService interfaces.ISmartAlbumService) *SmartAlbumHandler {
+	return &SmartAlbumHandler{smartAlbumService: smartAlbumService}
+}
+
+type CreateSmartAlbumRequest struct {
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	Query       interfaces.PhotoQuery `json:"query"`
+}
+
+// UpdateSmartAlbumRequest leaves absent fields unchanged; a null description clears it.
+type UpdateSmartAlbumRequest struct {
+	Title       *string                `json:"title"`
+	Description json.RawMessage        `json:"description"`
+	Query       *interfaces.PhotoQuery `json:"query"`
+}
+
+func (h *SmartAlbumHandler) CreateSmartAlbum(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	var body CreateSmartAlbumRequest
+	if err := decodeStrictJSON(r, &body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	album, err := h.smartAlbumService.CreateSmartAlbum(r.Context(), interfaces.CreateSmartAlbumRequest{
+		UserID:      userID,
+		Title:       body.Title,
+		Description: body.Description,
+		Query:       body.Query,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusCreated, album)
+}
+
+func (h *SmartAlbumHandler) ListSmartAlbums(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	albums, err := h.smartAlbumService.ListSmartAlbums(r.Context(), userID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"smart_albums": albums})
+}
+
+func (h *SmartAlbumHandler) GetSmartAlbum(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	album, err := h.smartAlbumService.GetSmartAlbum(r.Context(), userID, albumID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, album)
+}
+
+func (h *SmartAlbumHandler) UpdateSmartAlbum(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	var body UpdateSmartAlbumRequest
+	if err := decodeStrictJSON(r, &body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	serviceRequest := interfaces.UpdateSmartAlbumRequest{
+		UserID:         userID,
+		AlbumID:        albumID,
+		Title:          body.Title,
+		SetDescription: len(body.Description) > 0,
+		Query:          body.Query,
+	}
+	if serviceRequest.SetDescription {
+		if err := json.Unmarshal(body.Description, &serviceRequest.Description); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "description must be a string or null")
+			return
+		}
+	}
+	album, err := h.smartAlbumService.UpdateSmartAlbum(r.Context(), serviceRequest)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, album)
+}
+
+func (h *SmartAlbumHandler) DeleteSmartAlbum(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.smartAlbumService.DeleteSmartAlbum(r.Context(), userID, albumID); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSmartAlbumPhotos pages through the photos matching the album's query with ?cursor= and ?limit=.
+func (h *SmartAlbumHandler) ListSmartAlbumPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultSmartAlbumPhotosLimit, 1, maxSmartAlbumPhotosLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.smartAlbumService.ListSmartAlbumPhotos(r.Context(), interfaces.ListSmartAlbumPhotosRequest{
+		UserID:  userID,
+		AlbumID: albumID,
+		Cursor:  r.URL.Query().Get("cursor"),
+		Limit:   limit,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, page)
+}
+
+func (h *SmartAlbumHandler) CountSmartAlbumPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, albumID, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	count, err := h.smartAlbumService.CountSmartAlbumPhotos(r.Context(), userID, albumID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"count": count})
+}
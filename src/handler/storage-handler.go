@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+)
+
+type StorageHandler struct {
+	quotaService interfaces.IStorageQuotaService
+}
+
+func NewStorageHandler(quotaService interfaces.IStorageQuotaService) *StorageHandler {
+	return &StorageHandler{quotaService: quotaService}
+}
+
+// GetUsage reports the caller's storage usage and the limits of their tier.
+func (h *StorageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := callerUserParam(w, r)
+	if !ok {
+		return
+	}
+	usage, err := h.quotaService.GetUsage(r.Context(), userID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, usage)
+}
+
+type SetStorageTierRequest struct {
+	Tier string `json:"tier"`
+}
+
+// SetTier moves a user to another storage tier.
+func (h *StorageHandler) SetTier(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body SetStorageTierRequest
+	if err := decodeStrictJSON(r, &body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	usage, err := h.quotaService.SetTier(r.Context(), userID, body.Tier)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, usage)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+)
+
+const (
+	defaultTagSearchLimit = 10
+	maxTagSearchLimit     = 50
+)
+
+type TagHandler struct {
+	tagService interfaces.ITagService
+}
+
+func NewTagHandler(tagService interfaces.ITagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+type PhotoTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+func (h *TagHandler) AddPhotoTags(w http.ResponseWriter, r *http.Request) {
+	request, ok := photoTagsRequest(w, r, false)
+	if !ok {
+		return
+	}
+	tags, err := h.tagService.AddPhotoTags(r.Context(), request)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"tags": tags})
+}
+
+// RemovePhotoTags takes the tags to remove from repeated ?tag= parameters or a JSON body.
+func (h *TagHandler) RemovePhotoTags(w http.ResponseWriter, r *http.Request) {
+	request, ok := photoTagsRequest(w, r, true)
+	if !ok {
+		return
+	}
+	tags, err := h.tagService.RemovePhotoTags(r.Context(), request)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"tags": tags})
+}
+
+func (h *TagHandler) SearchTags(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultTagSearchLimit, 1, maxTagSearchLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tags, err := h.tagService.SearchTags(r.Context(), interfaces.SearchTagsRequest{
+		UserID: userID,
+		Prefix: r.URL.Query().Get("prefix"),
+		Limit:  limit,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"tags": tags})
+}
+
+// photoTagsRequest parses the caller, photo ID and tag names shared by the photo tag
+// endpoints, writing an error response when any of them is invalid.
+func photoTagsRequest(w http.ResponseWriter, r *http.Request, allowQuery bool) (interfaces.PhotoTagsRequest, bool) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return interfaces.PhotoTagsRequest{}, false
+	}
+	photoID, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return interfaces.PhotoTagsRequest{}, false
+	}
+	tags := r.URL.Query()["tag"]
+	if !allowQuery || len(tags) == 0 {
+		var body PhotoTagsRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			util.RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+			return interfaces.PhotoTagsRequest{}, false
+		}
+		tags = body.Tags
+	}
+	return interfaces.PhotoTagsRequest{UserID: userID, PhotoID: photoID, Tags: tags}, true
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+)
+
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,termination"
+	tusContentType = "application/offset+octet-stream"
+	// photoIDHeader carries the ID of the photo created from a completed upload.
+	photoIDHeader = "X-Photo-Id"
+)
+
+// TusHandler serves resumable uploads with the tus 1.0 protocol and its creation and
+// termination extensions, see https://tus.io/protocols/resumable-upload.
+type TusHandler struct {
+	tusUploadService interfaces.ITusUploadService
+}
+
+func NewTusHandler(tusUploadService interfaces.ITusUploadService) *TusHandler {
+	return &TusHandler{tusUploadService: tusUploadService}
+}
+
+// TusResumable rejects requests for another protocol version with 412 and adds the
+// version to responses. OPTIONS requests are exempt, they discover the version.
+func TusResumable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Tus-Resumable", tusVersion)
+		if r.Header.Get("Tus-Resumable") != tusVersion {
+			w.Header().Set("Tus-Version", tusVersion)
+			util.RespondWithError(w, http.StatusPreconditionFailed, "Unsupported tus version, this server speaks "+tusVersion)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Options describes the server's tus support.
+func (h *TusHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.tusUploadService.MaxSize(), 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateUpload starts an upload of Upload-Length bytes. The filename and description keys
+// of Upload-Metadata name and describe the photo.
+func (h *TusHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	if r.Header.Get("Upload-Defer-Length") != "" {
+		util.RespondWithError(w, http.StatusBadRequest, "Upload-Defer-Length is not supported")
+		return
+	}
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		util.RespondWithError(w, http.StatusBadRequest, "Upload-Length must be a non-negative integer")
+		return
+	}
+	if length > h.tusUploadService.MaxSize() {
+		util.RespondWithError(w, http.StatusRequestEntityTooLarge, "Upload-Length exceeds Tus-Max-Size")
+		return
+	}
+	rawMetadata := r.Header.Get("Upload-Metadata")
+	metadata, err := parseUploadMetadata(rawMetadata)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	fileName := metadata["filename"]
+	if fileName == "" {
+		fileName = metadata["name"]
+	}
+
+	upload, err := h.tusUploadService.CreateUpload(r.Context(), interfaces.CreateTusUploadRequest{
+		UserID:      userID,
+		Length:      length,
+		FileName:    fileName,
+		Description: metadata["description"],
+		Metadata:    rawMetadata,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+upload.ID.String())
+	w.Header().Set("Upload-Offset", "0")
+	w.WriteHeader(http.StatusCreated)
+}
+
+// HeadUpload reports how many bytes of an upload the server has.
+func (h *TusHandler) HeadUpload(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	upload, err := h.tusUploadService.GetUpload(r.Context(), userID, id)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	writeUploadProgress(w, upload)
+	if upload.Metadata != "" {
+		w.Header().Set("Upload-Metadata", upload.Metadata)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// PatchUpload appends the body at Upload-Offset. The response to the write that completes
+// the upload carries the ID of the photo created from it.
+func (h *TusHandler) PatchUpload(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	if r.Header.Get("Content-Type") != tusContentType {
+		util.RespondWithError(w, http.StatusUnsupportedMediaType, "Content-Type must be "+tusContentType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		util.RespondWithError(w, http.StatusBadRequest, "Upload-Offset must be a non-negative integer")
+		return
+	}
+
+	upload, err := h.tusUploadService.WriteUpload(r.Context(), interfaces.WriteTusUploadRequest{
+		UserID: userID,
+		ID:     id,
+		Offset: offset,
+		Body:   r.Body,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	writeUploadProgress(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TerminateUpload stops an upload and frees what was stored for it.
+func (h *TusHandler) TerminateUpload(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.tusUploadService.TerminateUpload(r.Context(), userID, id); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeUploadProgress(w http.ResponseWriter, upload interfaces.TusUpload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if upload.PhotoID != nil {
+		w.Header().Set(photoIDHeader, upload.PhotoID.String())
+	}
+}
+
+// parseUploadMetadata decodes an Upload-Metadata header: comma-separated keys, each
+// followed by a space and its base64 encoded value unless it has none.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("Upload-Metadata has an empty key")
+		}
+		if _, ok := metadata[key]; ok {
+			return nil, errors.New("Upload-Metadata repeats the key " + key)
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("Upload-Metadata value of " + key + " is not base64")
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/google/uuid"
+)
+
+type UploadIntentHandler struct {
+	uploadIntentService interfaces.IUploadIntentService
+}
+
+func NewUploadIntentHandler(uploadIntentService interfaces.IUploadIntentService) *UploadIntentHandler {
+	return &UploadIntentHandler{uploadIntentService: uploadIntentService}
+}
+
+type CreateUploadIntentRequest struct {
+	FileName    string `json:"file_name"`
+	Description string `json:"description"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+type presignedPut struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+type presignedPost struct {
+	URL    string            `json:"url"`
+	Fields map[string]string `json:"fields"`
+}
+
+// UploadIntentResponse carries the reserved photo ID and two ways to upload the file:
+// a PUT sending the given headers, or a multipart form POST with the given fields
+// followed by the file in a `file` field.
+type UploadIntentResponse struct {
+	ID        uuid.UUID     `json:"id"`
+	PhotoID   uuid.UUID     `json:"photo_id"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	Put       presignedPut  `json:"put"`
+	Post      presignedPost `json:"post"`
+}
+
+// CreateUploadIntent reserves a photo and presigns the upload of its file to the bucket.
+func (h *UploadIntentHandler) CreateUploadIntent(w http.ResponseWriter, r *http.Request) {
+	userID, err := callerID(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	var body CreateUploadIntentRequest
+	if err := decodeStrictJSON(r, &body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ticket, err := h.uploadIntentService.CreateIntent(r.Context(), interfaces.CreateUploadIntentRequest{
+		UserID:      userID,
+		FileName:    body.FileName,
+		Description: body.Description,
+		ContentType: body.ContentType,
+		SizeBytes:   body.SizeBytes,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusCreated, UploadIntentResponse{
+		ID:        ticket.Intent.ID,
+		PhotoID:   ticket.Intent.ID,
+		ExpiresAt: ticket.Intent.ExpiresAt,
+		Put:       presignedPut{URL: ticket.Upload.PutURL, Headers: ticket.Upload.PutHeaders},
+		Post:      presignedPost{URL: ticket.Upload.PostURL, Fields: ticket.Upload.PostFields},
+	})
+}
+
+// CompleteUploadIntent creates the photo once its file is in the bucket. The photo is
+// processed in the background, as with other uploads.
+func (h *UploadIntentHandler) CompleteUploadIntent(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := callerAndIDParam(w, r)
+	if !ok {
+		return
+	}
+	photoID, err := h.uploadIntentService.CompleteIntent(r.Context(), userID, id)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusAccepted, map[string]string{"photo_id": photoID})
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"net/http"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/util"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+type WebhookHandler struct {
+	webhookService interfaces.IWebhookService
+}
+
+func NewWebhookHandler(webhookService interfaces.IWebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// UpdateWebhookRequest leaves absent fields unchanged.
+type UpdateWebhookRequest struct {
+	URL     *string  `json:"url"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// CreateWebhook registers an endpoint for the caller's events. The response carries the
+// signing secret, which is not shown again.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	owner, err := webhookOwner(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	var body CreateWebhookRequest
+	if err := decodeStrictJSON(r, &body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	webhook, err := h.webhookService.CreateWebhook(r.Context(), interfaces.CreateWebhookRequest{
+		Owner:  owner,
+		URL:    body.URL,
+		Events: body.Events,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	owner, err := webhookOwner(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	webhooks, err := h.webhookService.ListWebhooks(r.Context(), owner)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"webhooks": webhooks})
+}
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := webhookOwnerAndID(w, r)
+	if !ok {
+		return
+	}
+	webhook, err := h.webhookService.GetWebhook(r.Context(), owner, id)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, webhook)
+}
+
+// UpdateWebhook changes the URL or events of a webhook, or turns it off and on again.
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := webhookOwnerAndID(w, r)
+	if !ok {
+		return
+	}
+	var body UpdateWebhookRequest
+	if err := decodeStrictJSON(r, &body); err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	webhook, err := h.webhookService.UpdateWebhook(r.Context(), interfaces.UpdateWebhookRequest{
+		Owner:   owner,
+		ID:      id,
+		URL:     body.URL,
+		Events:  body.Events,
+		Enabled: body.Enabled,
+	})
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := webhookOwnerAndID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.webhookService.DeleteWebhook(r.Context(), owner, id); err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries shows the latest deliveries of a webhook, up to ?limit=.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := webhookOwnerAndID(w, r)
+	if !ok {
+		return
+	}
+	limit, err := intQueryParam(r, "limit", defaultWebhookDeliveriesLimit, 1, maxWebhookDeliveriesLimit)
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), owner, id, limit)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"deliveries": deliveries})
+}
+
+// Redeliver queues a delivery's event again and answers with the new delivery.
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := webhookOwnerAndID(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := uuidURLParam(r, "deliveryId")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	delivery, err := h.webhookService.Redeliver(r.Context(), owner, id, deliveryID)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusAccepted, delivery)
+}
+
+// webhookOwner is the caller, narrowed to its API key when it authenticated with one.
+func webhookOwner(r *http.Request) (interfaces.WebhookOwner, error) {
+	userID, err := callerID(r)
+	if err != nil {
+		return interfaces.WebhookOwner{}, err
+	}
+	principal, _ := principalFromContext(r.Context())
+	return interfaces.WebhookOwner{UserID: userID, APIKeyID: principal.APIKeyID}, nil
+}
+
+func webhookOwnerAndID(w http.ResponseWriter, r *http.Request) (interfaces.WebhookOwner, uuid.UUID, bool) {
+	owner, err := webhookOwner(r)
+	if err != nil {
+		respondWithServiceError(w, err)
+		return interfaces.WebhookOwner{}, uuid.Nil, false
+	}
+	id, err := uuidURLParam(r, "id")
+	if err != nil {
+		util.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return interfaces.WebhookOwner{}, uuid.Nil, false
+	}
+	return owner, id, true
+}
@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// AlbumRole is what a user may do with an album. Owners have full control; the other
+// roles are granted through album membership, each including the ones before it.
+type AlbumRole string
+
+const (
+	AlbumRoleViewer      AlbumRole = "viewer"
+	AlbumRoleContributor AlbumRole = "contributor"
+	AlbumRoleEditor      AlbumRole = "editor"
+	AlbumRoleOwner       AlbumRole = "owner"
+)
+
+type PhotoAction string
+
+const (
+	// PhotoActionView reads a photo; owners and members of an album holding it may.
+	PhotoActionView PhotoAction = "view"
+	// PhotoActionEdit changes or shares a photo, which only its owner may.
+	PhotoActionEdit PhotoAction = "edit"
+)
+
+type AlbumAction string
+
+const (
+	AlbumActionView AlbumAction = "view"
+	// AlbumActionAddPhotos adds the caller's own photos and removes photos they added.
+	AlbumActionAddPhotos AlbumAction = "add_photos"
+	// AlbumActionEdit changes the album's details, order and cover and removes any photo.
+	AlbumActionEdit AlbumAction = "edit"
+	// AlbumActionManage deletes the album and manages its members and share links.
+	AlbumActionManage AlbumAction = "manage"
+)
+
+// IAccessPolicy decides what a user may do with photos and albums. Resources the user
+// cannot see at all are reported as ErrNotFound so their existence is not revealed;
+// resources the user can see but not act on are reported as ErrForbidden.
+type IAccessPolicy interface {
+	// AuthorizeUser checks that a request is made on behalf of a user, for actions on the
+	// user's own photos.
+	AuthorizeUser(userID uuid.UUID) error
+	AuthorizePhoto(ctx context.Context, userID uuid.UUID, photoID uuid.UUID, action PhotoAction) (PhotoRecord, error)
+	// AuthorizeAlbum returns the album with Role set to the user's role in it.
+	AuthorizeAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID, action AlbumAction) (Album, error)
+}
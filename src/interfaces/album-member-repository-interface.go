@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type SetAlbumMemberRepoRequest struct {
+	AlbumID uuid.UUID
+	UserID  uuid.UUID
+	Role    AlbumRole
+	AddedBy uuid.UUID
+}
+
+type IAlbumMemberRepository interface {
+	SetAlbumMember(ctx context.Context, req SetAlbumMemberRepoRequest) (AlbumMember, error)
+	GetAlbumMember(ctx context.Context, albumID uuid.UUID, userID uuid.UUID) (AlbumMember, error)
+	ListAlbumMembers(ctx context.Context, albumID uuid.UUID) ([]AlbumMember, error)
+	RemoveAlbumMember(ctx context.Context, albumID uuid.UUID, userID uuid.UUID) error
+	// IsPhotoShared reports whether the photo is in an album the user owns or is a member of.
+	IsPhotoShared(ctx context.Context, photoID uuid.UUID, userID uuid.UUID) (bool, error)
+}
@@ -0,0 +1,34 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type CreateAlbumRepoRequest struct {
+	OwnerID     uuid.UUID
+	Title       string
+	Description string
+}
+
+type UpdateAlbumRepoRequest struct {
+	AlbumID        uuid.UUID
+	Title          *string
+	SetDescription bool
+	Description    *string
+}
+
+type IAlbumRepository interface {
+	CreateAlbum(ctx context.Context, req CreateAlbumRepoRequest) (Album, error)
+	GetAlbum(ctx context.Context, id uuid.UUID) (Album, error)
+	// ListAlbums returns the albums the user owns or is a member of, with the user's role.
+	ListAlbums(ctx context.Context, userID uuid.UUID) ([]Album, error)
+	UpdateAlbum(ctx context.Context, req UpdateAlbumRepoRequest) error
+	DeleteAlbum(ctx context.Context, id uuid.UUID) error
+	AddPhotos(ctx context.Context, albumID uuid.UUID, ownerID uuid.UUID, photoIDs []uuid.UUID) (int64, error)
+	RemovePhoto(ctx context.Context, albumID uuid.UUID, photoID uuid.UUID) error
+	ReorderPhotos(ctx context.Context, albumID uuid.UUID, photoIDs []uuid.UUID) error
+	SetCover(ctx context.Context, albumID uuid.UUID, photoID uuid.UUID) error
+	ListAlbumPhotos(ctx context.Context, albumID uuid.UUID, limit int, offset int) ([]AlbumPhoto, error)
+}
@@ -0,0 +1,89 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Album struct {
+	ID           uuid.UUID  `json:"id"`
+	OwnerID      uuid.UUID  `json:"owner_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	CoverPhotoID *uuid.UUID `json:"cover_photo_id,omitempty"`
+	PhotoCount   int        `json:"photo_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	// Role is the caller's role in the album.
+	Role AlbumRole `json:"role,omitempty"`
+}
+
+type AlbumMember struct {
+	AlbumID   uuid.UUID `json:"album_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Role      AlbumRole `json:"role"`
+	AddedBy   uuid.UUID `json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AlbumPhoto struct {
+	Photo
+	Position int `json:"position"`
+}
+
+type CreateAlbumRequest struct {
+	UserID      uuid.UUID
+	Title       string
+	Description string
+}
+
+// UpdateAlbumRequest changes the fields that are set; a nil Description with
+// SetDescription clears it.
+type UpdateAlbumRequest struct {
+	UserID         uuid.UUID
+	AlbumID        uuid.UUID
+	Title          *string
+	SetDescription bool
+	Description    *string
+}
+
+type AlbumPhotosRequest struct {
+	UserID   uuid.UUID
+	AlbumID  uuid.UUID
+	PhotoIDs []uuid.UUID
+}
+
+type ListAlbumPhotosRequest struct {
+	UserID  uuid.UUID
+	AlbumID uuid.UUID
+	Limit   int
+	Offset  int
+}
+
+// SetAlbumMemberRequest adds a member to an album or changes their role.
+type SetAlbumMemberRequest struct {
+	UserID   uuid.UUID
+	AlbumID  uuid.UUID
+	MemberID uuid.UUID
+	Role     AlbumRole
+}
+
+type IAlbumService interface {
+	CreateAlbum(ctx context.Context, request CreateAlbumRequest) (Album, error)
+	GetAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) (Album, error)
+	ListAlbums(ctx context.Context, userID uuid.UUID) ([]Album, error)
+	UpdateAlbum(ctx context.Context, request UpdateAlbumRequest) (Album, error)
+	DeleteAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) error
+	AddPhotos(ctx context.Context, request AlbumPhotosRequest) (Album, error)
+	RemovePhoto(ctx context.Context, userID uuid.UUID, albumID uuid.UUID, photoID uuid.UUID) error
+	ReorderPhotos(ctx context.Context, request AlbumPhotosRequest) error
+	SetCover(ctx context.Context, userID uuid.UUID, albumID uuid.UUID, photoID uuid.UUID) (Album, error)
+	ListAlbumPhotos(ctx context.Context, request ListAlbumPhotosRequest) ([]AlbumPhoto, error)
+	ListMembers(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) ([]AlbumMember, error)
+	SetMember(ctx context.Context, request SetAlbumMemberRequest) (AlbumMember, error)
+	// RemoveMember lets the owner remove anyone and members leave on their own.
+	RemoveMember(ctx context.Context, userID uuid.UUID, albumID uuid.UUID, memberID uuid.UUID) error
+}
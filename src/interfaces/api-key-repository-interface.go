@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAPIKeyRepoRequest struct {
+	Name       string
+	KeyPrefix  string
+	KeyHash    []byte
+	Scopes     []Scope
+	OwnerID    *uuid.UUID
+	OnBehalfOf bool
+	CreatedBy  *uuid.UUID
+	ExpiresAt  *time.Time
+}
+
+type IAPIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, req CreateAPIKeyRepoRequest) (APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash []byte) (APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	// TouchAPIKey records that the key was just used.
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,48 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []Scope    `json:"scopes"`
+	OwnerID    *uuid.UUID `json:"owner_id,omitempty"`
+	OnBehalfOf bool       `json:"on_behalf_of"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	KeyHash    []byte     `json:"-"`
+}
+
+// CreatedAPIKey is returned once when a key is minted; only its hash is kept.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type CreateAPIKeyRequest struct {
+	// CreatedBy is the admin minting the key, if it is a user.
+	CreatedBy  *uuid.UUID
+	Name       string
+	Scopes     []Scope
+	OwnerID    *uuid.UUID
+	OnBehalfOf bool
+	ExpiresAt  *time.Time
+}
+
+type IAPIKeyService interface {
+	CreateAPIKey(ctx context.Context, request CreateAPIKeyRequest) (CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	// VerifyAPIKey checks a key presented by a caller. Unknown, revoked and expired keys are
+	// reported as ErrUnauthorized.
+	VerifyAPIKey(ctx context.Context, key string) (Principal, error)
+}
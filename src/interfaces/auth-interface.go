@@ -0,0 +1,43 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Scope limits what an authenticated caller may do.
+type Scope string
+
+const (
+	ScopePhotosRead  Scope = "photos:read"
+	ScopePhotosWrite Scope = "photos:write"
+	ScopeAdmin       Scope = "admin"
+)
+
+// Principal is the authenticated caller of a request: a user signed in with a token or a
+// service using an API key.
+type Principal struct {
+	// UserID is the user requests act as; uuid.Nil for API keys not bound to an owner.
+	UserID uuid.UUID
+	// APIKeyID is set when the caller authenticated with an API key.
+	APIKeyID *uuid.UUID
+	// OnBehalfOf is set for API keys that may act as the user named in X-On-Behalf-Of.
+	OnBehalfOf bool
+	Scopes     []Scope
+}
+
+func (p Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ITokenVerifier checks a bearer token and returns the user it was issued to.
+// Invalid, expired and unknown tokens are reported as ErrUnauthorized.
+type ITokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (Principal, error)
+}
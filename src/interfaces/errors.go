@@ -0,0 +1,33 @@
+package interfaces
+
+import (
+	"errors"
+	"time"
+)
+
+// Sentinel errors shared by repositories and services. Handlers map them to
+// HTTP status codes, so wrap them with fmt.Errorf("%w: ...") to add detail.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrConflict        = errors.New("conflict")
+	ErrQuotaExceeded   = errors.New("storage quota exceeded")
+	ErrRateLimited     = errors.New("rate limited")
+)
+
+// RateLimitedError is ErrRateLimited with how long the caller should wait before trying
+// again.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+	Detail     string
+}
+
+func (e *RateLimitedError) Error() string {
+	return ErrRateLimited.Error() + ": " + e.Detail
+}
+
+func (e *RateLimitedError) Unwrap() error {
+	return ErrRateLimited
+}
@@ -0,0 +1,87 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types.
+const (
+	EventPhotoCreated = "photo.created"
+	// EventPhotoProcessed follows background processing, whether the photo ended up
+	// ready or failed.
+	EventPhotoProcessed = "photo.processed"
+	// EventPhotoUpdated follows another version of a photo's file becoming current.
+	EventPhotoUpdated = "photo.updated"
+	// EventPhotoDeleted follows moving a photo to the trash, EventPhotoRestored taking it
+	// out again and EventPhotoPurged deleting it for good.
+	EventPhotoDeleted  = "photo.deleted"
+	EventPhotoRestored = "photo.restored"
+	EventPhotoPurged   = "photo.purged"
+)
+
+// EventTypes lists every event type, in the order they are documented.
+var EventTypes = []string{EventPhotoCreated, EventPhotoProcessed, EventPhotoUpdated, EventPhotoDeleted, EventPhotoRestored, EventPhotoPurged}
+
+// Event is something that happened to an owner's data. Data is sent as JSON.
+type Event struct {
+	ID        uuid.UUID
+	Type      string
+	OwnerID   uuid.UUID
+	CreatedAt time.Time
+	Data      interface{}
+}
+
+// PhotoEventData is the data of photo events.
+type PhotoEventData struct {
+	PhotoID uuid.UUID `json:"photo_id"`
+	Status  string    `json:"status,omitempty"`
+	// Version is the current version of the photo's file, in photo.updated events.
+	Version int `json:"version,omitempty"`
+}
+
+// IEventPublisher passes events on to whoever listens for them. ID and CreatedAt are
+// filled in when left empty.
+type IEventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// SequencedEvent is a published event with its number. Numbers grow in publishing order
+// across all instances.
+type SequencedEvent struct {
+	Seq   int64
+	Event Event
+}
+
+type IEventRepository interface {
+	// NotifyEvent numbers an event and sends it to every listening instance.
+	NotifyEvent(ctx context.Context, event Event) (int64, error)
+	// ListenEvents passes published events to handle, in order, until ctx ends. synced is
+	// called with the latest event number whenever listening (re)starts: events up to it
+	// may have been missed, later ones are handled. The Data of handled events is a
+	// json.RawMessage.
+	ListenEvents(ctx context.Context, handle func(SequencedEvent), synced func(seq int64)) error
+}
+
+// EventSubscription is a user's live event stream.
+type EventSubscription struct {
+	// Replay holds the events after the one the client saw last.
+	Replay []SequencedEvent
+	// Reset is set when events after the one the client saw last may be gone; the client
+	// should reload what it shows. ResetSeq is the number to resume from afterwards.
+	Reset    bool
+	ResetSeq int64
+	// Events is closed when the stream ends, such as when the client falls too far behind
+	// or the server shuts down; clients reconnect and resume.
+	Events <-chan SequencedEvent
+	Close  func()
+}
+
+type IEventStreamService interface {
+	IEventPublisher
+	// Subscribe streams the user's events. lastSeq is the number of the last event the
+	// client saw, or 0 for none.
+	Subscribe(userID uuid.UUID, lastSeq int64) EventSubscription
+}
@@ -1,6 +1,9 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type UploadFileRequest struct {
 	UserID   string
@@ -11,4 +14,94 @@ type UploadFileRequest struct {
 
 type IFileUpload interface {
 	Upload(ctx context.Context, request UploadFileRequest) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// PresignURLRequest asks for a temporary URL to a stored file. Download makes the browser
+// save the file instead of displaying it.
+type PresignURLRequest struct {
+	Key      string
+	Download bool
+	Expires  time.Duration
+}
+
+type IFileURLSigner interface {
+	PresignURL(ctx context.Context, request PresignURLRequest) (string, error)
+}
+
+// UploadedPart identifies a stored part of a multipart upload.
+type UploadedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// IMultipartStorage assembles a file from parts uploaded one at a time, and stores the
+// small objects used to stage data between parts.
+type IMultipartStorage interface {
+	IFileReader
+	CreateMultipartUpload(ctx context.Context, key string) (string, error)
+	UploadPart(ctx context.Context, key string, uploadID string, partNumber int32, data []byte) (string, error)
+	CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []UploadedPart) error
+	AbortMultipartUpload(ctx context.Context, key string, uploadID string) error
+	PutObject(ctx context.Context, key string, data []byte) error
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// PresignUploadRequest asks for temporary credentials to upload one file of exactly
+// SizeBytes bytes and the given content type to Key.
+type PresignUploadRequest struct {
+	Key         string
+	ContentType string
+	SizeBytes   int64
+	Expires     time.Duration
+}
+
+// PresignedUpload tells a client how to upload a file directly to storage: either a PUT to
+// PutURL sending PutHeaders, or a multipart form POST to PostURL with PostFields ahead of
+// the file field.
+type PresignedUpload struct {
+	PutURL     string
+	PutHeaders map[string]string
+	PostURL    string
+	PostFields map[string]string
+}
+
+// StoredObject describes a file in storage.
+type StoredObject struct {
+	SizeBytes   int64
+	ContentType string
+}
+
+type IFileReader interface {
+	// GetObjectHead returns the first n bytes of the file at key.
+	GetObjectHead(ctx context.Context, key string, n int64) ([]byte, error)
+	// HashObject returns the hex SHA-256 digest of the file at key, read as a stream.
+	HashObject(ctx context.Context, key string) (string, error)
+}
+
+// IDirectUploadStorage lets clients upload files to storage without going through the
+// service.
+type IDirectUploadStorage interface {
+	IFileReader
+	PresignUpload(ctx context.Context, request PresignUploadRequest) (PresignedUpload, error)
+	// HeadObject describes the file at key, or returns ErrNotFound.
+	HeadObject(ctx context.Context, key string) (StoredObject, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// IVersionedFileStorage keeps the earlier contents of overwritten files, when the storage
+// supports it.
+type IVersionedFileStorage interface {
+	// Versioned reports whether overwriting a file keeps its earlier contents.
+	Versioned() bool
+	// PutVersion overwrites the file at key and returns the version ID of the new contents.
+	// fileName gives the content type.
+	PutVersion(ctx context.Context, key string, fileName string, data []byte) (string, error)
+	// LatestVersionID returns the version ID of the current contents of key, or ErrNotFound.
+	LatestVersionID(ctx context.Context, key string) (string, error)
+	// RestoreVersion copies a version of sourceKey over the file at key.
+	RestoreVersion(ctx context.Context, key string, sourceKey string, versionID string) error
+	// DeleteVersion removes one version of key; the previous one becomes current again.
+	DeleteVersion(ctx context.Context, key string, versionID string) error
 }
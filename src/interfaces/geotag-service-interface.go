@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GeotagFromGPXRequest struct {
+	UserID  uuid.UUID
+	GPXData []byte
+	// ClockOffset is added to the camera's capture time to get UTC.
+	ClockOffset time.Duration
+	// MaxGap is how far a photo may be from the nearest track point and still be matched.
+	MaxGap time.Duration
+	DryRun bool
+}
+
+type GeotagMatch struct {
+	PhotoID      uuid.UUID     `json:"photo_id"`
+	CapturedAt   time.Time     `json:"captured_at"`
+	Location     PhotoLocation `json:"location"`
+	GapSeconds   float64       `json:"gap_seconds"`
+	Interpolated bool          `json:"interpolated"`
+}
+
+type GeotagResult struct {
+	DryRun      bool          `json:"dry_run"`
+	TrackPoints int           `json:"track_points"`
+	Matched     []GeotagMatch `json:"matched"`
+	Unmatched   []uuid.UUID   `json:"unmatched"`
+	Updated     int64         `json:"updated"`
+}
+
+type IGeotagService interface {
+	GeotagFromGPX(ctx context.Context, request GeotagFromGPXRequest) (GeotagResult, error)
+}
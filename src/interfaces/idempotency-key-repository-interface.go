@@ -0,0 +1,43 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyID names an Idempotency-Key: keys are per caller and per route (Scope).
+type IdempotencyKeyID struct {
+	OwnerID uuid.UUID
+	Scope   string
+	Key     string
+}
+
+// StoredResponse is a response kept to be replayed to retries.
+type StoredResponse struct {
+	Status      int
+	ContentType string
+	// Location is the Location header, which responses creating something carry.
+	Location string
+	Body     []byte
+}
+
+// IdempotencyRecord is a key in use. Response is nil while the request holding the key
+// is still running.
+type IdempotencyRecord struct {
+	ID          IdempotencyKeyID
+	Fingerprint string
+	Response    *StoredResponse
+}
+
+type IIdempotencyKeyRepository interface {
+	// ClaimIdempotencyKey takes a free key for a request with the given fingerprint. Keys
+	// created before expiredBefore are free again. It returns false when the key is taken.
+	ClaimIdempotencyKey(ctx context.Context, id IdempotencyKeyID, fingerprint string, expiredBefore time.Time) (bool, error)
+	GetIdempotencyKey(ctx context.Context, id IdempotencyKeyID) (IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, id IdempotencyKeyID, response StoredResponse) error
+	// ReleaseIdempotencyKey frees a key whose request did not finish.
+	ReleaseIdempotencyKey(ctx context.Context, id IdempotencyKeyID) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, createdBefore time.Time) (int64, error)
+}
@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrIdempotencyKeyInUse is returned to a duplicate of a request that is still running.
+var ErrIdempotencyKeyInUse = fmt.Errorf("%w: a request with this Idempotency-Key is in progress", ErrConflict)
+
+type IIdempotencyService interface {
+	// Begin claims the key for a request with the given fingerprint. When the request
+	// already ran, its stored response is returned instead. A key used for a different
+	// request is an ErrConflict; while a duplicate is running Begin waits for it a little,
+	// then gives up with ErrIdempotencyKeyInUse.
+	Begin(ctx context.Context, id IdempotencyKeyID, fingerprint string) (*StoredResponse, error)
+	// Complete stores the response of a claimed key for replay.
+	Complete(ctx context.Context, id IdempotencyKeyID, response StoredResponse) error
+	// Release frees a claimed key without storing a response, so a retry runs again.
+	Release(ctx context.Context, id IdempotencyKeyID) error
+	// PurgeExpired deletes keys past their retention and returns how many it deleted.
+	PurgeExpired(ctx context.Context) (int64, error)
+}
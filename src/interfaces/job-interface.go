@@ -0,0 +1,59 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Job kinds.
+const (
+	// JobKindProcessPhoto extracts the metadata of a new photo; the payload is a
+	// ProcessPhotoPayload.
+	JobKindProcessPhoto = "photo.process"
+)
+
+type ProcessPhotoPayload struct {
+	PhotoID string `json:"photo_id"`
+}
+
+// NewJob is a job to enqueue. It is attempted up to MaxAttempts times.
+type NewJob struct {
+	Kind        string
+	Payload     json.RawMessage
+	MaxAttempts int
+}
+
+// Job is a claimed job; Attempts counts the current attempt.
+type Job struct {
+	ID          int64
+	Kind        string
+	Payload     json.RawMessage
+	Attempts    int
+	MaxAttempts int
+}
+
+// LastAttempt reports whether a failure of the current attempt is final.
+func (j Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+type IJobRepository interface {
+	EnqueueJob(ctx context.Context, job NewJob) (int64, error)
+	// ClaimJobs locks up to limit runnable jobs for the caller, skipping jobs other workers
+	// hold.
+	ClaimJobs(ctx context.Context, limit int) ([]Job, error)
+	// CompleteJob removes a finished job.
+	CompleteJob(ctx context.Context, id int64) error
+	// RetryJob makes a failed job runnable again after delay.
+	RetryJob(ctx context.Context, id int64, delay time.Duration, lastError string) error
+	// BuryJob moves a job that failed its last attempt to the dead state.
+	BuryJob(ctx context.Context, id int64, lastError string) error
+}
+
+// JobHandler runs the jobs of one kind. Run failures are retried; Dead, when set, is
+// called once a job has failed its last attempt.
+type JobHandler struct {
+	Run  func(ctx context.Context, job Job) error
+	Dead func(ctx context.Context, job Job, err error)
+}
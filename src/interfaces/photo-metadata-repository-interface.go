@@ -7,13 +7,56 @@ import (
 	"github.com/google/uuid"
 )
 
+// Sources recorded for each photo_metadata value.
+const (
+	MetadataSourceExif     = "exif"
+	MetadataSourceGPX      = "gpx"
+	MetadataSourceManual   = "manual"
+	MetadataSourceGeocoder = "geocoder"
+)
+
 type CreatePhotoMetadataRepoRequest struct {
-	Id        uuid.UUID
-	Longitude *float64
-	Latitude  *float64
-	CreatedAt *time.Time
+	Id          uuid.UUID
+	Longitude   *float64
+	Latitude    *float64
+	Altitude    *float64
+	CreatedAt   *time.Time
+	CameraMake  string
+	CameraModel string
+	Source      string
+}
+
+type UpdatePhotoMetadataRepoRequest struct {
+	PhotoID       uuid.UUID
+	SetLocation   bool
+	Location      *PhotoLocation
+	SetCapturedAt bool
+	CapturedAt    *time.Time
+}
+
+type ListNearbyPhotosRepoRequest struct {
+	PhotoID      uuid.UUID
+	ViewerID     uuid.UUID
+	RadiusMeters float64
+	TimeWindow   *time.Duration
+	Limit        int
+}
+
+type PhotoCaptureTime struct {
+	PhotoID    uuid.UUID
+	CapturedAt time.Time
+}
+
+type SetPhotoLocationsRepoRequest struct {
+	OwnerID   uuid.UUID
+	Locations map[uuid.UUID]PhotoLocation
 }
 
 type IPhotoMetadataRepository interface {
 	CreatePhotoMetadata(ctx context.Context, req CreatePhotoMetadataRepoRequest) (string, error)
+	GetPhotoMetadata(ctx context.Context, photoID uuid.UUID) (PhotoMetadata, error)
+	UpdatePhotoMetadataManually(ctx context.Context, req UpdatePhotoMetadataRepoRequest) error
+	ListNearbyPhotos(ctx context.Context, req ListNearbyPhotosRepoRequest) ([]NearbyPhoto, error)
+	ListPhotosWithoutLocation(ctx context.Context, ownerID uuid.UUID) ([]PhotoCaptureTime, error)
+	SetPhotoLocations(ctx context.Context, req SetPhotoLocationsRepoRequest) (int64, error)
 }
@@ -0,0 +1,113 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sort orders accepted by PhotoQuery.Sort.
+const (
+	PhotoSortUploadedDesc = "uploaded_desc"
+	PhotoSortUploadedAsc  = "uploaded_asc"
+	PhotoSortCapturedDesc = "captured_desc"
+	PhotoSortCapturedAsc  = "captured_asc"
+	PhotoSortRelevance    = "relevance"
+)
+
+// PhotoQuery is the filter document of POST /v1/photos/query. Every filter is optional and
+// all of the given ones must match.
+type PhotoQuery struct {
+	Text        string        `json:"text,omitempty"`
+	Tags        *TagFilter    `json:"tags,omitempty"`
+	CapturedAt  *TimeRange    `json:"captured_at,omitempty"`
+	UploadedAt  *TimeRange    `json:"uploaded_at,omitempty"`
+	BBox        *BoundingBox  `json:"bbox,omitempty"`
+	Near        *RadiusFilter `json:"near,omitempty"`
+	Camera      *CameraFilter `json:"camera,omitempty"`
+	Formats     []string      `json:"formats,omitempty"`
+	HasLocation *bool         `json:"has_location,omitempty"`
+	Sort        string        `json:"sort,omitempty"`
+	Limit       int           `json:"limit,omitempty"`
+	Cursor      string        `json:"cursor,omitempty"`
+}
+
+// TagFilter matches photos carrying all (the default) or any of the named tags.
+type TagFilter struct {
+	Names []string `json:"names"`
+	Match string   `json:"match,omitempty"`
+}
+
+// TimeRange is the half-open interval [From, To); either end may be left open.
+type TimeRange struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+type BoundingBox struct {
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+type RadiusFilter struct {
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	RadiusMeters float64 `json:"radius_m"`
+}
+
+// CameraFilter matches the EXIF camera make and model exactly, ignoring case.
+type CameraFilter struct {
+	Make  string `json:"make,omitempty"`
+	Model string `json:"model,omitempty"`
+}
+
+type QueryPhotosRequest struct {
+	UserID uuid.UUID
+	Query  PhotoQuery
+}
+
+// QueriedPhoto is a photo query match; Rank and Headline are only set for text queries.
+// Headline is HTML, as in PhotoSearchResult.
+type QueriedPhoto struct {
+	Photo
+	Rank     float64 `json:"rank,omitempty"`
+	Headline string  `json:"headline,omitempty"`
+}
+
+// PhotoQueryPage is one page of results; NextCursor is empty on the last page.
+type PhotoQueryPage struct {
+	Photos     []QueriedPhoto `json:"photos"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// PhotoCursor is the sort key of the last photo on a page.
+type PhotoCursor struct {
+	Sort string     `json:"s"`
+	Time *time.Time `json:"t,omitempty"`
+	Rank *float64   `json:"r,omitempty"`
+	ID   uuid.UUID  `json:"id"`
+}
+
+// PhotoQueryRepoRequest is a validated PhotoQuery. Tag names are lower-cased and TSQuery
+// is a to_tsquery expression.
+type PhotoQueryRepoRequest struct {
+	OwnerID      uuid.UUID
+	TSQuery      string
+	TagNames     []string
+	TagMatchAll  bool
+	CapturedFrom *time.Time
+	CapturedTo   *time.Time
+	UploadedFrom *time.Time
+	UploadedTo   *time.Time
+	BBox         *BoundingBox
+	Near         *RadiusFilter
+	CameraMake   string
+	CameraModel  string
+	Formats      []string
+	HasLocation  *bool
+	Sort         string
+	After        *PhotoCursor
+	Limit        int
+}
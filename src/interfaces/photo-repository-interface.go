@@ -2,16 +2,90 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type CreatePhotoRepoRequest struct {
+	ID          uuid.UUID
 	UserID      uuid.UUID
 	Description string
 	URL         string
+	Format      string
+	SizeBytes   int64
+	// SHA256 is the hex digest of the file, when it is known.
+	SHA256 string
+	// Quota is checked atomically with the insert; exceeding it returns ErrQuotaExceeded.
+	Quota StorageQuota
+	// Status is one of the PhotoStatus values.
+	Status string
+	// Jobs are enqueued in the same transaction, so they run for every created photo.
+	Jobs []NewJob
+}
+
+// RestorePhotoRepoRequest takes one of the owner's photos out of the trash.
+type RestorePhotoRepoRequest struct {
+	ID      uuid.UUID
+	OwnerID uuid.UUID
+	// Reprocess jobs are enqueued in the same transaction when the photo is still
+	// processing, since processing skips photos in the trash.
+	Reprocess []NewJob
+}
+
+// PurgePhotosRepoRequest selects trashed photos to delete for good.
+type PurgePhotosRepoRequest struct {
+	// OwnerID limits the purge to one owner's trash when set.
+	OwnerID *uuid.UUID
+	// DeletedBefore limits the purge to photos trashed before it when set.
+	DeletedBefore *time.Time
+	Limit         int
+}
+
+// DeletedPhoto is what is left of a deleted photo: the stored files still have to be removed.
+type DeletedPhoto struct {
+	ID      uuid.UUID
+	OwnerID uuid.UUID
+	URL     string
+	// SizeBytes counts every version of the photo.
+	SizeBytes int64
+	// Files are the storage keys of all versions, URL included.
+	Files []string
+}
+
+type PhotoRecord struct {
+	ID          uuid.UUID
+	OwnerID     uuid.UUID
+	Description string
+	URL         string
+	CreatedAt   time.Time
+	HasLocation bool
+	CapturedAt  *time.Time
+	// CurrentVersion is the version of the file at URL, and CurrentSHA256 its digest
+	// when it is known.
+	CurrentVersion int
+	CurrentSHA256  string
 }
 
 type IPhotoRepository interface {
 	CreatePhoto(ctx context.Context, req CreatePhotoRepoRequest) (string, error)
+	// TrashPhoto moves a photo to its owner's trash, where reads no longer find it.
+	TrashPhoto(ctx context.Context, id uuid.UUID) error
+	RestorePhoto(ctx context.Context, req RestorePhotoRepoRequest) error
+	// ListTrashedPhotos lists the owner's trash, most recently deleted first.
+	ListTrashedPhotos(ctx context.Context, ownerID uuid.UUID, limit int, offset int) ([]TrashedPhoto, error)
+	// PurgePhotos deletes trashed photos and releases their storage in one transaction.
+	PurgePhotos(ctx context.Context, req PurgePhotosRepoRequest) ([]DeletedPhoto, error)
+	GetPhoto(ctx context.Context, id uuid.UUID) (PhotoRecord, error)
+	GetPhotoDetail(ctx context.Context, id uuid.UUID) (Photo, error)
+	ListPhotos(ctx context.Context, ownerID uuid.UUID, limit int, offset int) ([]Photo, error)
+	// SearchPhotos matches tsQuery, a to_tsquery expression, against the owner's descriptions.
+	SearchPhotos(ctx context.Context, ownerID uuid.UUID, tsQuery string, limit int, offset int) ([]PhotoSearchResult, error)
+	QueryPhotos(ctx context.Context, req PhotoQueryRepoRequest) ([]QueriedPhoto, error)
+	CountPhotos(ctx context.Context, req PhotoQueryRepoRequest) (int64, error)
+	SetPhotoStatus(ctx context.Context, id uuid.UUID, status string) error
+	// SetPhotoVersionMetadata records the metadata extracted from a version's file.
+	SetPhotoVersionMetadata(ctx context.Context, photoID uuid.UUID, version int, metadata PhotoVersionMetadata) error
+	// SetPhotoVersionSHA256 records the digest of a version stored without one.
+	SetPhotoVersionSHA256(ctx context.Context, photoID uuid.UUID, version int, sha256 string) error
 }
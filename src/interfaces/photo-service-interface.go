@@ -2,10 +2,19 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// Photo statuses. New photos are processing until their metadata has been extracted in
+// the background; failed means processing gave up.
+const (
+	PhotoStatusProcessing = "processing"
+	PhotoStatusReady      = "ready"
+	PhotoStatusFailed     = "failed"
+)
+
 type CreatePhotoRequest struct {
 	UserID      uuid.UUID
 	Description string
@@ -13,6 +22,132 @@ type CreatePhotoRequest struct {
 	FileData    []byte
 }
 
+// CreateStoredPhotoRequest creates a photo from a file already stored at URL. PhotoID is
+// the ID reserved for the photo, if any. FileData may hold just the start of the file,
+// enough to read its metadata, when SizeBytes gives the size of the whole file.
+type CreateStoredPhotoRequest struct {
+	PhotoID     uuid.UUID
+	UserID      uuid.UUID
+	Description string
+	FileName    string
+	FileData    []byte
+	SizeBytes   int64
+	URL         string
+}
+
+type PhotoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type Photo struct {
+	ID          uuid.UUID      `json:"id"`
+	OwnerID     uuid.UUID      `json:"owner_id"`
+	Description string         `json:"description"`
+	URL         string         `json:"url"`
+	Status      string         `json:"status,omitempty"`
+	Location    *PhotoLocation `json:"location,omitempty"`
+	CapturedAt  *time.Time     `json:"captured_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	// LocationPrivate is set when the location falls inside one of the owner's private zones.
+	LocationPrivate bool `json:"-"`
+}
+
+// TrashedPhoto is a deleted photo waiting in the trash until PurgeAt.
+type TrashedPhoto struct {
+	ID          uuid.UUID `json:"id"`
+	Description string    `json:"description"`
+	URL         string    `json:"url"`
+	Status      string    `json:"status"`
+	SizeBytes   int64     `json:"size_bytes"`
+	CreatedAt   time.Time `json:"created_at"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	PurgeAt     time.Time `json:"purge_at"`
+}
+
+type PhotoMetadata struct {
+	PhotoID          uuid.UUID      `json:"photo_id"`
+	Location         *PhotoLocation `json:"location"`
+	LocationSource   string         `json:"location_source,omitempty"`
+	CapturedAt       *time.Time     `json:"captured_at"`
+	CapturedAtSource string         `json:"captured_at_source,omitempty"`
+}
+
+// UpdatePhotoMetadataRequest sets or clears (nil value) each field whose Set flag is true.
+type UpdatePhotoMetadataRequest struct {
+	UserID        uuid.UUID
+	PhotoID       uuid.UUID
+	SetLocation   bool
+	Location      *PhotoLocation
+	SetCapturedAt bool
+	CapturedAt    *time.Time
+}
+
+// ListPhotosRequest lists the user's photos, optionally only those carrying all
+// (MatchAll) or any of the given tags.
+type ListPhotosRequest struct {
+	UserID   uuid.UUID
+	Tags     []string
+	MatchAll bool
+	Limit    int
+	Offset   int
+}
+
+type ListTrashRequest struct {
+	UserID uuid.UUID
+	Limit  int
+	Offset int
+}
+
+type SearchPhotosRequest struct {
+	UserID uuid.UUID
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// PhotoSearchResult is a search match. Headline is HTML: the escaped description with matches
+// wrapped in <mark> tags.
+type PhotoSearchResult struct {
+	Photo
+	Rank     float64 `json:"rank"`
+	Headline string  `json:"headline"`
+}
+
+type NearbyPhoto struct {
+	Photo
+	DistanceMeters float64 `json:"distance_m"`
+}
+
+type GetNearbyPhotosRequest struct {
+	ViewerID     uuid.UUID
+	PhotoID      uuid.UUID
+	RadiusMeters float64
+	TimeWindow   *time.Duration
+	Limit        int
+}
+
 type IPhotoService interface {
 	CreatePhoto(ctx context.Context, request CreatePhotoRequest) (string, error)
+	CreateStoredPhoto(ctx context.Context, request CreateStoredPhotoRequest) (string, error)
+	ListPhotos(ctx context.Context, request ListPhotosRequest) ([]Photo, error)
+	SearchPhotos(ctx context.Context, request SearchPhotosRequest) ([]PhotoSearchResult, error)
+	QueryPhotos(ctx context.Context, request QueryPhotosRequest) (PhotoQueryPage, error)
+	GetPhoto(ctx context.Context, viewerID uuid.UUID, photoID uuid.UUID) (Photo, error)
+	// DeletePhoto moves a photo to the trash. It is purged after the retention period
+	// unless restored first.
+	DeletePhoto(ctx context.Context, userID uuid.UUID, photoID uuid.UUID) error
+	ListTrash(ctx context.Context, request ListTrashRequest) ([]TrashedPhoto, error)
+	RestorePhoto(ctx context.Context, userID uuid.UUID, photoID uuid.UUID) error
+	// EmptyTrash deletes the photos in the user's trash for good and returns how many.
+	EmptyTrash(ctx context.Context, userID uuid.UUID) (int, error)
+	// PurgeTrash deletes photos that have been in the trash for longer than the retention
+	// period, of all users, and returns how many.
+	PurgeTrash(ctx context.Context) (int, error)
+	GetNearbyPhotos(ctx context.Context, request GetNearbyPhotosRequest) ([]NearbyPhoto, error)
+	UpdatePhotoMetadata(ctx context.Context, request UpdatePhotoMetadataRequest) (PhotoMetadata, error)
+	// ProcessPhoto extracts the metadata of a new photo from its file and marks it ready.
+	ProcessPhoto(ctx context.Context, photoID uuid.UUID) error
+	// FailPhotoProcessing marks a photo whose processing gave up as failed.
+	FailPhotoProcessing(ctx context.Context, photoID uuid.UUID) error
 }
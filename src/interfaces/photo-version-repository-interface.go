@@ -0,0 +1,40 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type CreatePhotoVersionRepoRequest struct {
+	PhotoID          uuid.UUID
+	StorageKey       string
+	StorageVersionID string
+	SHA256           string
+	SizeBytes        int64
+	Format           string
+	// Quota is checked atomically with the insert; exceeding it returns ErrQuotaExceeded.
+	Quota StorageQuota
+	// Jobs are enqueued in the same transaction.
+	Jobs []NewJob
+}
+
+// SetCurrentVersionRepoRequest makes a version current, with its file now at StorageKey.
+type SetCurrentVersionRepoRequest struct {
+	PhotoID    uuid.UUID
+	Version    int
+	StorageKey string
+	// Jobs are enqueued in the same transaction.
+	Jobs []NewJob
+}
+
+type IPhotoVersionRepository interface {
+	// CreatePhotoVersion adds a photo's newest version, makes it current and counts it
+	// against the owner's storage in one transaction.
+	CreatePhotoVersion(ctx context.Context, req CreatePhotoVersionRepoRequest) (PhotoVersion, error)
+	GetPhotoVersion(ctx context.Context, photoID uuid.UUID, version int) (PhotoVersion, error)
+	ListPhotoVersions(ctx context.Context, photoID uuid.UUID) ([]PhotoVersion, error)
+	SetCurrentVersion(ctx context.Context, req SetCurrentVersionRepoRequest) error
+	// SetStorageVersionID records the object version of a file stored before it was known.
+	SetStorageVersionID(ctx context.Context, photoID uuid.UUID, version int, storageVersionID string) error
+}
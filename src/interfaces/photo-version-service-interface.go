@@ -0,0 +1,50 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PhotoVersionMetadata is the metadata extracted from the file of a version.
+type PhotoVersionMetadata struct {
+	Location    *PhotoLocation `json:"location,omitempty"`
+	Altitude    *float64       `json:"altitude,omitempty"`
+	CapturedAt  *time.Time     `json:"captured_at,omitempty"`
+	CameraMake  string         `json:"camera_make,omitempty"`
+	CameraModel string         `json:"camera_model,omitempty"`
+}
+
+// PhotoVersion is one file a photo has had. Metadata is nil until the file was processed,
+// or when it has none.
+type PhotoVersion struct {
+	Version   int                   `json:"version"`
+	Current   bool                  `json:"current"`
+	SizeBytes int64                 `json:"size_bytes"`
+	SHA256    string                `json:"sha256,omitempty"`
+	Format    string                `json:"format,omitempty"`
+	Metadata  *PhotoVersionMetadata `json:"metadata"`
+	CreatedAt time.Time             `json:"created_at"`
+	// StorageKey is where the file is stored; StorageVersionID picks the object version
+	// when the storage keeps versions.
+	StorageKey       string `json:"-"`
+	StorageVersionID string `json:"-"`
+}
+
+type ReplacePhotoFileRequest struct {
+	UserID   uuid.UUID
+	PhotoID  uuid.UUID
+	FileName string
+	FileData []byte
+}
+
+type IPhotoVersionService interface {
+	// ReplacePhotoFile stores a new file for a photo as its newest version and makes it
+	// current. Uploading the current file again changes nothing.
+	ReplacePhotoFile(ctx context.Context, request ReplacePhotoFileRequest) (PhotoVersion, error)
+	// ListVersions lists the versions of one of the user's photos, newest first.
+	ListVersions(ctx context.Context, userID uuid.UUID, photoID uuid.UUID) ([]PhotoVersion, error)
+	// RollbackPhoto makes an earlier version of one of the user's photos current again.
+	RollbackPhoto(ctx context.Context, userID uuid.UUID, photoID uuid.UUID, version int) (PhotoVersion, error)
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PrivateZoneKindCircle  = "circle"
+	PrivateZoneKindPolygon = "polygon"
+)
+
+type SavePrivateZoneRepoRequest struct {
+	ID             uuid.UUID
+	OwnerID        uuid.UUID
+	Name           string
+	Kind           string
+	Center         *PhotoLocation
+	RadiusMeters   *float64
+	PolygonGeoJSON string
+}
+
+type IPrivateZoneRepository interface {
+	CreatePrivateZone(ctx context.Context, req SavePrivateZoneRepoRequest) (uuid.UUID, error)
+	GetPrivateZone(ctx context.Context, id uuid.UUID) (PrivateZone, error)
+	ListPrivateZones(ctx context.Context, ownerID uuid.UUID) ([]PrivateZone, error)
+	UpdatePrivateZone(ctx context.Context, req SavePrivateZoneRepoRequest) error
+	DeletePrivateZone(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) error
+}
@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PrivateZone struct {
+	ID           uuid.UUID       `json:"id"`
+	OwnerID      uuid.UUID       `json:"owner_id"`
+	Name         string          `json:"name"`
+	Kind         string          `json:"kind"`
+	Center       PhotoLocation   `json:"center"`
+	RadiusMeters *float64        `json:"radius_m,omitempty"`
+	Area         json.RawMessage `json:"area"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// SavePrivateZoneRequest describes either a circle (Center and RadiusMeters) or a polygon.
+type SavePrivateZoneRequest struct {
+	UserID       uuid.UUID
+	ZoneID       uuid.UUID
+	Name         string
+	Center       *PhotoLocation
+	RadiusMeters *float64
+	Polygon      []PhotoLocation
+}
+
+type IPrivateZoneService interface {
+	CreatePrivateZone(ctx context.Context, request SavePrivateZoneRequest) (PrivateZone, error)
+	GetPrivateZone(ctx context.Context, userID uuid.UUID, zoneID uuid.UUID) (PrivateZone, error)
+	ListPrivateZones(ctx context.Context, userID uuid.UUID) ([]PrivateZone, error)
+	UpdatePrivateZone(ctx context.Context, request SavePrivateZoneRequest) (PrivateZone, error)
+	DeletePrivateZone(ctx context.Context, userID uuid.UUID, zoneID uuid.UUID) error
+}
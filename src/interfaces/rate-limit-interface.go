@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit describes a token bucket: it holds up to Burst tokens and refills at Rate
+// tokens per second. Each request takes one token.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// IRateLimitStore keeps the token buckets of the rate limiter, in memory or in a store
+// shared by all instances of the service.
+type IRateLimitStore interface {
+	// Take removes a token from the bucket named key. When the bucket is empty it returns
+	// false and how long until the next token is available.
+	Take(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error)
+}
+
+// IInFlightLimiter bounds the resources held by requests being served. Acquire returns
+// false instead of waiting when n more would exceed the limit; Release gives n back.
+type IInFlightLimiter interface {
+	Acquire(n int64) bool
+	Release(n int64)
+}
@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ListRoutesRepoRequest struct {
+	OwnerID uuid.UUID
+	From    time.Time
+	To      time.Time
+	PerTrip bool
+}
+
+type RouteSegmentRecord struct {
+	Day                 time.Time
+	PhotoCount          int
+	StartedAt           time.Time
+	EndedAt             time.Time
+	LineGeoJSON         string
+	DistanceMeters      float64
+	ElevationGainMeters *float64
+}
+
+type RoutePlaceRecord struct {
+	Day   time.Time
+	Place RoutePlace
+}
+
+type IRouteRepository interface {
+	ListRouteSegments(ctx context.Context, req ListRoutesRepoRequest) ([]RouteSegmentRecord, error)
+	ListRoutePlaces(ctx context.Context, req ListRoutesRepoRequest, clusterRadiusMeters float64) ([]RoutePlaceRecord, error)
+}
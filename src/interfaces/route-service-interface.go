@@ -0,0 +1,53 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GetRoutesRequest struct {
+	UserID uuid.UUID
+	From   time.Time
+	To     time.Time
+	// PerTrip returns the whole range as one route instead of one per day.
+	PerTrip bool
+}
+
+type RoutePlace struct {
+	Location   PhotoLocation `json:"location"`
+	ArrivedAt  time.Time     `json:"arrived_at"`
+	LeftAt     time.Time     `json:"left_at"`
+	PhotoCount int           `json:"photo_count"`
+}
+
+type RouteProperties struct {
+	Day                 string       `json:"day"`
+	StartedAt           time.Time    `json:"started_at"`
+	EndedAt             time.Time    `json:"ended_at"`
+	PhotoCount          int          `json:"photo_count"`
+	DistanceMeters      float64      `json:"distance_m"`
+	ElevationGainMeters *float64     `json:"elevation_gain_m,omitempty"`
+	Places              []RoutePlace `json:"places"`
+}
+
+// RouteFeature is a GeoJSON Feature whose geometry is a LineString.
+type RouteFeature struct {
+	Type       string          `json:"type"`
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties RouteProperties `json:"properties"`
+}
+
+// RouteCollection is a GeoJSON FeatureCollection with totals over all routes.
+type RouteCollection struct {
+	Type                     string         `json:"type"`
+	Features                 []RouteFeature `json:"features"`
+	TotalDistanceMeters      float64        `json:"total_distance_m"`
+	TotalElevationGainMeters *float64       `json:"total_elevation_gain_m,omitempty"`
+}
+
+type IRouteService interface {
+	GetRoutes(ctx context.Context, request GetRoutesRequest) (RouteCollection, error)
+}
@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateShareLinkRepoRequest struct {
+	OwnerID       uuid.UUID
+	TokenHash     []byte
+	PhotoID       *uuid.UUID
+	AlbumID       *uuid.UUID
+	PasswordHash  string
+	AllowDownload bool
+	ExpiresAt     *time.Time
+}
+
+type IShareLinkRepository interface {
+	CreateShareLink(ctx context.Context, req CreateShareLinkRepoRequest) (ShareLink, error)
+	GetShareLink(ctx context.Context, id uuid.UUID) (ShareLink, error)
+	GetShareLinkByTokenHash(ctx context.Context, tokenHash []byte) (ShareLink, error)
+	ListShareLinks(ctx context.Context, ownerID uuid.UUID) ([]ShareLink, error)
+	RevokeShareLink(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,76 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareLink is a public link to one photo or album. The token itself is only returned
+// when the link is created.
+type ShareLink struct {
+	ID            uuid.UUID  `json:"id"`
+	OwnerID       uuid.UUID  `json:"owner_id"`
+	PhotoID       *uuid.UUID `json:"photo_id,omitempty"`
+	AlbumID       *uuid.UUID `json:"album_id,omitempty"`
+	HasPassword   bool       `json:"has_password"`
+	AllowDownload bool       `json:"allow_download"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PasswordHash  string     `json:"-"`
+}
+
+type CreatedShareLink struct {
+	ShareLink
+	Token string `json:"token"`
+}
+
+// CreateShareLinkRequest shares either a photo or an album.
+type CreateShareLinkRequest struct {
+	UserID        uuid.UUID
+	PhotoID       *uuid.UUID
+	AlbumID       *uuid.UUID
+	Password      string
+	AllowDownload bool
+	ExpiresAt     *time.Time
+}
+
+type OpenShareLinkRequest struct {
+	Token    string
+	Password string
+	Limit    int
+	Offset   int
+}
+
+// SharedPhoto is what anonymous visitors of a share link see of a photo. URL and
+// DownloadURL are presigned and expire after a few minutes.
+type SharedPhoto struct {
+	ID          uuid.UUID      `json:"id"`
+	Description string         `json:"description"`
+	Location    *PhotoLocation `json:"location,omitempty"`
+	CapturedAt  *time.Time     `json:"captured_at,omitempty"`
+	URL         string         `json:"url"`
+	DownloadURL string         `json:"download_url,omitempty"`
+}
+
+type SharedAlbum struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	PhotoCount  int           `json:"photo_count"`
+	Photos      []SharedPhoto `json:"photos"`
+}
+
+type SharedContent struct {
+	Photo     *SharedPhoto `json:"photo,omitempty"`
+	Album     *SharedAlbum `json:"album,omitempty"`
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+}
+
+type IShareLinkService interface {
+	CreateShareLink(ctx context.Context, request CreateShareLinkRequest) (CreatedShareLink, error)
+	ListShareLinks(ctx context.Context, userID uuid.UUID) ([]ShareLink, error)
+	RevokeShareLink(ctx context.Context, userID uuid.UUID, linkID uuid.UUID) error
+	OpenShareLink(ctx context.Context, request OpenShareLinkRequest) (SharedContent, error)
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type CreateSmartAlbumRepoRequest struct {
+	OwnerID     uuid.UUID
+	Title       string
+	Description string
+	Query       PhotoQuery
+}
+
+type UpdateSmartAlbumRepoRequest struct {
+	AlbumID        uuid.UUID
+	Title          *string
+	SetDescription bool
+	Description    *string
+	Query          *PhotoQuery
+}
+
+type ISmartAlbumRepository interface {
+	CreateSmartAlbum(ctx context.Context, req CreateSmartAlbumRepoRequest) (SmartAlbum, error)
+	GetSmartAlbum(ctx context.Context, id uuid.UUID) (SmartAlbum, error)
+	ListSmartAlbums(ctx context.Context, ownerID uuid.UUID) ([]SmartAlbum, error)
+	UpdateSmartAlbum(ctx context.Context, req UpdateSmartAlbumRepoRequest) error
+	DeleteSmartAlbum(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,54 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SmartAlbum is a saved photo query shown like an album; its photos are found again on every read.
+type SmartAlbum struct {
+	ID          uuid.UUID  `json:"id"`
+	OwnerID     uuid.UUID  `json:"owner_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Query       PhotoQuery `json:"query"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type CreateSmartAlbumRequest struct {
+	UserID      uuid.UUID
+	Title       string
+	Description string
+	Query       PhotoQuery
+}
+
+// UpdateSmartAlbumRequest changes the fields that are set; a nil Description with
+// SetDescription clears it.
+type UpdateSmartAlbumRequest struct {
+	UserID         uuid.UUID
+	AlbumID        uuid.UUID
+	Title          *string
+	SetDescription bool
+	Description    *string
+	Query          *PhotoQuery
+}
+
+type ListSmartAlbumPhotosRequest struct {
+	UserID  uuid.UUID
+	AlbumID uuid.UUID
+	Cursor  string
+	Limit   int
+}
+
+type ISmartAlbumService interface {
+	CreateSmartAlbum(ctx context.Context, request CreateSmartAlbumRequest) (SmartAlbum, error)
+	GetSmartAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) (SmartAlbum, error)
+	ListSmartAlbums(ctx context.Context, userID uuid.UUID) ([]SmartAlbum, error)
+	UpdateSmartAlbum(ctx context.Context, request UpdateSmartAlbumRequest) (SmartAlbum, error)
+	DeleteSmartAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) error
+	ListSmartAlbumPhotos(ctx context.Context, request ListSmartAlbumPhotosRequest) (PhotoQueryPage, error)
+	CountSmartAlbumPhotos(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) (int64, error)
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// StorageUsageRecord is an owner's stored usage. Tier is empty for the default tier.
+type StorageUsageRecord struct {
+	OwnerID       uuid.UUID
+	Tier          string
+	PhotoCount    int64
+	OriginalBytes int64
+	VersionBytes  int64
+}
+
+type IStorageUsageRepository interface {
+	// GetStorageUsage returns zero usage for owners that have not stored anything yet.
+	GetStorageUsage(ctx context.Context, ownerID uuid.UUID) (StorageUsageRecord, error)
+	SetStorageTier(ctx context.Context, ownerID uuid.UUID, tier string) error
+}
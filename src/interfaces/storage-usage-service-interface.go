@@ -0,0 +1,45 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// StorageTier is a named set of storage limits; a limit of 0 means unlimited.
+type StorageTier struct {
+	Name      string
+	MaxBytes  int64
+	MaxPhotos int64
+}
+
+// StorageQuota holds the limits an upload is checked against.
+type StorageQuota struct {
+	MaxBytes  int64
+	MaxPhotos int64
+}
+
+// StorageUsage reports what a user stores. Originals are the files photos were uploaded
+// with and versions the files that replaced them later, kept until the photo is purged;
+// both count towards the byte limit. Limits are null when the tier does not have them.
+type StorageUsage struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Tier          string    `json:"tier"`
+	PhotoCount    int64     `json:"photo_count"`
+	UsedBytes     int64     `json:"used_bytes"`
+	OriginalBytes int64     `json:"original_bytes"`
+	VersionBytes  int64     `json:"version_bytes"`
+	MaxBytes      *int64    `json:"max_bytes"`
+	MaxPhotos     *int64    `json:"max_photos"`
+}
+
+type IStorageQuotaService interface {
+	GetUsage(ctx context.Context, userID uuid.UUID) (StorageUsage, error)
+	SetTier(ctx context.Context, userID uuid.UUID, tier string) (StorageUsage, error)
+	// CheckUpload rejects an upload of sizeBytes that would exceed the owner's quota with
+	// ErrQuotaExceeded, and returns the quota to enforce when the photo is stored.
+	CheckUpload(ctx context.Context, ownerID uuid.UUID, sizeBytes int64) (StorageQuota, error)
+	// CheckVersionUpload is CheckUpload for a new file of an existing photo, which only
+	// adds bytes.
+	CheckVersionUpload(ctx context.Context, ownerID uuid.UUID, sizeBytes int64) (StorageQuota, error)
+}
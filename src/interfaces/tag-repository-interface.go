@@ -0,0 +1,37 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Sources recorded for each tag on a photo.
+const (
+	TagSourceManual = "manual"
+	TagSourceIPTC   = "iptc"
+	TagSourceXMP    = "xmp"
+)
+
+type AddPhotoTagsRepoRequest struct {
+	PhotoID uuid.UUID
+	OwnerID uuid.UUID
+	Names   []string
+	Source  string
+}
+
+type ListPhotosByTagsRepoRequest struct {
+	OwnerID  uuid.UUID
+	Names    []string
+	MatchAll bool
+	Limit    int
+	Offset   int
+}
+
+type ITagRepository interface {
+	AddPhotoTags(ctx context.Context, req AddPhotoTagsRepoRequest) error
+	RemovePhotoTags(ctx context.Context, photoID uuid.UUID, names []string) error
+	ListPhotoTags(ctx context.Context, photoID uuid.UUID) ([]string, error)
+	SearchTags(ctx context.Context, ownerID uuid.UUID, prefix string, limit int) ([]TagUsage, error)
+	ListPhotosByTags(ctx context.Context, req ListPhotosByTagsRepoRequest) ([]Photo, error)
+}
@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type TagUsage struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	UsageCount int       `json:"usage_count"`
+}
+
+type PhotoTagsRequest struct {
+	UserID  uuid.UUID
+	PhotoID uuid.UUID
+	Tags    []string
+}
+
+type SearchTagsRequest struct {
+	UserID uuid.UUID
+	Prefix string
+	Limit  int
+}
+
+type ITagService interface {
+	AddPhotoTags(ctx context.Context, request PhotoTagsRequest) ([]string, error)
+	RemovePhotoTags(ctx context.Context, request PhotoTagsRequest) ([]string, error)
+	SearchTags(ctx context.Context, request SearchTagsRequest) ([]TagUsage, error)
+}
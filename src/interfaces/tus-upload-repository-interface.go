@@ -0,0 +1,61 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateTusUploadRepoRequest struct {
+	ID          uuid.UUID
+	OwnerID     uuid.UUID
+	Length      int64
+	Metadata    string
+	FileName    string
+	Description string
+	StorageKey  string
+	S3UploadID  string
+}
+
+// TusUpload is a resumable upload. Offset bytes have been received; PartsBytes of them
+// are stored as multipart upload parts and the rest in a staging object.
+type TusUpload struct {
+	ID          uuid.UUID
+	OwnerID     uuid.UUID
+	Length      int64
+	Offset      int64
+	PartsBytes  int64
+	Metadata    string
+	FileName    string
+	Description string
+	StorageKey  string
+	S3UploadID  string
+	AssembledAt *time.Time
+	PhotoID     *uuid.UUID
+}
+
+type TusUploadPart struct {
+	PartNumber int32
+	ETag       string
+	SizeBytes  int64
+}
+
+type ITusUploadRepository interface {
+	CreateTusUpload(ctx context.Context, req CreateTusUploadRepoRequest) (TusUpload, error)
+	GetTusUpload(ctx context.Context, id uuid.UUID) (TusUpload, error)
+	// ClaimTusUpload locks the upload for a write at offset; progress updates renew the lock.
+	// It returns ErrConflict when another request holds the lock or the offset has moved on.
+	ClaimTusUpload(ctx context.Context, id uuid.UUID, offset int64) (TusUpload, error)
+	ReleaseTusUpload(ctx context.Context, id uuid.UUID) error
+	// AddTusUploadPart records a stored part and the progress it brings in one transaction.
+	AddTusUploadPart(ctx context.Context, id uuid.UUID, part TusUploadPart, offset int64, partsBytes int64) error
+	UpdateTusUploadProgress(ctx context.Context, id uuid.UUID, offset int64, partsBytes int64) error
+	ListTusUploadParts(ctx context.Context, id uuid.UUID) ([]TusUploadPart, error)
+	MarkTusUploadAssembled(ctx context.Context, id uuid.UUID) error
+	SetTusUploadPhoto(ctx context.Context, id uuid.UUID, photoID uuid.UUID) error
+	DeleteTusUpload(ctx context.Context, id uuid.UUID) error
+	// ListStaleTusUploads returns uploads nobody has written to since updatedBefore, except
+	// those whose assembled file a photo uses without the upload knowing.
+	ListStaleTusUploads(ctx context.Context, updatedBefore time.Time, limit int) ([]TusUpload, error)
+}
@@ -0,0 +1,41 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// CreateTusUploadRequest starts a resumable upload of Length bytes. Metadata is the raw
+// Upload-Metadata header, kept to be returned as sent.
+type CreateTusUploadRequest struct {
+	UserID      uuid.UUID
+	Length      int64
+	FileName    string
+	Description string
+	Metadata    string
+}
+
+// WriteTusUploadRequest appends Body to an upload, which must have received exactly
+// Offset bytes so far.
+type WriteTusUploadRequest struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+	Offset int64
+	Body   io.Reader
+}
+
+type ITusUploadService interface {
+	// MaxSize is the largest upload accepted, in bytes.
+	MaxSize() int64
+	CreateUpload(ctx context.Context, request CreateTusUploadRequest) (TusUpload, error)
+	GetUpload(ctx context.Context, userID uuid.UUID, id uuid.UUID) (TusUpload, error)
+	// WriteUpload stores the bytes of the request. When the upload is complete the photo is
+	// created from it; writing no bytes to a complete upload retries that step.
+	WriteUpload(ctx context.Context, request WriteTusUploadRequest) (TusUpload, error)
+	TerminateUpload(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+	// CleanupExpired deletes uploads nobody has written to for a while, with the data stored
+	// for them, and returns how many were deleted.
+	CleanupExpired(ctx context.Context) (int, error)
+}
@@ -0,0 +1,46 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateUploadIntentRepoRequest struct {
+	ID          uuid.UUID
+	OwnerID     uuid.UUID
+	StorageKey  string
+	FileName    string
+	Description string
+	ContentType string
+	SizeBytes   int64
+	ExpiresAt   time.Time
+}
+
+// UploadIntent is a direct-to-bucket upload. Its ID is also the ID of the photo created
+// when it is completed.
+type UploadIntent struct {
+	ID          uuid.UUID  `json:"id"`
+	OwnerID     uuid.UUID  `json:"owner_id"`
+	StorageKey  string     `json:"-"`
+	FileName    string     `json:"file_name"`
+	Description string     `json:"description,omitempty"`
+	ContentType string     `json:"content_type"`
+	SizeBytes   int64      `json:"size_bytes"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type IUploadIntentRepository interface {
+	CreateUploadIntent(ctx context.Context, req CreateUploadIntentRepoRequest) (UploadIntent, error)
+	GetUploadIntent(ctx context.Context, id uuid.UUID) (UploadIntent, error)
+	// ClaimUploadIntent locks a pending intent while it is completed. It returns ErrConflict
+	// when another request holds the lock or the intent is already completed.
+	ClaimUploadIntent(ctx context.Context, id uuid.UUID) (UploadIntent, error)
+	ReleaseUploadIntent(ctx context.Context, id uuid.UUID) error
+	MarkUploadIntentCompleted(ctx context.Context, id uuid.UUID) error
+	// ListExpiredUploadIntents returns up to limit pending intents past their expiry.
+	ListExpiredUploadIntents(ctx context.Context, limit int) ([]UploadIntent, error)
+	DeleteUploadIntent(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,32 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CreateUploadIntentRequest reserves a photo for a file of SizeBytes bytes and the given
+// content type, to be uploaded straight to storage.
+type CreateUploadIntentRequest struct {
+	UserID      uuid.UUID
+	FileName    string
+	Description string
+	ContentType string
+	SizeBytes   int64
+}
+
+// UploadIntentTicket is a new intent with the presigned request to upload its file with.
+type UploadIntentTicket struct {
+	Intent UploadIntent
+	Upload PresignedUpload
+}
+
+type IUploadIntentService interface {
+	CreateIntent(ctx context.Context, request CreateUploadIntentRequest) (UploadIntentTicket, error)
+	// CompleteIntent creates the photo from the uploaded file and returns its ID. Completing
+	// an intent again returns the same photo.
+	CompleteIntent(ctx context.Context, userID uuid.UUID, id uuid.UUID) (string, error)
+	// CleanupExpired deletes expired intents and their files, returning how many it deleted.
+	CleanupExpired(ctx context.Context) (int, error)
+}
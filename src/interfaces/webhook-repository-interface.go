@@ -0,0 +1,64 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type CreateWebhookRepoRequest struct {
+	OwnerID  uuid.UUID
+	APIKeyID *uuid.UUID
+	URL      string
+	Secret   string
+	Events   []string
+}
+
+// UpdateWebhookRepoRequest changes the fields that are set.
+type UpdateWebhookRepoRequest struct {
+	ID      uuid.UUID
+	URL     *string
+	Events  []string
+	Enabled *bool
+}
+
+type CreateWebhookDeliveryRepoRequest struct {
+	ID         uuid.UUID
+	EndpointID uuid.UUID
+	EventID    uuid.UUID
+	EventType  string
+	Payload    json.RawMessage
+	// Job sends the delivery; it is enqueued in the same transaction.
+	Job NewJob
+}
+
+// WebhookAttempt is the outcome of one attempt at a delivery.
+type WebhookAttempt struct {
+	Status         string
+	ResponseStatus int
+	ResponseBody   string
+	Error          string
+}
+
+type IWebhookRepository interface {
+	CreateWebhook(ctx context.Context, req CreateWebhookRepoRequest) (Webhook, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (Webhook, error)
+	// ListWebhooks lists the owner's webhooks, or only those of apiKeyID when it is set.
+	ListWebhooks(ctx context.Context, ownerID uuid.UUID, apiKeyID *uuid.UUID) ([]Webhook, error)
+	// ListWebhooksForEvent lists the enabled webhooks subscribed to an event of the owner.
+	ListWebhooksForEvent(ctx context.Context, ownerID uuid.UUID, eventType string) ([]Webhook, error)
+	UpdateWebhook(ctx context.Context, req UpdateWebhookRepoRequest) error
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	ResetWebhookFailures(ctx context.Context, id uuid.UUID) error
+	// RecordWebhookFailure counts a failed delivery and disables the webhook once
+	// disableAfter deliveries in a row failed. It reports whether the webhook is still
+	// enabled.
+	RecordWebhookFailure(ctx context.Context, id uuid.UUID, disableAfter int) (bool, error)
+
+	CreateWebhookDelivery(ctx context.Context, req CreateWebhookDeliveryRepoRequest) (WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, id uuid.UUID) (WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, id uuid.UUID, attempt WebhookAttempt) error
+	SetWebhookDeliveryStatus(ctx context.Context, id uuid.UUID, status string, lastError string) error
+}
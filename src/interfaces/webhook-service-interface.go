@@ -0,0 +1,99 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook delivery statuses. A delivery is pending while it is being retried.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// JobKindDeliverWebhook sends a webhook delivery; the payload is a DeliverWebhookPayload.
+const JobKindDeliverWebhook = "webhook.deliver"
+
+type DeliverWebhookPayload struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+// Webhook is an endpoint that receives signed POSTs for the events it subscribes to.
+type Webhook struct {
+	ID                  uuid.UUID  `json:"id"`
+	OwnerID             uuid.UUID  `json:"owner_id"`
+	APIKeyID            *uuid.UUID `json:"api_key_id,omitempty"`
+	URL                 string     `json:"url"`
+	Events              []string   `json:"events"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	Secret              string     `json:"-"`
+}
+
+// CreatedWebhook is returned once when a webhook is created; its secret is not shown again.
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookDelivery is an event sent to a webhook, with the outcome of its last attempt.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhook_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookOwner is who manages webhooks: a user, or one of its API keys, which only sees
+// the webhooks created with it.
+type WebhookOwner struct {
+	UserID   uuid.UUID
+	APIKeyID *uuid.UUID
+}
+
+type CreateWebhookRequest struct {
+	Owner  WebhookOwner
+	URL    string
+	Events []string
+}
+
+// UpdateWebhookRequest changes the fields that are set. Enabling a webhook clears its
+// failures.
+type UpdateWebhookRequest struct {
+	Owner   WebhookOwner
+	ID      uuid.UUID
+	URL     *string
+	Events  []string
+	Enabled *bool
+}
+
+type IWebhookService interface {
+	IEventPublisher
+	CreateWebhook(ctx context.Context, request CreateWebhookRequest) (CreatedWebhook, error)
+	ListWebhooks(ctx context.Context, owner WebhookOwner) ([]Webhook, error)
+	GetWebhook(ctx context.Context, owner WebhookOwner, id uuid.UUID) (Webhook, error)
+	UpdateWebhook(ctx context.Context, request UpdateWebhookRequest) (Webhook, error)
+	DeleteWebhook(ctx context.Context, owner WebhookOwner, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, owner WebhookOwner, webhookID uuid.UUID, limit int) ([]WebhookDelivery, error)
+	// Redeliver sends a delivery's event to its webhook again, as a new delivery.
+	Redeliver(ctx context.Context, owner WebhookOwner, webhookID uuid.UUID, deliveryID uuid.UUID) (WebhookDelivery, error)
+	// Deliver makes one attempt at a delivery; errors are retried.
+	Deliver(ctx context.Context, deliveryID uuid.UUID) error
+	// FailDelivery gives up on a delivery, counting it against its webhook.
+	FailDelivery(ctx context.Context, deliveryID uuid.UUID, cause error) error
+}
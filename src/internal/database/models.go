@@ -6,22 +6,241 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID         uuid.UUID
+	Name       string
+	KeyPrefix  string
+	KeyHash    []byte
+	Scopes     []string
+	OwnerID    uuid.NullUUID
+	CreatedBy  uuid.NullUUID
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+	CreatedAt  time.Time
+	OnBehalfOf bool
+}
+
+type Album struct {
+	ID           uuid.UUID
+	OwnerID      uuid.UUID
+	Title        string
+	Description  sql.NullString
+	CoverPhotoID uuid.NullUUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type AlbumMember struct {
+	AlbumID   uuid.UUID
+	UserID    uuid.UUID
+	Role      string
+	AddedBy   uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type AlbumPhoto struct {
+	AlbumID  uuid.UUID
+	PhotoID  uuid.UUID
+	Position int32
+	AddedAt  time.Time
+}
+
+type IdempotencyKey struct {
+	OwnerID             uuid.UUID
+	Scope               string
+	Key                 string
+	Fingerprint         string
+	ResponseStatus      sql.NullInt32
+	ResponseContentType sql.NullString
+	ResponseBody        []byte
+	LockedUntil         sql.NullTime
+	CompletedAt         sql.NullTime
+	CreatedAt           time.Time
+	ResponseLocation    sql.NullString
+}
+
+type Job struct {
+	ID          int64
+	Kind        string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	RunAt       time.Time
+	LockedUntil sql.NullTime
+	LastError   sql.NullString
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
 type Photo struct {
+	ID             uuid.UUID
+	OwnerID        uuid.UUID
+	Description    sql.NullString
+	PhotoUrl       string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	SearchLanguage string
+	SearchVector   interface{}
+	Format         sql.NullString
+	SizeBytes      int64
+	Status         string
+	DeletedAt      sql.NullTime
+	CurrentVersion int32
+}
+
+type PhotoMetadatum struct {
+	ID              uuid.UUID
+	Location        interface{}
+	CreatedAt       sql.NullTime
+	LocationSource  sql.NullString
+	CreatedAtSource sql.NullString
+	Altitude        sql.NullFloat64
+	CameraMake      sql.NullString
+	CameraModel     sql.NullString
+}
+
+type PhotoTag struct {
+	PhotoID   uuid.UUID
+	TagID     uuid.UUID
+	Source    string
+	CreatedAt time.Time
+}
+
+type PhotoVersion struct {
+	PhotoID          uuid.UUID
+	Version          int32
+	StorageKey       string
+	StorageVersionID sql.NullString
+	Sha256           sql.NullString
+	SizeBytes        int64
+	Format           sql.NullString
+	Metadata         json.RawMessage
+	CreatedAt        time.Time
+}
+
+type PrivateZone struct {
+	ID        uuid.UUID
+	OwnerID   uuid.UUID
+	Name      string
+	Kind      string
+	Area      interface{}
+	RadiusM   sql.NullFloat64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ShareLink struct {
+	ID            uuid.UUID
+	OwnerID       uuid.UUID
+	TokenHash     []byte
+	PhotoID       uuid.NullUUID
+	AlbumID       uuid.NullUUID
+	PasswordHash  sql.NullString
+	AllowDownload bool
+	ExpiresAt     sql.NullTime
+	RevokedAt     sql.NullTime
+	CreatedAt     time.Time
+}
+
+type SmartAlbum struct {
 	ID          uuid.UUID
 	OwnerID     uuid.UUID
+	Title       string
 	Description sql.NullString
-	PhotoUrl    string
+	Query       json.RawMessage
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
-type PhotoMetadatum struct {
+type StorageUsage struct {
+	OwnerID       uuid.UUID
+	Tier          sql.NullString
+	PhotoCount    int64
+	OriginalBytes int64
+	VersionBytes  int64
+	UpdatedAt     time.Time
+}
+
+type Tag struct {
 	ID        uuid.UUID
-	Location  interface{}
-	CreatedAt sql.NullTime
+	OwnerID   uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
+type TusUpload struct {
+	ID           uuid.UUID
+	OwnerID      uuid.UUID
+	UploadLength int64
+	UploadOffset int64
+	PartsBytes   int64
+	Metadata     string
+	FileName     string
+	Description  sql.NullString
+	StorageKey   string
+	S3UploadID   string
+	AssembledAt  sql.NullTime
+	PhotoID      uuid.NullUUID
+	LockedUntil  sql.NullTime
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type TusUploadPart struct {
+	UploadID   uuid.UUID
+	PartNumber int32
+	Etag       string
+	SizeBytes  int64
+}
+
+type UploadIntent struct {
+	ID          uuid.UUID
+	OwnerID     uuid.UUID
+	StorageKey  string
+	FileName    string
+	Description sql.NullString
+	ContentType string
+	SizeBytes   int64
+	ExpiresAt   time.Time
+	CompletedAt sql.NullTime
+	LockedUntil sql.NullTime
+	CreatedAt   time.Time
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	EndpointID     uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	ResponseStatus sql.NullInt32
+	ResponseBody   sql.NullString
+	LastError      sql.NullString
+	LastAttemptAt  sql.NullTime
+	CreatedAt      time.Time
+}
+
+type WebhookEndpoint struct {
+	ID                  uuid.UUID
+	OwnerID             uuid.UUID
+	ApiKeyID            uuid.NullUUID
+	Url                 string
+	Secret              string
+	Events              []string
+	Enabled             bool
+	ConsecutiveFailures int32
+	DisabledAt          sql.NullTime
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
 }
@@ -8,31 +8,312 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createPhotoMetadata = `-- name: CreatePhotoMetadata :one
-INSERT INTO photo_metadata (id, location, created_at)
-VALUES ($1, ST_MakePoint($2::double precision, $3::double precision), $4)
-RETURNING id, location, created_at
+INSERT INTO photo_metadata AS pm (id, location, location_source, altitude, created_at, created_at_source, camera_make, camera_model)
+VALUES (
+    $1,
+    ST_SetSRID(ST_MakePoint($2::double precision, $3::double precision), 4326)::geography,
+    CASE WHEN $2::double precision IS NOT NULL THEN $4::varchar END,
+    $5::double precision,
+    $6::timestamp,
+    CASE WHEN $6::timestamp IS NOT NULL THEN $4::varchar END,
+    $7,
+    $8
+)
+ON CONFLICT (id) DO UPDATE SET
+    location = CASE WHEN pm.location_source = 'manual' OR EXCLUDED.location IS NULL THEN pm.location ELSE EXCLUDED.location END,
+    location_source = CASE WHEN pm.location_source = 'manual' OR EXCLUDED.location IS NULL THEN pm.location_source ELSE EXCLUDED.location_source END,
+    altitude = CASE WHEN pm.location_source = 'manual' OR EXCLUDED.location IS NULL THEN pm.altitude ELSE EXCLUDED.altitude END,
+    created_at = CASE WHEN pm.created_at_source = 'manual' OR EXCLUDED.created_at IS NULL THEN pm.created_at ELSE EXCLUDED.created_at END,
+    created_at_source = CASE WHEN pm.created_at_source = 'manual' OR EXCLUDED.created_at IS NULL THEN pm.created_at_source ELSE EXCLUDED.created_at_source END,
+    camera_make = COALESCE(EXCLUDED.camera_make, pm.camera_make),
+    camera_model = COALESCE(EXCLUDED.camera_model, pm.camera_model)
+RETURNING id, location, created_at, location_source, created_at_source, altitude, camera_make, camera_model
 `
 
 type CreatePhotoMetadataParams struct {
-	ID        uuid.UUID
-	Column2   float64
-	Column3   float64
-	CreatedAt sql.NullTime
+	ID          uuid.UUID
+	Longitude   sql.NullFloat64
+	Latitude    sql.NullFloat64
+	Source      string
+	Altitude    sql.NullFloat64
+	CreatedAt   sql.NullTime
+	CameraMake  sql.NullString
+	CameraModel sql.NullString
 }
 
+// Re-extraction keeps manual edits and never replaces a known value with NULL.
 func (q *Queries) CreatePhotoMetadata(ctx context.Context, arg CreatePhotoMetadataParams) (PhotoMetadatum, error) {
 	row := q.db.QueryRowContext(ctx, createPhotoMetadata,
 		arg.ID,
-		arg.Column2,
-		arg.Column3,
+		arg.Longitude,
+		arg.Latitude,
+		arg.Source,
+		arg.Altitude,
 		arg.CreatedAt,
+		arg.CameraMake,
+		arg.CameraModel,
 	)
 	var i PhotoMetadatum
-	err := row.Scan(&i.ID, &i.Location, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Location,
+		&i.CreatedAt,
+		&i.LocationSource,
+		&i.CreatedAtSource,
+		&i.Altitude,
+		&i.CameraMake,
+		&i.CameraModel,
+	)
 	return i, err
 }
+
+const getPhotoMetadata = `-- name: GetPhotoMetadata :one
+SELECT
+    pm.id,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.location_source,
+    pm.created_at,
+    pm.created_at_source
+FROM photo_metadata pm
+WHERE pm.id = $1
+`
+
+type GetPhotoMetadataRow struct {
+	ID              uuid.UUID
+	HasLocation     bool
+	Latitude        float64
+	Longitude       float64
+	LocationSource  sql.NullString
+	CreatedAt       sql.NullTime
+	CreatedAtSource sql.NullString
+}
+
+func (q *Queries) GetPhotoMetadata(ctx context.Context, id uuid.UUID) (GetPhotoMetadataRow, error) {
+	row := q.db.QueryRowContext(ctx, getPhotoMetadata, id)
+	var i GetPhotoMetadataRow
+	err := row.Scan(
+		&i.ID,
+		&i.HasLocation,
+		&i.Latitude,
+		&i.Longitude,
+		&i.LocationSource,
+		&i.CreatedAt,
+		&i.CreatedAtSource,
+	)
+	return i, err
+}
+
+const listNearbyPhotos = `-- name: ListNearbyPhotos :many
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    ST_Y(pm.location::geometry)::double precision AS latitude,
+    ST_X(pm.location::geometry)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    ST_Distance(pm.location, origin.location)::double precision AS distance_m,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private
+FROM photo_metadata origin
+JOIN photo_metadata pm ON pm.id <> origin.id
+JOIN photo p ON p.id = pm.id
+WHERE origin.id = $1
+  AND p.owner_id = $2
+  AND p.deleted_at IS NULL
+  AND pm.location IS NOT NULL
+  AND ST_DWithin(pm.location, origin.location, $3::double precision)
+  AND (
+    $4::double precision IS NULL
+    OR pm.created_at BETWEEN origin.created_at - make_interval(secs => $4::double precision)
+                         AND origin.created_at + make_interval(secs => $4::double precision)
+  )
+ORDER BY distance_m, p.id
+LIMIT $5
+`
+
+type ListNearbyPhotosParams struct {
+	PhotoID           uuid.UUID
+	ViewerID          uuid.UUID
+	RadiusM           float64
+	TimeWindowSeconds sql.NullFloat64
+	MaxResults        int32
+}
+
+type ListNearbyPhotosRow struct {
+	ID              uuid.UUID
+	OwnerID         uuid.UUID
+	Description     sql.NullString
+	PhotoUrl        string
+	CreatedAt       time.Time
+	Latitude        float64
+	Longitude       float64
+	CapturedAt      sql.NullTime
+	DistanceM       float64
+	LocationPrivate bool
+}
+
+func (q *Queries) ListNearbyPhotos(ctx context.Context, arg ListNearbyPhotosParams) ([]ListNearbyPhotosRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNearbyPhotos,
+		arg.PhotoID,
+		arg.ViewerID,
+		arg.RadiusM,
+		arg.TimeWindowSeconds,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNearbyPhotosRow
+	for rows.Next() {
+		var i ListNearbyPhotosRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Description,
+			&i.PhotoUrl,
+			&i.CreatedAt,
+			&i.Latitude,
+			&i.Longitude,
+			&i.CapturedAt,
+			&i.DistanceM,
+			&i.LocationPrivate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPhotosWithoutLocation = `-- name: ListPhotosWithoutLocation :many
+SELECT p.id, pm.created_at AS captured_at
+FROM photo p
+JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.owner_id = $1
+  AND p.deleted_at IS NULL
+  AND pm.location IS NULL
+  AND pm.location_source IS DISTINCT FROM 'manual'
+  AND pm.created_at IS NOT NULL
+ORDER BY pm.created_at, p.id
+`
+
+type ListPhotosWithoutLocationRow struct {
+	ID         uuid.UUID
+	CapturedAt sql.NullTime
+}
+
+func (q *Queries) ListPhotosWithoutLocation(ctx context.Context, ownerID uuid.UUID) ([]ListPhotosWithoutLocationRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPhotosWithoutLocation, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPhotosWithoutLocationRow
+	for rows.Next() {
+		var i ListPhotosWithoutLocationRow
+		if err := rows.Scan(&i.ID, &i.CapturedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPhotoLocations = `-- name: SetPhotoLocations :execrows
+UPDATE photo_metadata pm
+SET location = ST_SetSRID(ST_MakePoint(u.longitude, u.latitude), 4326)::geography,
+    location_source = 'gpx'
+FROM unnest(
+    $1::uuid[],
+    $2::double precision[],
+    $3::double precision[]
+) AS u(id, longitude, latitude)
+JOIN photo p ON p.id = u.id
+WHERE pm.id = u.id
+  AND p.owner_id = $4
+  AND p.deleted_at IS NULL
+  AND pm.location IS NULL
+  AND pm.location_source IS DISTINCT FROM 'manual'
+`
+
+type SetPhotoLocationsParams struct {
+	Ids        []uuid.UUID
+	Longitudes []float64
+	Latitudes  []float64
+	OwnerID    uuid.UUID
+}
+
+func (q *Queries) SetPhotoLocations(ctx context.Context, arg SetPhotoLocationsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setPhotoLocations,
+		pq.Array(arg.Ids),
+		pq.Array(arg.Longitudes),
+		pq.Array(arg.Latitudes),
+		arg.OwnerID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updatePhotoMetadataManually = `-- name: UpdatePhotoMetadataManually :exec
+INSERT INTO photo_metadata AS pm (id, location, location_source, created_at, created_at_source)
+VALUES (
+    $1,
+    ST_SetSRID(ST_MakePoint($2::double precision, $3::double precision), 4326)::geography,
+    CASE WHEN $4::boolean THEN 'manual' END,
+    $5::timestamp,
+    CASE WHEN $6::boolean THEN 'manual' END
+)
+ON CONFLICT (id) DO UPDATE SET
+    location = CASE WHEN $4::boolean THEN EXCLUDED.location ELSE pm.location END,
+    location_source = CASE WHEN $4::boolean THEN EXCLUDED.location_source ELSE pm.location_source END,
+    altitude = CASE WHEN $4::boolean THEN NULL ELSE pm.altitude END,
+    created_at = CASE WHEN $6::boolean THEN EXCLUDED.created_at ELSE pm.created_at END,
+    created_at_source = CASE WHEN $6::boolean THEN EXCLUDED.created_at_source ELSE pm.created_at_source END
+`
+
+type UpdatePhotoMetadataManuallyParams struct {
+	ID           uuid.UUID
+	Longitude    sql.NullFloat64
+	Latitude     sql.NullFloat64
+	SetLocation  bool
+	CreatedAt    sql.NullTime
+	SetCreatedAt bool
+}
+
+// Only the fields flagged with set_* are touched; they are marked as manual edits.
+func (q *Queries) UpdatePhotoMetadataManually(ctx context.Context, arg UpdatePhotoMetadataManuallyParams) error {
+	_, err := q.db.ExecContext(ctx, updatePhotoMetadataManually,
+		arg.ID,
+		arg.Longitude,
+		arg.Latitude,
+		arg.SetLocation,
+		arg.CreatedAt,
+		arg.SetCreatedAt,
+	)
+	return err
+}
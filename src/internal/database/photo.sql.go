@@ -8,24 +8,40 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createPhoto = `-- name: CreatePhoto :one
-INSERT INTO photo (owner_id, description, photo_url)
-VALUES ($1, $2, $3)
-RETURNING id, owner_id, description, photo_url, created_at, updated_at
+INSERT INTO photo (id, owner_id, description, photo_url, search_language, format, size_bytes, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, owner_id, description, photo_url, created_at, updated_at, search_language, search_vector, format, size_bytes, status, deleted_at, current_version
 `
 
 type CreatePhotoParams struct {
-	OwnerID     uuid.UUID
-	Description sql.NullString
-	PhotoUrl    string
+	ID             uuid.UUID
+	OwnerID        uuid.UUID
+	Description    sql.NullString
+	PhotoUrl       string
+	SearchLanguage string
+	Format         sql.NullString
+	SizeBytes      int64
+	Status         string
 }
 
 func (q *Queries) CreatePhoto(ctx context.Context, arg CreatePhotoParams) (Photo, error) {
-	row := q.db.QueryRowContext(ctx, createPhoto, arg.OwnerID, arg.Description, arg.PhotoUrl)
+	row := q.db.QueryRowContext(ctx, createPhoto,
+		arg.ID,
+		arg.OwnerID,
+		arg.Description,
+		arg.PhotoUrl,
+		arg.SearchLanguage,
+		arg.Format,
+		arg.SizeBytes,
+		arg.Status,
+	)
 	var i Photo
 	err := row.Scan(
 		&i.ID,
@@ -34,6 +50,486 @@ func (q *Queries) CreatePhoto(ctx context.Context, arg CreatePhotoParams) (Photo
 		&i.PhotoUrl,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SearchLanguage,
+		&i.SearchVector,
+		&i.Format,
+		&i.SizeBytes,
+		&i.Status,
+		&i.DeletedAt,
+		&i.CurrentVersion,
+	)
+	return i, err
+}
+
+const getPhotoDetail = `-- name: GetPhotoDetail :one
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.status,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private
+FROM photo p
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.id = $1
+  AND p.deleted_at IS NULL
+`
+
+type GetPhotoDetailRow struct {
+	ID              uuid.UUID
+	OwnerID         uuid.UUID
+	Description     sql.NullString
+	PhotoUrl        string
+	CreatedAt       time.Time
+	Status          string
+	HasLocation     bool
+	Latitude        float64
+	Longitude       float64
+	CapturedAt      sql.NullTime
+	LocationPrivate bool
+}
+
+func (q *Queries) GetPhotoDetail(ctx context.Context, id uuid.UUID) (GetPhotoDetailRow, error) {
+	row := q.db.QueryRowContext(ctx, getPhotoDetail, id)
+	var i GetPhotoDetailRow
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Description,
+		&i.PhotoUrl,
+		&i.CreatedAt,
+		&i.Status,
+		&i.HasLocation,
+		&i.Latitude,
+		&i.Longitude,
+		&i.CapturedAt,
+		&i.LocationPrivate,
+	)
+	return i, err
+}
+
+const getPhotoWithLocation = `-- name: GetPhotoWithLocation :one
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.current_version,
+    v.sha256 AS current_sha256,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    pm.created_at AS captured_at
+FROM photo p
+LEFT JOIN photo_version v ON v.photo_id = p.id AND v.version = p.current_version
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.id = $1
+  AND p.deleted_at IS NULL
+`
+
+type GetPhotoWithLocationRow struct {
+	ID             uuid.UUID
+	OwnerID        uuid.UUID
+	Description    sql.NullString
+	PhotoUrl       string
+	CreatedAt      time.Time
+	CurrentVersion int32
+	CurrentSha256  sql.NullString
+	HasLocation    bool
+	CapturedAt     sql.NullTime
+}
+
+func (q *Queries) GetPhotoWithLocation(ctx context.Context, id uuid.UUID) (GetPhotoWithLocationRow, error) {
+	row := q.db.QueryRowContext(ctx, getPhotoWithLocation, id)
+	var i GetPhotoWithLocationRow
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Description,
+		&i.PhotoUrl,
+		&i.CreatedAt,
+		&i.CurrentVersion,
+		&i.CurrentSha256,
+		&i.HasLocation,
+		&i.CapturedAt,
 	)
 	return i, err
 }
+
+const listPhotos = `-- name: ListPhotos :many
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.status,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private
+FROM photo p
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.owner_id = $1
+  AND p.deleted_at IS NULL
+ORDER BY p.created_at DESC, p.id
+LIMIT $2 OFFSET $3
+`
+
+type ListPhotosParams struct {
+	OwnerID uuid.UUID
+	Limit   int32
+	Offset  int32
+}
+
+type ListPhotosRow struct {
+	ID              uuid.UUID
+	OwnerID         uuid.UUID
+	Description     sql.NullString
+	PhotoUrl        string
+	CreatedAt       time.Time
+	Status          string
+	HasLocation     bool
+	Latitude        float64
+	Longitude       float64
+	CapturedAt      sql.NullTime
+	LocationPrivate bool
+}
+
+func (q *Queries) ListPhotos(ctx context.Context, arg ListPhotosParams) ([]ListPhotosRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPhotos, arg.OwnerID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPhotosRow
+	for rows.Next() {
+		var i ListPhotosRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Description,
+			&i.PhotoUrl,
+			&i.CreatedAt,
+			&i.Status,
+			&i.HasLocation,
+			&i.Latitude,
+			&i.Longitude,
+			&i.CapturedAt,
+			&i.LocationPrivate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedPhotos = `-- name: ListTrashedPhotos :many
+SELECT
+    p.id,
+    p.description,
+    p.photo_url,
+    p.status,
+    p.size_bytes,
+    p.created_at,
+    p.deleted_at::timestamp AS deleted_at
+FROM photo p
+WHERE p.owner_id = $1
+  AND p.deleted_at IS NOT NULL
+ORDER BY p.deleted_at DESC, p.id
+LIMIT $2 OFFSET $3
+`
+
+type ListTrashedPhotosParams struct {
+	OwnerID uuid.UUID
+	Limit   int32
+	Offset  int32
+}
+
+type ListTrashedPhotosRow struct {
+	ID          uuid.UUID
+	Description sql.NullString
+	PhotoUrl    string
+	Status      string
+	SizeBytes   int64
+	CreatedAt   time.Time
+	DeletedAt   time.Time
+}
+
+func (q *Queries) ListTrashedPhotos(ctx context.Context, arg ListTrashedPhotosParams) ([]ListTrashedPhotosRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedPhotos, arg.OwnerID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTrashedPhotosRow
+	for rows.Next() {
+		var i ListTrashedPhotosRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.PhotoUrl,
+			&i.Status,
+			&i.SizeBytes,
+			&i.CreatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgePhotos = `-- name: PurgePhotos :many
+WITH purged AS (
+    DELETE FROM photo
+    WHERE id IN (
+        SELECT p.id
+        FROM photo p
+        WHERE p.deleted_at IS NOT NULL
+          AND ($1::uuid IS NULL OR p.owner_id = $1)
+          AND ($2::timestamp IS NULL OR p.deleted_at < $2)
+        ORDER BY p.deleted_at
+        LIMIT $3
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, owner_id, photo_url, size_bytes
+)
+SELECT
+    purged.id,
+    purged.owner_id,
+    purged.photo_url,
+    COALESCE(
+        (SELECT sum(v.size_bytes) FROM photo_version v WHERE v.photo_id = purged.id),
+        purged.size_bytes
+    )::bigint AS stored_bytes,
+    COALESCE(
+        (SELECT sum(v.size_bytes) FROM photo_version v WHERE v.photo_id = purged.id AND v.version > 1),
+        0
+    )::bigint AS version_bytes,
+    ARRAY(
+        SELECT DISTINCT v.storage_key FROM photo_version v WHERE v.photo_id = purged.id
+    )::text[] AS storage_keys
+FROM purged
+`
+
+type PurgePhotosParams struct {
+	OwnerID       uuid.NullUUID
+	DeletedBefore sql.NullTime
+	MaxPhotos     int32
+}
+
+type PurgePhotosRow struct {
+	ID           uuid.UUID
+	OwnerID      uuid.UUID
+	PhotoUrl     string
+	StoredBytes  int64
+	VersionBytes int64
+	StorageKeys  []string
+}
+
+// Deletes up to max_photos trashed photos, of one owner when owner_id is set and only
+// those deleted before deleted_before when that is set. Photos another purge is deleting
+// are skipped. The statement's snapshot still shows the versions of the deleted photos,
+// which give the bytes they stored and their files.
+func (q *Queries) PurgePhotos(ctx context.Context, arg PurgePhotosParams) ([]PurgePhotosRow, error) {
+	rows, err := q.db.QueryContext(ctx, purgePhotos, arg.OwnerID, arg.DeletedBefore, arg.MaxPhotos)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PurgePhotosRow
+	for rows.Next() {
+		var i PurgePhotosRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.PhotoUrl,
+			&i.StoredBytes,
+			&i.VersionBytes,
+			pq.Array(&i.StorageKeys),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restorePhoto = `-- name: RestorePhoto :one
+UPDATE photo
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND owner_id = $2
+  AND deleted_at IS NOT NULL
+RETURNING status
+`
+
+type RestorePhotoParams struct {
+	ID      uuid.UUID
+	OwnerID uuid.UUID
+}
+
+func (q *Queries) RestorePhoto(ctx context.Context, arg RestorePhotoParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, restorePhoto, arg.ID, arg.OwnerID)
+	var status string
+	err := row.Scan(&status)
+	return status, err
+}
+
+const searchPhotos = `-- name: SearchPhotos :many
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.status,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private,
+    ts_rank(p.search_vector, q.query)::double precision AS rank,
+    ts_headline(
+        $1::regconfig,
+        escape_html(coalesce(p.description, '')),
+        q.query,
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5'
+    )::text AS headline
+FROM photo p
+CROSS JOIN to_tsquery($1::regconfig, $2::text) AS q(query)
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.owner_id = $3
+  AND p.deleted_at IS NULL
+  AND p.search_vector @@ q.query
+ORDER BY rank DESC, p.created_at DESC, p.id
+LIMIT $4 OFFSET $5
+`
+
+type SearchPhotosParams struct {
+	SearchLanguage string
+	Query          string
+	OwnerID        uuid.UUID
+	MaxResults     int32
+	Skip           int32
+}
+
+type SearchPhotosRow struct {
+	ID              uuid.UUID
+	OwnerID         uuid.UUID
+	Description     sql.NullString
+	PhotoUrl        string
+	CreatedAt       time.Time
+	Status          string
+	HasLocation     bool
+	Latitude        float64
+	Longitude       float64
+	CapturedAt      sql.NullTime
+	LocationPrivate bool
+	Rank            float64
+	Headline        string
+}
+
+// Full-text search over the owner's photo descriptions, best matches first.
+func (q *Queries) SearchPhotos(ctx context.Context, arg SearchPhotosParams) ([]SearchPhotosRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPhotos,
+		arg.SearchLanguage,
+		arg.Query,
+		arg.OwnerID,
+		arg.MaxResults,
+		arg.Skip,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPhotosRow
+	for rows.Next() {
+		var i SearchPhotosRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Description,
+			&i.PhotoUrl,
+			&i.CreatedAt,
+			&i.Status,
+			&i.HasLocation,
+			&i.Latitude,
+			&i.Longitude,
+			&i.CapturedAt,
+			&i.LocationPrivate,
+			&i.Rank,
+			&i.Headline,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPhotoStatus = `-- name: SetPhotoStatus :exec
+UPDATE photo
+SET status = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type SetPhotoStatusParams struct {
+	ID     uuid.UUID
+	Status string
+}
+
+func (q *Queries) SetPhotoStatus(ctx context.Context, arg SetPhotoStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setPhotoStatus, arg.ID, arg.Status)
+	return err
+}
+
+const trashPhoto = `-- name: TrashPhoto :execrows
+UPDATE photo
+SET deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) TrashPhoto(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, trashPhoto, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
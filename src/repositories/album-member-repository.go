@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type AlbumMemberRepo struct {
+	db *database.Queries
+}
+
+func NewAlbumMemberRepo(db *database.Queries) *AlbumMemberRepo {
+	return &AlbumMemberRepo{db: db}
+}
+
+// SetAlbumMember adds a member to an album, or changes the role of an existing one.
+func (r *AlbumMemberRepo) SetAlbumMember(ctx context.Context, request interfaces.SetAlbumMemberRepoRequest) (interfaces.AlbumMember, error) {
+	member, err := r.db.UpsertAlbumMember(ctx, database.UpsertAlbumMemberParams{
+		AlbumID: request.AlbumID,
+		UserID:  request.UserID,
+		Role:    string(request.Role),
+		AddedBy: request.AddedBy,
+	})
+	if err != nil {
+		log.Printf("Error setting album member: %v", err)
+		return interfaces.AlbumMember{}, err
+	}
+	return toAlbumMember(member), nil
+}
+
+func (r *AlbumMemberRepo) GetAlbumMember(ctx context.Context, albumID uuid.UUID, userID uuid.UUID) (interfaces.AlbumMember, error) {
+	member, err := r.db.GetAlbumMember(ctx, database.GetAlbumMemberParams{AlbumID: albumID, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.AlbumMember{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting album member: %v", err)
+		return interfaces.AlbumMember{}, err
+	}
+	return toAlbumMember(member), nil
+}
+
+// ListAlbumMembers returns the album's members in the order they were added.
+func (r *AlbumMemberRepo) ListAlbumMembers(ctx context.Context, albumID uuid.UUID) ([]interfaces.AlbumMember, error) {
+	rows, err := r.db.ListAlbumMembers(ctx, albumID)
+	if err != nil {
+		log.Printf("Error listing album members: %v", err)
+		return nil, err
+	}
+	members := make([]interfaces.AlbumMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, toAlbumMember(row))
+	}
+	return members, nil
+}
+
+func (r *AlbumMemberRepo) RemoveAlbumMember(ctx context.Context, albumID uuid.UUID, userID uuid.UUID) error {
+	deleted, err := r.db.DeleteAlbumMember(ctx, database.DeleteAlbumMemberParams{AlbumID: albumID, UserID: userID})
+	if err != nil {
+		log.Printf("Error removing album member: %v", err)
+		return err
+	}
+	if deleted == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+func (r *AlbumMemberRepo) IsPhotoShared(ctx context.Context, photoID uuid.UUID, userID uuid.UUID) (bool, error) {
+	shared, err := r.db.IsPhotoSharedWithUser(ctx, database.IsPhotoSharedWithUserParams{UserID: userID, PhotoID: photoID})
+	if err != nil {
+		log.Printf("Error checking photo access: %v", err)
+		return false, err
+	}
+	return shared, nil
+}
+
+func toAlbumMember(row database.AlbumMember) interfaces.AlbumMember {
+	return interfaces.AlbumMember{
+		AlbumID:   row.AlbumID,
+		UserID:    row.UserID,
+		Role:      interfaces.AlbumRole(row.Role),
+		AddedBy:   row.AddedBy,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
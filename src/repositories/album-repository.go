@@ -0,0 +1,277 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type AlbumRepo struct {
+	conn *sql.DB
+	db   *database.Queries
+}
+
+func NewAlbumRepo(conn *sql.DB, db *database.Queries) *AlbumRepo {
+	return &AlbumRepo{conn: conn, db: db}
+}
+
+// CreateAlbum creates an empty album.
+func (r *AlbumRepo) CreateAlbum(ctx context.Context, request interfaces.CreateAlbumRepoRequest) (interfaces.Album, error) {
+	album, err := r.db.CreateAlbum(ctx, database.CreateAlbumParams{
+		OwnerID:     request.OwnerID,
+		Title:       request.Title,
+		Description: sql.NullString{String: request.Description, Valid: request.Description != ""},
+	})
+	if err != nil {
+		log.Printf("Error creating album: %v", err)
+		return interfaces.Album{}, err
+	}
+	return toAlbum(database.GetAlbumRow{
+		ID:           album.ID,
+		OwnerID:      album.OwnerID,
+		Title:        album.Title,
+		Description:  album.Description,
+		CoverPhotoID: album.CoverPhotoID,
+		CreatedAt:    album.CreatedAt,
+		UpdatedAt:    album.UpdatedAt,
+	}), nil
+}
+
+// GetAlbum loads an album with its photo count.
+func (r *AlbumRepo) GetAlbum(ctx context.Context, id uuid.UUID) (interfaces.Album, error) {
+	album, err := r.db.GetAlbum(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.Album{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting album: %v", err)
+		return interfaces.Album{}, err
+	}
+	return toAlbum(album), nil
+}
+
+// ListAlbums returns the albums the user owns or is a member of, newest first.
+func (r *AlbumRepo) ListAlbums(ctx context.Context, userID uuid.UUID) ([]interfaces.Album, error) {
+	rows, err := r.db.ListAlbums(ctx, userID)
+	if err != nil {
+		log.Printf("Error listing albums: %v", err)
+		return nil, err
+	}
+	albums := make([]interfaces.Album, 0, len(rows))
+	for _, row := range rows {
+		album := toAlbum(database.GetAlbumRow{
+			ID:           row.ID,
+			OwnerID:      row.OwnerID,
+			Title:        row.Title,
+			Description:  row.Description,
+			CoverPhotoID: row.CoverPhotoID,
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+			PhotoCount:   row.PhotoCount,
+		})
+		album.Role = interfaces.AlbumRole(row.Role)
+		albums = append(albums, album)
+	}
+	return albums, nil
+}
+
+// UpdateAlbum changes the title and/or description of an album.
+func (r *AlbumRepo) UpdateAlbum(ctx context.Context, request interfaces.UpdateAlbumRepoRequest) error {
+	params := database.UpdateAlbumParams{ID: request.AlbumID, SetDescription: request.SetDescription}
+	if request.Title != nil {
+		params.Title = sql.NullString{String: *request.Title, Valid: true}
+	}
+	if request.Description != nil {
+		params.Description = sql.NullString{String: *request.Description, Valid: *request.Description != ""}
+	}
+	updated, err := r.db.UpdateAlbum(ctx, params)
+	if err != nil {
+		log.Printf("Error updating album: %v", err)
+		return err
+	}
+	if updated == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAlbum removes an album; its photos are kept.
+func (r *AlbumRepo) DeleteAlbum(ctx context.Context, id uuid.UUID) error {
+	deleted, err := r.db.DeleteAlbum(ctx, id)
+	if err != nil {
+		log.Printf("Error deleting album: %v", err)
+		return err
+	}
+	if deleted == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+// AddPhotos appends photos owned by ownerID to the end of the album and returns how many were added.
+func (r *AlbumRepo) AddPhotos(ctx context.Context, albumID uuid.UUID, ownerID uuid.UUID, photoIDs []uuid.UUID) (int64, error) {
+	var added int64
+	err := r.withTx(ctx, func(q *database.Queries) error {
+		// Lock the album so concurrent additions cannot take the same positions.
+		if _, err := q.GetAlbumForUpdate(ctx, albumID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return interfaces.ErrNotFound
+			}
+			return err
+		}
+		var err error
+		added, err = q.AddAlbumPhotos(ctx, database.AddAlbumPhotosParams{
+			AlbumID:  albumID,
+			PhotoIds: photoIDs,
+			OwnerID:  ownerID,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// RemovePhoto takes a photo out of an album, clearing the cover if it was that photo.
+func (r *AlbumRepo) RemovePhoto(ctx context.Context, albumID uuid.UUID, photoID uuid.UUID) error {
+	return r.withTx(ctx, func(q *database.Queries) error {
+		removed, err := q.RemoveAlbumPhoto(ctx, database.RemoveAlbumPhotoParams{AlbumID: albumID, PhotoID: photoID})
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return interfaces.ErrNotFound
+		}
+		return q.ClearAlbumCover(ctx, database.ClearAlbumCoverParams{
+			ID:           albumID,
+			CoverPhotoID: uuid.NullUUID{UUID: photoID, Valid: true},
+		})
+	})
+}
+
+// ReorderPhotos sets the album order. photoIDs must list every photo in the album exactly once.
+func (r *AlbumRepo) ReorderPhotos(ctx context.Context, albumID uuid.UUID, photoIDs []uuid.UUID) error {
+	return r.withTx(ctx, func(q *database.Queries) error {
+		// Lock the album so concurrent additions cannot slip in between the check and the update.
+		if _, err := q.GetAlbumForUpdate(ctx, albumID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return interfaces.ErrNotFound
+			}
+			return err
+		}
+		current, err := q.ListAlbumPhotoIDs(ctx, albumID)
+		if err != nil {
+			return err
+		}
+		if !sameIDs(current, photoIDs) {
+			return fmt.Errorf("%w: photo_ids must list every photo in the album exactly once", interfaces.ErrInvalidArgument)
+		}
+		_, err = q.ReorderAlbumPhotos(ctx, database.ReorderAlbumPhotosParams{PhotoIds: photoIDs, AlbumID: albumID})
+		return err
+	})
+}
+
+// SetCover makes a photo of the album its cover.
+func (r *AlbumRepo) SetCover(ctx context.Context, albumID uuid.UUID, photoID uuid.UUID) error {
+	updated, err := r.db.SetAlbumCover(ctx, database.SetAlbumCoverParams{PhotoID: photoID, AlbumID: albumID})
+	if err != nil {
+		log.Printf("Error setting album cover: %v", err)
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("%w: the cover must be a photo in the album", interfaces.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// ListAlbumPhotos returns a page of the album's photos in album order.
+func (r *AlbumRepo) ListAlbumPhotos(ctx context.Context, albumID uuid.UUID, limit int, offset int) ([]interfaces.AlbumPhoto, error) {
+	rows, err := r.db.ListAlbumPhotos(ctx, database.ListAlbumPhotosParams{
+		AlbumID: albumID,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	})
+	if err != nil {
+		log.Printf("Error listing album photos: %v", err)
+		return nil, err
+	}
+	photos := make([]interfaces.AlbumPhoto, 0, len(rows))
+	for _, row := range rows {
+		photo := interfaces.AlbumPhoto{
+			Photo: interfaces.Photo{
+				ID:              row.ID,
+				OwnerID:         row.OwnerID,
+				Description:     row.Description.String,
+				URL:             row.PhotoUrl,
+				CapturedAt:      nullTimePtr(row.CapturedAt),
+				CreatedAt:       row.CreatedAt,
+				LocationPrivate: row.LocationPrivate,
+			},
+			Position: int(row.Position),
+		}
+		if row.HasLocation {
+			photo.Location = &interfaces.PhotoLocation{Latitude: row.Latitude, Longitude: row.Longitude}
+		}
+		photos = append(photos, photo)
+	}
+	return photos, nil
+}
+
+// withTx runs fn inside a transaction, rolling back when it returns an error.
+func (r *AlbumRepo) withTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(r.db.WithTx(tx)); err != nil {
+		if !errors.Is(err, interfaces.ErrNotFound) && !errors.Is(err, interfaces.ErrInvalidArgument) {
+			log.Printf("Error in album transaction: %v", err)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func toAlbum(row database.GetAlbumRow) interfaces.Album {
+	album := interfaces.Album{
+		ID:          row.ID,
+		OwnerID:     row.OwnerID,
+		Title:       row.Title,
+		Description: row.Description.String,
+		PhotoCount:  int(row.PhotoCount),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	if row.CoverPhotoID.Valid {
+		album.CoverPhotoID = &row.CoverPhotoID.UUID
+	}
+	return album
+}
+
+// sameIDs reports whether both lists hold the same IDs, each exactly once.
+func sameIDs(current []uuid.UUID, requested []uuid.UUID) bool {
+	if len(current) != len(requested) {
+		return false
+	}
+	remaining := make(map[uuid.UUID]bool, len(current))
+	for _, id := range current {
+		remaining[id] = true
+	}
+	for _, id := range requested {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyRepo struct {
+	db *database.Queries
+}
+
+func NewAPIKeyRepo(db *database.Queries) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, request interfaces.CreateAPIKeyRepoRequest) (interfaces.APIKey, error) {
+	scopes := make([]string, len(request.Scopes))
+	for i, scope := range request.Scopes {
+		scopes[i] = string(scope)
+	}
+	key, err := r.db.CreateAPIKey(ctx, database.CreateAPIKeyParams{
+		Name:       request.Name,
+		KeyPrefix:  request.KeyPrefix,
+		KeyHash:    request.KeyHash,
+		Scopes:     scopes,
+		OwnerID:    toNullUUID(request.OwnerID),
+		CreatedBy:  toNullUUID(request.CreatedBy),
+		ExpiresAt:  toNullTime(request.ExpiresAt),
+		OnBehalfOf: request.OnBehalfOf,
+	})
+	if err != nil {
+		log.Printf("Error creating API key: %v", err)
+		return interfaces.APIKey{}, err
+	}
+	return toAPIKey(key), nil
+}
+
+func (r *APIKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (interfaces.APIKey, error) {
+	key, err := r.db.GetAPIKeyByHash(ctx, keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.APIKey{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting API key: %v", err)
+		return interfaces.APIKey{}, err
+	}
+	return toAPIKey(key), nil
+}
+
+// ListAPIKeys returns every key, including revoked and expired ones, newest first.
+func (r *APIKeyRepo) ListAPIKeys(ctx context.Context) ([]interfaces.APIKey, error) {
+	rows, err := r.db.ListAPIKeys(ctx)
+	if err != nil {
+		log.Printf("Error listing API keys: %v", err)
+		return nil, err
+	}
+	keys := make([]interfaces.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, toAPIKey(row))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key as revoked; revoking it again keeps the first revocation time.
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	revoked, err := r.db.RevokeAPIKey(ctx, id)
+	if err != nil {
+		log.Printf("Error revoking API key: %v", err)
+		return err
+	}
+	if revoked == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.TouchAPIKey(ctx, id); err != nil {
+		log.Printf("Error recording API key use: %v", err)
+		return err
+	}
+	return nil
+}
+
+func toAPIKey(row database.ApiKey) interfaces.APIKey {
+	scopes := make([]interfaces.Scope, len(row.Scopes))
+	for i, scope := range row.Scopes {
+		scopes[i] = interfaces.Scope(scope)
+	}
+	return interfaces.APIKey{
+		ID:         row.ID,
+		Name:       row.Name,
+		Prefix:     row.KeyPrefix,
+		Scopes:     scopes,
+		OwnerID:    nullUUIDPtr(row.OwnerID),
+		OnBehalfOf: row.OnBehalfOf,
+		CreatedBy:  nullUUIDPtr(row.CreatedBy),
+		ExpiresAt:  nullTimePtr(row.ExpiresAt),
+		LastUsedAt: nullTimePtr(row.LastUsedAt),
+		RevokedAt:  nullTimePtr(row.RevokedAt),
+		CreatedAt:  row.CreatedAt,
+		KeyHash:    row.KeyHash,
+	}
+}
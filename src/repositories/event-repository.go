@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// eventChannel is the channel NotifyEvent sends on.
+	eventChannel = "events"
+	// eventListenerPing is how often an idle listener checks its connection.
+	eventListenerPing = 90 * time.Second
+)
+
+// notifiedEvent is an event as sent through the events channel.
+type notifiedEvent struct {
+	Seq   int64 `json:"seq"`
+	Event struct {
+		ID        uuid.UUID       `json:"id"`
+		Type      string          `json:"type"`
+		OwnerID   uuid.UUID       `json:"owner_id"`
+		CreatedAt time.Time       `json:"created_at"`
+		Data      json.RawMessage `json:"data"`
+	} `json:"event"`
+}
+
+// EventRepo passes events between instances with Postgres LISTEN/NOTIFY.
+type EventRepo struct {
+	conn  *sql.DB
+	db    *database.Queries
+	dbURL string
+}
+
+// NewEventRepo listens on its own connection to dbURL.
+func NewEventRepo(conn *sql.DB, db *database.Queries, dbURL string) *EventRepo {
+	return &EventRepo{conn: conn, db: db, dbURL: dbURL}
+}
+
+func (r *EventRepo) NotifyEvent(ctx context.Context, event interfaces.Event) (int64, error) {
+	var payload notifiedEvent
+	payload.Event.ID = event.ID
+	payload.Event.Type = event.Type
+	payload.Event.OwnerID = event.OwnerID
+	payload.Event.CreatedAt = event.CreatedAt
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return 0, err
+	}
+	payload.Event.Data = data
+	encoded, err := json.Marshal(payload.Event)
+	if err != nil {
+		return 0, err
+	}
+
+	// Numbers are taken when the statement runs but notifications only go out at commit;
+	// the lock keeps a later number from arriving before an earlier one.
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+	if err := q.LockEventSeq(ctx); err != nil {
+		log.Printf("Error locking event sequence: %v", err)
+		return 0, err
+	}
+	seq, err := q.NotifyEvent(ctx, encoded)
+	if err != nil {
+		log.Printf("Error notifying event: %v", err)
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing event: %v", err)
+		return 0, err
+	}
+	return seq, nil
+}
+
+func (r *EventRepo) ListenEvents(ctx context.Context, handle func(interfaces.SequencedEvent), synced func(seq int64)) error {
+	listener := pq.NewListener(r.dbURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Event listener connection: %v", err)
+		}
+	})
+	defer listener.Close()
+	if err := listener.Listen(eventChannel); err != nil {
+		log.Printf("Error listening for events: %v", err)
+		return err
+	}
+
+	// When the latest number cannot be read, the first event received stands in for it.
+	needSync := !r.sync(ctx, synced)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			// The listener reconnected; notifications sent meanwhile are lost.
+			if notification == nil {
+				needSync = !r.sync(ctx, synced)
+				continue
+			}
+			var payload notifiedEvent
+			if err := json.Unmarshal([]byte(notification.Extra), &payload); err != nil {
+				log.Printf("Error decoding event: %v", err)
+				continue
+			}
+			if needSync {
+				synced(payload.Seq - 1)
+				needSync = false
+			}
+			handle(interfaces.SequencedEvent{
+				Seq: payload.Seq,
+				Event: interfaces.Event{
+					ID:        payload.Event.ID,
+					Type:      payload.Event.Type,
+					OwnerID:   payload.Event.OwnerID,
+					CreatedAt: payload.Event.CreatedAt,
+					Data:      payload.Event.Data,
+				},
+			})
+		case <-time.After(eventListenerPing):
+			go listener.Ping()
+		}
+	}
+}
+
+func (r *EventRepo) sync(ctx context.Context, synced func(seq int64)) bool {
+	seq, err := r.db.CurrentEventSeq(ctx)
+	if err != nil {
+		log.Printf("Error reading latest event number: %v", err)
+		return false
+	}
+	synced(seq)
+	return true
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+)
+
+// idempotencyKeyLease is how long a request holds its key. A retry of a request that
+// stopped without finishing can take the key over after that.
+const idempotencyKeyLease = 5 * time.Minute
+
+type IdempotencyKeyRepo struct {
+	db *database.Queries
+}
+
+func NewIdempotencyKeyRepo(db *database.Queries) *IdempotencyKeyRepo {
+	return &IdempotencyKeyRepo{db: db}
+}
+
+func (r *IdempotencyKeyRepo) ClaimIdempotencyKey(ctx context.Context, id interfaces.IdempotencyKeyID, fingerprint string, expiredBefore time.Time) (bool, error) {
+	_, err := r.db.ClaimIdempotencyKey(ctx, database.ClaimIdempotencyKeyParams{
+		OwnerID:       id.OwnerID,
+		Scope:         id.Scope,
+		Key:           id.Key,
+		Fingerprint:   fingerprint,
+		LeaseSeconds:  idempotencyKeyLease.Seconds(),
+		ExpiredBefore: expiredBefore,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("Error claiming idempotency key: %v", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *IdempotencyKeyRepo) GetIdempotencyKey(ctx context.Context, id interfaces.IdempotencyKeyID) (interfaces.IdempotencyRecord, error) {
+	row, err := r.db.GetIdempotencyKey(ctx, database.GetIdempotencyKeyParams{
+		OwnerID: id.OwnerID,
+		Scope:   id.Scope,
+		Key:     id.Key,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.IdempotencyRecord{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting idempotency key: %v", err)
+		return interfaces.IdempotencyRecord{}, err
+	}
+	record := interfaces.IdempotencyRecord{ID: id, Fingerprint: row.Fingerprint}
+	if row.CompletedAt.Valid {
+		record.Response = &interfaces.StoredResponse{
+			Status:      int(row.ResponseStatus.Int32),
+			ContentType: row.ResponseContentType.String,
+			Location:    row.ResponseLocation.String,
+			Body:        row.ResponseBody,
+		}
+	}
+	return record, nil
+}
+
+func (r *IdempotencyKeyRepo) CompleteIdempotencyKey(ctx context.Context, id interfaces.IdempotencyKeyID, response interfaces.StoredResponse) error {
+	err := r.db.CompleteIdempotencyKey(ctx, database.CompleteIdempotencyKeyParams{
+		ResponseStatus:      sql.NullInt32{Int32: int32(response.Status), Valid: true},
+		ResponseContentType: toNullString(response.ContentType),
+		ResponseBody:        response.Body,
+		ResponseLocation:    toNullString(response.Location),
+		OwnerID:             id.OwnerID,
+		Scope:               id.Scope,
+		Key:                 id.Key,
+	})
+	if err != nil {
+		log.Printf("Error completing idempotency key: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *IdempotencyKeyRepo) ReleaseIdempotencyKey(ctx context.Context, id interfaces.IdempotencyKeyID) error {
+	err := r.db.ReleaseIdempotencyKey(ctx, database.ReleaseIdempotencyKeyParams{
+		OwnerID: id.OwnerID,
+		Scope:   id.Scope,
+		Key:     id.Key,
+	})
+	if err != nil {
+		log.Printf("Error releasing idempotency key: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *IdempotencyKeyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, createdBefore time.Time) (int64, error) {
+	deleted, err := r.db.DeleteExpiredIdempotencyKeys(ctx, createdBefore)
+	if err != nil {
+		log.Printf("Error deleting expired idempotency keys: %v", err)
+		return 0, err
+	}
+	return deleted, nil
+}
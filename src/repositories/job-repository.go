@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+)
+
+// jobLease is how long a worker holds a claimed job. A job still running after that is
+// taken to have lost its worker and is claimed again.
+const jobLease = 5 * time.Minute
+
+type JobRepo struct {
+	db *database.Queries
+}
+
+func NewJobRepo(db *database.Queries) *JobRepo {
+	return &JobRepo{db: db}
+}
+
+func (r *JobRepo) EnqueueJob(ctx context.Context, job interfaces.NewJob) (int64, error) {
+	return enqueueJob(ctx, r.db, job)
+}
+
+func (r *JobRepo) ClaimJobs(ctx context.Context, limit int) ([]interfaces.Job, error) {
+	rows, err := r.db.ClaimJobs(ctx, database.ClaimJobsParams{
+		LeaseSeconds: jobLease.Seconds(),
+		MaxJobs:      int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error claiming jobs: %v", err)
+		return nil, err
+	}
+	jobs := make([]interfaces.Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, interfaces.Job{
+			ID:          row.ID,
+			Kind:        row.Kind,
+			Payload:     row.Payload,
+			Attempts:    int(row.Attempts),
+			MaxAttempts: int(row.MaxAttempts),
+		})
+	}
+	return jobs, nil
+}
+
+func (r *JobRepo) CompleteJob(ctx context.Context, id int64) error {
+	if err := r.db.CompleteJob(ctx, id); err != nil {
+		log.Printf("Error completing job: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *JobRepo) RetryJob(ctx context.Context, id int64, delay time.Duration, lastError string) error {
+	err := r.db.RetryJob(ctx, database.RetryJobParams{
+		DelaySeconds: delay.Seconds(),
+		LastError:    toNullString(lastError),
+		ID:           id,
+	})
+	if err != nil {
+		log.Printf("Error rescheduling job: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *JobRepo) BuryJob(ctx context.Context, id int64, lastError string) error {
+	err := r.db.BuryJob(ctx, database.BuryJobParams{ID: id, LastError: toNullString(lastError)})
+	if err != nil {
+		log.Printf("Error burying job: %v", err)
+		return err
+	}
+	return nil
+}
+
+// enqueueJob adds a job with q, which may be bound to a transaction.
+func enqueueJob(ctx context.Context, q *database.Queries, job interfaces.NewJob) (int64, error) {
+	payload := job.Payload
+	if payload == nil {
+		payload = []byte("{}")
+	}
+	id, err := q.EnqueueJob(ctx, database.EnqueueJobParams{
+		Kind:        job.Kind,
+		Payload:     payload,
+		MaxAttempts: int32(job.MaxAttempts),
+	})
+	if err != nil {
+		log.Printf("Error enqueueing job: %v", err)
+		return 0, err
+	}
+	return id, nil
+}
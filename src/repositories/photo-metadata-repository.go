@@ -3,9 +3,12 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"photo-service/src/interfaces"
 	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
 )
 
 type PhotoMetadataRepo struct {
@@ -17,15 +20,132 @@ func NewPhotoMetadataRepo(db *database.Queries) *PhotoMetadataRepo {
 }
 
 func (r *PhotoMetadataRepo) CreatePhotoMetadata(ctx context.Context, request interfaces.CreatePhotoMetadataRepoRequest) (string, error) {
-	metadata, err := r.db.CreatePhotoMetadata(ctx, database.CreatePhotoMetadataParams{
-		ID:        request.Id,
-		Column2:   *request.Latitude,
-		Column3:   *request.Longitude,
-		CreatedAt: sql.NullTime{Time: *request.CreatedAt, Valid: true},
-	})
+	params := database.CreatePhotoMetadataParams{
+		ID:          request.Id,
+		Source:      request.Source,
+		CreatedAt:   toNullTime(request.CreatedAt),
+		CameraMake:  toNullString(request.CameraMake),
+		CameraModel: toNullString(request.CameraModel),
+	}
+	// A point needs both coordinates; otherwise only the capture time is stored.
+	if request.Longitude != nil && request.Latitude != nil {
+		params.Longitude = sql.NullFloat64{Float64: *request.Longitude, Valid: true}
+		params.Latitude = sql.NullFloat64{Float64: *request.Latitude, Valid: true}
+		if request.Altitude != nil {
+			params.Altitude = sql.NullFloat64{Float64: *request.Altitude, Valid: true}
+		}
+	}
+	metadata, err := r.db.CreatePhotoMetadata(ctx, params)
 	if err != nil {
 		log.Printf("Error creating photo metadata: %v", err)
 		return "", err
 	}
 	return metadata.ID.String(), nil
 }
+
+// ListNearbyPhotos returns the viewer's geotagged photos within a radius of the given photo, closest first.
+func (r *PhotoMetadataRepo) ListNearbyPhotos(ctx context.Context, request interfaces.ListNearbyPhotosRepoRequest) ([]interfaces.NearbyPhoto, error) {
+	params := database.ListNearbyPhotosParams{
+		PhotoID:    request.PhotoID,
+		ViewerID:   request.ViewerID,
+		RadiusM:    request.RadiusMeters,
+		MaxResults: int32(request.Limit),
+	}
+	if request.TimeWindow != nil {
+		params.TimeWindowSeconds = sql.NullFloat64{Float64: request.TimeWindow.Seconds(), Valid: true}
+	}
+	rows, err := r.db.ListNearbyPhotos(ctx, params)
+	if err != nil {
+		log.Printf("Error listing nearby photos: %v", err)
+		return nil, err
+	}
+	photos := make([]interfaces.NearbyPhoto, 0, len(rows))
+	for _, row := range rows {
+		photos = append(photos, interfaces.NearbyPhoto{
+			Photo: interfaces.Photo{
+				ID:          row.ID,
+				OwnerID:     row.OwnerID,
+				Description: row.Description.String,
+				URL:         row.PhotoUrl,
+				Location:    &interfaces.PhotoLocation{Latitude: row.Latitude, Longitude: row.Longitude},
+				CapturedAt:  nullTimePtr(row.CapturedAt),
+				CreatedAt:   row.CreatedAt,
+
+				LocationPrivate: row.LocationPrivate,
+			},
+			DistanceMeters: row.DistanceM,
+		})
+	}
+	return photos, nil
+}
+
+// ListPhotosWithoutLocation returns the owner's photos that have a capture time but no location.
+func (r *PhotoMetadataRepo) ListPhotosWithoutLocation(ctx context.Context, ownerID uuid.UUID) ([]interfaces.PhotoCaptureTime, error) {
+	rows, err := r.db.ListPhotosWithoutLocation(ctx, ownerID)
+	if err != nil {
+		log.Printf("Error listing photos without location: %v", err)
+		return nil, err
+	}
+	photos := make([]interfaces.PhotoCaptureTime, 0, len(rows))
+	for _, row := range rows {
+		photos = append(photos, interfaces.PhotoCaptureTime{PhotoID: row.ID, CapturedAt: row.CapturedAt.Time})
+	}
+	return photos, nil
+}
+
+// SetPhotoLocations writes locations for the owner's photos that do not have one yet, in a single statement.
+func (r *PhotoMetadataRepo) SetPhotoLocations(ctx context.Context, request interfaces.SetPhotoLocationsRepoRequest) (int64, error) {
+	params := database.SetPhotoLocationsParams{OwnerID: request.OwnerID}
+	for id, location := range request.Locations {
+		params.Ids = append(params.Ids, id)
+		params.Longitudes = append(params.Longitudes, location.Longitude)
+		params.Latitudes = append(params.Latitudes, location.Latitude)
+	}
+	updated, err := r.db.SetPhotoLocations(ctx, params)
+	if err != nil {
+		log.Printf("Error setting photo locations: %v", err)
+		return 0, err
+	}
+	return updated, nil
+}
+
+// GetPhotoMetadata returns the stored location and capture time of a photo with their sources.
+func (r *PhotoMetadataRepo) GetPhotoMetadata(ctx context.Context, photoID uuid.UUID) (interfaces.PhotoMetadata, error) {
+	row, err := r.db.GetPhotoMetadata(ctx, photoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.PhotoMetadata{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting photo metadata: %v", err)
+		return interfaces.PhotoMetadata{}, err
+	}
+	metadata := interfaces.PhotoMetadata{
+		PhotoID:          row.ID,
+		LocationSource:   row.LocationSource.String,
+		CapturedAt:       nullTimePtr(row.CreatedAt),
+		CapturedAtSource: row.CreatedAtSource.String,
+	}
+	if row.HasLocation {
+		metadata.Location = &interfaces.PhotoLocation{Latitude: row.Latitude, Longitude: row.Longitude}
+	}
+	return metadata, nil
+}
+
+// UpdatePhotoMetadataManually applies a user's edit, marking the touched fields as manual.
+func (r *PhotoMetadataRepo) UpdatePhotoMetadataManually(ctx context.Context, request interfaces.UpdatePhotoMetadataRepoRequest) error {
+	params := database.UpdatePhotoMetadataManuallyParams{
+		ID:           request.PhotoID,
+		SetLocation:  request.SetLocation,
+		SetCreatedAt: request.SetCapturedAt,
+		CreatedAt:    toNullTime(request.CapturedAt),
+	}
+	if request.Location != nil {
+		params.Longitude = sql.NullFloat64{Float64: request.Location.Longitude, Valid: true}
+		params.Latitude = sql.NullFloat64{Float64: request.Location.Latitude, Valid: true}
+	}
+	if err := r.db.UpdatePhotoMetadataManually(ctx, params); err != nil {
+		log.Printf("Error updating photo metadata: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/lib/pq"
+)
+
+// photoQuerySorts maps each sort order to its key expression and direction. Captured-time
+// sorts only see photos with a capture time.
+var photoQuerySorts = map[string]struct {
+	key  string
+	desc bool
+}{
+	interfaces.PhotoSortUploadedDesc: {key: "p.created_at", desc: true},
+	interfaces.PhotoSortUploadedAsc:  {key: "p.created_at"},
+	interfaces.PhotoSortCapturedDesc: {key: "pm.created_at", desc: true},
+	interfaces.PhotoSortCapturedAsc:  {key: "pm.created_at"},
+	interfaces.PhotoSortRelevance:    {key: "rank", desc: true},
+}
+
+const (
+	photoQueryRank     = "ts_rank(p.search_vector, q.query)::double precision"
+	photoQueryHeadline = "ts_headline(%s::regconfig, escape_html(coalesce(p.description, '')), q.query, " +
+		"'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')"
+)
+
+// photoQueryBuilder collects SQL conditions; every request value is passed as a bind
+// parameter and only fixed SQL fragments end up in the query text.
+type photoQueryBuilder struct {
+	from       string
+	conditions []string
+	args       []interface{}
+	// language is the placeholder of the text-search configuration, set for text queries.
+	language string
+}
+
+// arg binds a value and returns its placeholder.
+func (b *photoQueryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// where adds a condition, formatting each value's placeholder into it.
+func (b *photoQueryBuilder) where(format string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = b.arg(value)
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(format, placeholders...))
+}
+
+// filterPhotos compiles the filters of a photo query, everything but sorting and paging.
+func (r *PhotoRepo) filterPhotos(request interfaces.PhotoQueryRepoRequest) *photoQueryBuilder {
+	b := &photoQueryBuilder{from: "photo p LEFT JOIN photo_metadata pm ON pm.id = p.id"}
+	owner := b.arg(request.OwnerID)
+	b.conditions = append(b.conditions, "p.owner_id = "+owner, "p.deleted_at IS NULL")
+
+	if request.TSQuery != "" {
+		b.language = b.arg(r.searchLanguage)
+		b.from += fmt.Sprintf(" CROSS JOIN to_tsquery(%s::regconfig, %s::text) AS q(query)", b.language, b.arg(request.TSQuery))
+		b.conditions = append(b.conditions, "p.search_vector @@ q.query")
+	}
+	if len(request.TagNames) > 0 {
+		names := b.arg(pq.Array(request.TagNames))
+		if request.TagMatchAll {
+			b.conditions = append(b.conditions, fmt.Sprintf(
+				"p.id IN (SELECT pt.photo_id FROM photo_tag pt JOIN tag t ON t.id = pt.tag_id"+
+					" WHERE t.owner_id = %s AND lower(t.name) = ANY(%s::text[]) GROUP BY pt.photo_id HAVING count(*) = %s)",
+				owner, names, b.arg(len(request.TagNames))))
+		} else {
+			b.conditions = append(b.conditions, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM photo_tag pt JOIN tag t ON t.id = pt.tag_id"+
+					" WHERE pt.photo_id = p.id AND t.owner_id = %s AND lower(t.name) = ANY(%s::text[]))",
+				owner, names))
+		}
+	}
+	if request.CapturedFrom != nil {
+		b.where("pm.created_at >= %s", *request.CapturedFrom)
+	}
+	if request.CapturedTo != nil {
+		b.where("pm.created_at < %s", *request.CapturedTo)
+	}
+	if request.UploadedFrom != nil {
+		b.where("p.created_at >= %s", *request.UploadedFrom)
+	}
+	if request.UploadedTo != nil {
+		b.where("p.created_at < %s", *request.UploadedTo)
+	}
+	if box := request.BBox; box != nil {
+		b.where("pm.location && ST_MakeEnvelope(%s, %s, %s, %s, 4326)::geography",
+			box.MinLongitude, box.MinLatitude, box.MaxLongitude, box.MaxLatitude)
+	}
+	if near := request.Near; near != nil {
+		b.where("ST_DWithin(pm.location, ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography, %s)",
+			near.Longitude, near.Latitude, near.RadiusMeters)
+	}
+	if request.CameraMake != "" {
+		b.where("lower(pm.camera_make) = lower(%s::text)", request.CameraMake)
+	}
+	if request.CameraModel != "" {
+		b.where("lower(pm.camera_model) = lower(%s::text)", request.CameraModel)
+	}
+	if len(request.Formats) > 0 {
+		b.where("p.format = ANY(%s::text[])", pq.Array(request.Formats))
+	}
+	if request.HasLocation != nil {
+		if *request.HasLocation {
+			b.conditions = append(b.conditions, "pm.location IS NOT NULL")
+		} else {
+			b.conditions = append(b.conditions, "pm.location IS NULL")
+		}
+	}
+	if photoQuerySorts[request.Sort].key == "pm.created_at" {
+		b.conditions = append(b.conditions, "pm.created_at IS NOT NULL")
+	}
+	return b
+}
+
+// QueryPhotos runs a compiled photo query and returns up to Limit photos after the cursor.
+func (r *PhotoRepo) QueryPhotos(ctx context.Context, request interfaces.PhotoQueryRepoRequest) ([]interfaces.QueriedPhoto, error) {
+	sort, ok := photoQuerySorts[request.Sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown sort %q", interfaces.ErrInvalidArgument, request.Sort)
+	}
+	b := r.filterPhotos(request)
+
+	rank, headline := "0::double precision", "''"
+	if request.TSQuery != "" {
+		rank, headline = photoQueryRank, fmt.Sprintf(photoQueryHeadline, b.language)
+	}
+	sortKey := sort.key
+	if sortKey == "rank" {
+		sortKey = rank
+	}
+	comparison, direction := ">", "ASC"
+	if sort.desc {
+		comparison, direction = "<", "DESC"
+	}
+	if after := request.After; after != nil {
+		var value interface{}
+		if after.Rank != nil {
+			value = *after.Rank
+		} else if after.Time != nil {
+			value = *after.Time
+		}
+		b.where("("+sortKey+", p.id) "+comparison+" (%s, %s)", value, after.ID)
+	}
+
+	query := fmt.Sprintf(`SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    (pm.location IS NOT NULL) AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0) AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0) AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location) AS location_private,
+    %s AS rank,
+    %s AS headline
+FROM %s
+WHERE %s
+ORDER BY %s %s, p.id %s
+LIMIT %s`,
+		rank, headline, b.from, strings.Join(b.conditions, "\n  AND "),
+		sortKey, direction, direction, b.arg(request.Limit))
+
+	rows, err := r.conn.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		log.Printf("Error querying photos: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+	photos := []interfaces.QueriedPhoto{}
+	for rows.Next() {
+		var row database.ListPhotosRow
+		var photo interfaces.QueriedPhoto
+		if err := rows.Scan(
+			&row.ID,
+			&row.OwnerID,
+			&row.Description,
+			&row.PhotoUrl,
+			&row.CreatedAt,
+			&row.HasLocation,
+			&row.Latitude,
+			&row.Longitude,
+			&row.CapturedAt,
+			&row.LocationPrivate,
+			&photo.Rank,
+			&photo.Headline,
+		); err != nil {
+			log.Printf("Error scanning queried photo: %v", err)
+			return nil, err
+		}
+		photo.Photo = toPhoto(row)
+		photos = append(photos, photo)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error querying photos: %v", err)
+		return nil, err
+	}
+	return photos, nil
+}
+
+// CountPhotos counts every photo matching a compiled photo query, ignoring sort and cursor.
+func (r *PhotoRepo) CountPhotos(ctx context.Context, request interfaces.PhotoQueryRepoRequest) (int64, error) {
+	b := r.filterPhotos(request)
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE %s", b.from, strings.Join(b.conditions, " AND "))
+	var count int64
+	if err := r.conn.QueryRowContext(ctx, query, b.args...).Scan(&count); err != nil {
+		log.Printf("Error counting photos: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
@@ -3,34 +3,361 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"log"
+	"slices"
 
 	"photo-service/src/interfaces"
 	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
 )
 
 type PhotoRepo struct {
-	db *database.Queries
+	conn *sql.DB
+	db   *database.Queries
+	// searchLanguage is the PostgreSQL text-search configuration new photos are indexed with.
+	searchLanguage string
 }
 
 // Constructor creates a new instance of PhotoRepo.
-func NewPhotoRepo(db *database.Queries) *PhotoRepo {
-	return &PhotoRepo{db: db}
+func NewPhotoRepo(conn *sql.DB, db *database.Queries, searchLanguage string) *PhotoRepo {
+	return &PhotoRepo{conn: conn, db: db, searchLanguage: searchLanguage}
 }
 
-// CreatePhoto creates a new photo entry in the database.
+// CreatePhoto creates a new photo entry in the database and counts it against the owner's
+// storage in the same transaction.
 func (r *PhotoRepo) CreatePhoto(ctx context.Context, request interfaces.CreatePhotoRepoRequest) (string, error) {
-	photo, err := r.db.CreatePhoto(ctx, database.CreatePhotoParams{
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return "", err
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	if err := q.EnsureStorageUsage(ctx, request.UserID); err != nil {
+		log.Printf("Error creating storage usage: %v", err)
+		return "", err
+	}
+	counted, err := q.AddPhotoUsage(ctx, database.AddPhotoUsageParams{
+		SizeBytes: request.SizeBytes,
+		OwnerID:   request.UserID,
+		MaxBytes:  request.Quota.MaxBytes,
+		MaxPhotos: request.Quota.MaxPhotos,
+	})
+	if err != nil {
+		log.Printf("Error updating storage usage: %v", err)
+		return "", err
+	}
+	if counted == 0 {
+		return "", interfaces.ErrQuotaExceeded
+	}
+
+	photo, err := q.CreatePhoto(ctx, database.CreatePhotoParams{
+		ID:      request.ID,
 		OwnerID: request.UserID,
 		Description: sql.NullString{
 			String: request.Description,
 			Valid:  request.Description != "", // Set Valid to true if the description is not empty
 		},
-		PhotoUrl: request.URL,
+		PhotoUrl:       request.URL,
+		SearchLanguage: r.searchLanguage,
+		Format:         toNullString(request.Format),
+		SizeBytes:      request.SizeBytes,
+		Status:         request.Status,
 	})
 	if err != nil {
 		log.Printf("Error creating photo: %v", err)
 		return "", err
 	}
+	_, err = q.CreatePhotoVersion(ctx, database.CreatePhotoVersionParams{
+		PhotoID:    photo.ID,
+		Version:    photo.CurrentVersion,
+		StorageKey: request.URL,
+		Sha256:     toNullString(request.SHA256),
+		SizeBytes:  request.SizeBytes,
+		Format:     toNullString(request.Format),
+	})
+	if err != nil {
+		log.Printf("Error creating photo version: %v", err)
+		return "", err
+	}
+	for _, job := range request.Jobs {
+		if _, err := enqueueJob(ctx, q, job); err != nil {
+			return "", err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing photo: %v", err)
+		return "", err
+	}
 	return photo.ID.String(), nil
 }
+
+// TrashPhoto moves a photo to the trash. Its storage stays counted until it is purged.
+func (r *PhotoRepo) TrashPhoto(ctx context.Context, id uuid.UUID) error {
+	trashed, err := r.db.TrashPhoto(ctx, id)
+	if err != nil {
+		log.Printf("Error trashing photo: %v", err)
+		return err
+	}
+	if trashed == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+// RestorePhoto takes a photo out of the owner's trash and queues the jobs it missed while
+// there, in the same transaction.
+func (r *PhotoRepo) RestorePhoto(ctx context.Context, request interfaces.RestorePhotoRepoRequest) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	status, err := q.RestorePhoto(ctx, database.RestorePhotoParams{ID: request.ID, OwnerID: request.OwnerID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error restoring photo: %v", err)
+		return err
+	}
+	if status == interfaces.PhotoStatusProcessing {
+		for _, job := range request.Reprocess {
+			if _, err := enqueueJob(ctx, q, job); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing photo restore: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PhotoRepo) ListTrashedPhotos(ctx context.Context, ownerID uuid.UUID, limit int, offset int) ([]interfaces.TrashedPhoto, error) {
+	rows, err := r.db.ListTrashedPhotos(ctx, database.ListTrashedPhotosParams{
+		OwnerID: ownerID,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	})
+	if err != nil {
+		log.Printf("Error listing trashed photos: %v", err)
+		return nil, err
+	}
+	photos := make([]interfaces.TrashedPhoto, 0, len(rows))
+	for _, row := range rows {
+		photos = append(photos, interfaces.TrashedPhoto{
+			ID:          row.ID,
+			Description: row.Description.String,
+			URL:         row.PhotoUrl,
+			Status:      row.Status,
+			SizeBytes:   row.SizeBytes,
+			CreatedAt:   row.CreatedAt,
+			DeletedAt:   row.DeletedAt,
+		})
+	}
+	return photos, nil
+}
+
+// PurgePhotos deletes trashed photos and releases their storage in the same transaction.
+// The stored files still have to be removed.
+func (r *PhotoRepo) PurgePhotos(ctx context.Context, request interfaces.PurgePhotosRepoRequest) ([]interfaces.DeletedPhoto, error) {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	rows, err := q.PurgePhotos(ctx, database.PurgePhotosParams{
+		OwnerID:       toNullUUID(request.OwnerID),
+		DeletedBefore: toNullTime(request.DeletedBefore),
+		MaxPhotos:     int32(request.Limit),
+	})
+	if err != nil {
+		log.Printf("Error purging photos: %v", err)
+		return nil, err
+	}
+	purged := make([]interfaces.DeletedPhoto, 0, len(rows))
+	for _, row := range rows {
+		err := q.RemovePhotoUsage(ctx, database.RemovePhotoUsageParams{
+			OriginalBytes: row.StoredBytes - row.VersionBytes,
+			VersionBytes:  row.VersionBytes,
+			OwnerID:       row.OwnerID,
+		})
+		if err != nil {
+			log.Printf("Error updating storage usage: %v", err)
+			return nil, err
+		}
+		files := row.StorageKeys
+		if !slices.Contains(files, row.PhotoUrl) {
+			files = append(files, row.PhotoUrl)
+		}
+		purged = append(purged, interfaces.DeletedPhoto{
+			ID:        row.ID,
+			OwnerID:   row.OwnerID,
+			URL:       row.PhotoUrl,
+			SizeBytes: row.StoredBytes,
+			Files:     files,
+		})
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing photo purge: %v", err)
+		return nil, err
+	}
+	return purged, nil
+}
+
+// GetPhoto loads a photo together with the parts of its metadata needed for lookups.
+func (r *PhotoRepo) GetPhoto(ctx context.Context, id uuid.UUID) (interfaces.PhotoRecord, error) {
+	photo, err := r.db.GetPhotoWithLocation(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.PhotoRecord{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting photo: %v", err)
+		return interfaces.PhotoRecord{}, err
+	}
+	return interfaces.PhotoRecord{
+		ID:             photo.ID,
+		OwnerID:        photo.OwnerID,
+		Description:    photo.Description.String,
+		URL:            photo.PhotoUrl,
+		CreatedAt:      photo.CreatedAt,
+		HasLocation:    photo.HasLocation,
+		CapturedAt:     nullTimePtr(photo.CapturedAt),
+		CurrentVersion: int(photo.CurrentVersion),
+		CurrentSHA256:  photo.CurrentSha256.String,
+	}, nil
+}
+
+// GetPhotoDetail loads a photo with its location, as shown to viewers.
+func (r *PhotoRepo) GetPhotoDetail(ctx context.Context, id uuid.UUID) (interfaces.Photo, error) {
+	row, err := r.db.GetPhotoDetail(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.Photo{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting photo: %v", err)
+		return interfaces.Photo{}, err
+	}
+	return toPhoto(database.ListPhotosRow(row)), nil
+}
+
+// ListPhotos returns a page of the owner's photos, newest first.
+func (r *PhotoRepo) ListPhotos(ctx context.Context, ownerID uuid.UUID, limit int, offset int) ([]interfaces.Photo, error) {
+	rows, err := r.db.ListPhotos(ctx, database.ListPhotosParams{
+		OwnerID: ownerID,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	})
+	if err != nil {
+		log.Printf("Error listing photos: %v", err)
+		return nil, err
+	}
+	photos := make([]interfaces.Photo, 0, len(rows))
+	for _, row := range rows {
+		photos = append(photos, toPhoto(row))
+	}
+	return photos, nil
+}
+
+// SearchPhotos runs a full-text search in the configured text-search language.
+func (r *PhotoRepo) SearchPhotos(ctx context.Context, ownerID uuid.UUID, tsQuery string, limit int, offset int) ([]interfaces.PhotoSearchResult, error) {
+	rows, err := r.db.SearchPhotos(ctx, database.SearchPhotosParams{
+		SearchLanguage: r.searchLanguage,
+		Query:          tsQuery,
+		OwnerID:        ownerID,
+		MaxResults:     int32(limit),
+		Skip:           int32(offset),
+	})
+	if err != nil {
+		log.Printf("Error searching photos: %v", err)
+		return nil, err
+	}
+	results := make([]interfaces.PhotoSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, interfaces.PhotoSearchResult{
+			Photo: toPhoto(database.ListPhotosRow{
+				ID:              row.ID,
+				OwnerID:         row.OwnerID,
+				Description:     row.Description,
+				PhotoUrl:        row.PhotoUrl,
+				CreatedAt:       row.CreatedAt,
+				Status:          row.Status,
+				HasLocation:     row.HasLocation,
+				Latitude:        row.Latitude,
+				Longitude:       row.Longitude,
+				CapturedAt:      row.CapturedAt,
+				LocationPrivate: row.LocationPrivate,
+			}),
+			Rank:     row.Rank,
+			Headline: row.Headline,
+		})
+	}
+	return results, nil
+}
+
+func (r *PhotoRepo) SetPhotoStatus(ctx context.Context, id uuid.UUID, status string) error {
+	if err := r.db.SetPhotoStatus(ctx, database.SetPhotoStatusParams{ID: id, Status: status}); err != nil {
+		log.Printf("Error setting photo status: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PhotoRepo) SetPhotoVersionMetadata(ctx context.Context, photoID uuid.UUID, version int, metadata interfaces.PhotoVersionMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	err = r.db.SetPhotoVersionMetadata(ctx, database.SetPhotoVersionMetadataParams{
+		PhotoID:  photoID,
+		Version:  int32(version),
+		Metadata: data,
+	})
+	if err != nil {
+		log.Printf("Error setting photo version metadata: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PhotoRepo) SetPhotoVersionSHA256(ctx context.Context, photoID uuid.UUID, version int, sha256 string) error {
+	err := r.db.SetPhotoVersionSHA256(ctx, database.SetPhotoVersionSHA256Params{
+		PhotoID: photoID,
+		Version: int32(version),
+		Sha256:  toNullString(sha256),
+	})
+	if err != nil {
+		log.Printf("Error setting photo version digest: %v", err)
+		return err
+	}
+	return nil
+}
+
+func toPhoto(row database.ListPhotosRow) interfaces.Photo {
+	photo := interfaces.Photo{
+		ID:              row.ID,
+		OwnerID:         row.OwnerID,
+		Description:     row.Description.String,
+		URL:             row.PhotoUrl,
+		Status:          row.Status,
+		CapturedAt:      nullTimePtr(row.CapturedAt),
+		CreatedAt:       row.CreatedAt,
+		LocationPrivate: row.LocationPrivate,
+	}
+	if row.HasLocation {
+		photo.Location = &interfaces.PhotoLocation{Latitude: row.Latitude, Longitude: row.Longitude}
+	}
+	return photo
+}
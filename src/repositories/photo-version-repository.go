@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type PhotoVersionRepo struct {
+	conn *sql.DB
+	db   *database.Queries
+}
+
+func NewPhotoVersionRepo(conn *sql.DB, db *database.Queries) *PhotoVersionRepo {
+	return &PhotoVersionRepo{conn: conn, db: db}
+}
+
+// CreatePhotoVersion adds the photo's newest version and makes it current. The photo row
+// stays locked until the transaction ends, so concurrent uploads get consecutive numbers.
+func (r *PhotoVersionRepo) CreatePhotoVersion(ctx context.Context, request interfaces.CreatePhotoVersionRepoRequest) (interfaces.PhotoVersion, error) {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return interfaces.PhotoVersion{}, err
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	photo, err := q.GetPhotoForNewVersion(ctx, request.PhotoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.PhotoVersion{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error locking photo: %v", err)
+		return interfaces.PhotoVersion{}, err
+	}
+	if err := q.EnsureStorageUsage(ctx, photo.OwnerID); err != nil {
+		log.Printf("Error creating storage usage: %v", err)
+		return interfaces.PhotoVersion{}, err
+	}
+	counted, err := q.AddVersionUsage(ctx, database.AddVersionUsageParams{
+		SizeBytes: request.SizeBytes,
+		OwnerID:   photo.OwnerID,
+		MaxBytes:  request.Quota.MaxBytes,
+	})
+	if err != nil {
+		log.Printf("Error updating storage usage: %v", err)
+		return interfaces.PhotoVersion{}, err
+	}
+	if counted == 0 {
+		return interfaces.PhotoVersion{}, interfaces.ErrQuotaExceeded
+	}
+
+	version, err := q.CreatePhotoVersion(ctx, database.CreatePhotoVersionParams{
+		PhotoID:          request.PhotoID,
+		Version:          photo.LatestVersion + 1,
+		StorageKey:       request.StorageKey,
+		StorageVersionID: toNullString(request.StorageVersionID),
+		Sha256:           toNullString(request.SHA256),
+		SizeBytes:        request.SizeBytes,
+		Format:           toNullString(request.Format),
+	})
+	if err != nil {
+		log.Printf("Error creating photo version: %v", err)
+		return interfaces.PhotoVersion{}, err
+	}
+	err = setCurrentVersion(ctx, q, interfaces.SetCurrentVersionRepoRequest{
+		PhotoID:    request.PhotoID,
+		Version:    int(version.Version),
+		StorageKey: request.StorageKey,
+		Jobs:       request.Jobs,
+	})
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing photo version: %v", err)
+		return interfaces.PhotoVersion{}, err
+	}
+	row := database.ListPhotoVersionsRow{
+		PhotoID:          version.PhotoID,
+		Version:          version.Version,
+		StorageKey:       version.StorageKey,
+		StorageVersionID: version.StorageVersionID,
+		Sha256:           version.Sha256,
+		SizeBytes:        version.SizeBytes,
+		Format:           version.Format,
+		Metadata:         version.Metadata,
+		CreatedAt:        version.CreatedAt,
+		Current:          true,
+	}
+	return toPhotoVersion(row), nil
+}
+
+func (r *PhotoVersionRepo) GetPhotoVersion(ctx context.Context, photoID uuid.UUID, version int) (interfaces.PhotoVersion, error) {
+	row, err := r.db.GetPhotoVersion(ctx, database.GetPhotoVersionParams{PhotoID: photoID, Version: int32(version)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.PhotoVersion{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting photo version: %v", err)
+		return interfaces.PhotoVersion{}, err
+	}
+	return toPhotoVersion(database.ListPhotoVersionsRow(row)), nil
+}
+
+func (r *PhotoVersionRepo) ListPhotoVersions(ctx context.Context, photoID uuid.UUID) ([]interfaces.PhotoVersion, error) {
+	rows, err := r.db.ListPhotoVersions(ctx, photoID)
+	if err != nil {
+		log.Printf("Error listing photo versions: %v", err)
+		return nil, err
+	}
+	versions := make([]interfaces.PhotoVersion, 0, len(rows))
+	for _, row := range rows {
+		versions = append(versions, toPhotoVersion(row))
+	}
+	return versions, nil
+}
+
+// SetCurrentVersion makes an existing version current and queues its jobs in the same
+// transaction.
+func (r *PhotoVersionRepo) SetCurrentVersion(ctx context.Context, request interfaces.SetCurrentVersionRepoRequest) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+	if err := setCurrentVersion(ctx, r.db.WithTx(tx), request); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing current photo version: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PhotoVersionRepo) SetStorageVersionID(ctx context.Context, photoID uuid.UUID, version int, storageVersionID string) error {
+	err := r.db.SetPhotoVersionStorageID(ctx, database.SetPhotoVersionStorageIDParams{
+		PhotoID:          photoID,
+		Version:          int32(version),
+		StorageVersionID: toNullString(storageVersionID),
+	})
+	if err != nil {
+		log.Printf("Error setting photo version storage ID: %v", err)
+		return err
+	}
+	return nil
+}
+
+// setCurrentVersion points the photo at a version's file and enqueues request.Jobs.
+func setCurrentVersion(ctx context.Context, q *database.Queries, request interfaces.SetCurrentVersionRepoRequest) error {
+	updated, err := q.SetPhotoCurrentVersion(ctx, database.SetPhotoCurrentVersionParams{
+		PhotoUrl: request.StorageKey,
+		PhotoID:  request.PhotoID,
+		Version:  int32(request.Version),
+	})
+	if err != nil {
+		log.Printf("Error setting current photo version: %v", err)
+		return err
+	}
+	if updated == 0 {
+		return interfaces.ErrNotFound
+	}
+	for _, job := range request.Jobs {
+		if _, err := enqueueJob(ctx, q, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toPhotoVersion(row database.ListPhotoVersionsRow) interfaces.PhotoVersion {
+	version := interfaces.PhotoVersion{
+		Version:          int(row.Version),
+		Current:          row.Current,
+		SizeBytes:        row.SizeBytes,
+		SHA256:           row.Sha256.String,
+		Format:           row.Format.String,
+		CreatedAt:        row.CreatedAt,
+		StorageKey:       row.StorageKey,
+		StorageVersionID: row.StorageVersionID.String,
+	}
+	// Files not processed yet have empty metadata.
+	if len(row.Metadata) > 0 && !bytes.Equal(row.Metadata, []byte("{}")) {
+		var metadata interfaces.PhotoVersionMetadata
+		if err := json.Unmarshal(row.Metadata, &metadata); err != nil {
+			log.Printf("Error decoding photo version metadata: %v", err)
+		} else {
+			version.Metadata = &metadata
+		}
+	}
+	return version
+}
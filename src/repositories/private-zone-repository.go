@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type PrivateZoneRepo struct {
+	db *database.Queries
+}
+
+func NewPrivateZoneRepo(db *database.Queries) *PrivateZoneRepo {
+	return &PrivateZoneRepo{db: db}
+}
+
+// CreatePrivateZone stores a zone and returns its ID.
+func (r *PrivateZoneRepo) CreatePrivateZone(ctx context.Context, request interfaces.SavePrivateZoneRepoRequest) (uuid.UUID, error) {
+	params := database.CreatePrivateZoneParams{
+		OwnerID: request.OwnerID,
+		Name:    request.Name,
+		Kind:    request.Kind,
+	}
+	params.CenterLongitude, params.CenterLatitude, params.RadiusM, params.PolygonGeojson = zoneGeometryParams(request)
+	id, err := r.db.CreatePrivateZone(ctx, params)
+	if err != nil {
+		log.Printf("Error creating private zone: %v", err)
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// GetPrivateZone loads a zone by ID.
+func (r *PrivateZoneRepo) GetPrivateZone(ctx context.Context, id uuid.UUID) (interfaces.PrivateZone, error) {
+	row, err := r.db.GetPrivateZone(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.PrivateZone{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting private zone: %v", err)
+		return interfaces.PrivateZone{}, err
+	}
+	return toPrivateZone(database.ListPrivateZonesRow(row)), nil
+}
+
+// ListPrivateZones returns all zones of an owner, oldest first.
+func (r *PrivateZoneRepo) ListPrivateZones(ctx context.Context, ownerID uuid.UUID) ([]interfaces.PrivateZone, error) {
+	rows, err := r.db.ListPrivateZones(ctx, ownerID)
+	if err != nil {
+		log.Printf("Error listing private zones: %v", err)
+		return nil, err
+	}
+	zones := make([]interfaces.PrivateZone, 0, len(rows))
+	for _, row := range rows {
+		zones = append(zones, toPrivateZone(row))
+	}
+	return zones, nil
+}
+
+// UpdatePrivateZone replaces the name and geometry of an owner's zone.
+func (r *PrivateZoneRepo) UpdatePrivateZone(ctx context.Context, request interfaces.SavePrivateZoneRepoRequest) error {
+	params := database.UpdatePrivateZoneParams{
+		ID:      request.ID,
+		OwnerID: request.OwnerID,
+		Name:    request.Name,
+		Kind:    request.Kind,
+	}
+	params.CenterLongitude, params.CenterLatitude, params.RadiusM, params.PolygonGeojson = zoneGeometryParams(request)
+	updated, err := r.db.UpdatePrivateZone(ctx, params)
+	if err != nil {
+		log.Printf("Error updating private zone: %v", err)
+		return err
+	}
+	if updated == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+// DeletePrivateZone removes an owner's zone.
+func (r *PrivateZoneRepo) DeletePrivateZone(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) error {
+	deleted, err := r.db.DeletePrivateZone(ctx, database.DeletePrivateZoneParams{ID: id, OwnerID: ownerID})
+	if err != nil {
+		log.Printf("Error deleting private zone: %v", err)
+		return err
+	}
+	if deleted == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+func zoneGeometryParams(request interfaces.SavePrivateZoneRepoRequest) (sql.NullFloat64, sql.NullFloat64, sql.NullFloat64, sql.NullString) {
+	var longitude, latitude, radius sql.NullFloat64
+	if request.Center != nil {
+		longitude = sql.NullFloat64{Float64: request.Center.Longitude, Valid: true}
+		latitude = sql.NullFloat64{Float64: request.Center.Latitude, Valid: true}
+	}
+	if request.RadiusMeters != nil {
+		radius = sql.NullFloat64{Float64: *request.RadiusMeters, Valid: true}
+	}
+	polygon := sql.NullString{String: request.PolygonGeoJSON, Valid: request.PolygonGeoJSON != ""}
+	return longitude, latitude, radius, polygon
+}
+
+func toPrivateZone(row database.ListPrivateZonesRow) interfaces.PrivateZone {
+	zone := interfaces.PrivateZone{
+		ID:        row.ID,
+		OwnerID:   row.OwnerID,
+		Name:      row.Name,
+		Kind:      row.Kind,
+		Center:    interfaces.PhotoLocation{Latitude: row.CenterLatitude, Longitude: row.CenterLongitude},
+		Area:      json.RawMessage(row.AreaGeojson),
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+	if row.RadiusM.Valid {
+		zone.RadiusMeters = &row.RadiusM.Float64
+	}
+	return zone
+}
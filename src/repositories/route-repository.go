@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+)
+
+type RouteRepo struct {
+	db *database.Queries
+}
+
+func NewRouteRepo(db *database.Queries) *RouteRepo {
+	return &RouteRepo{db: db}
+}
+
+// ListRouteSegments returns one line per day (or per trip) through the owner's geotagged photos.
+func (r *RouteRepo) ListRouteSegments(ctx context.Context, request interfaces.ListRoutesRepoRequest) ([]interfaces.RouteSegmentRecord, error) {
+	rows, err := r.db.ListRouteSegments(ctx, database.ListRouteSegmentsParams{
+		PerTrip:  request.PerTrip,
+		FromTime: request.From,
+		OwnerID:  request.OwnerID,
+		ToTime:   request.To,
+	})
+	if err != nil {
+		log.Printf("Error listing route segments: %v", err)
+		return nil, err
+	}
+	segments := make([]interfaces.RouteSegmentRecord, 0, len(rows))
+	for _, row := range rows {
+		segment := interfaces.RouteSegmentRecord{
+			Day:            row.SegmentDay,
+			PhotoCount:     int(row.PhotoCount),
+			StartedAt:      row.StartedAt,
+			EndedAt:        row.EndedAt,
+			LineGeoJSON:    row.LineGeojson,
+			DistanceMeters: row.DistanceM,
+		}
+		if row.HasAltitude {
+			segment.ElevationGainMeters = &row.ElevationGainM
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// ListRoutePlaces clusters the owner's geotagged photos into visited places per day (or per trip).
+func (r *RouteRepo) ListRoutePlaces(ctx context.Context, request interfaces.ListRoutesRepoRequest, clusterRadiusMeters float64) ([]interfaces.RoutePlaceRecord, error) {
+	rows, err := r.db.ListRoutePlaces(ctx, database.ListRoutePlacesParams{
+		PerTrip:        request.PerTrip,
+		FromTime:       request.From,
+		OwnerID:        request.OwnerID,
+		ToTime:         request.To,
+		ClusterRadiusM: clusterRadiusMeters,
+	})
+	if err != nil {
+		log.Printf("Error listing route places: %v", err)
+		return nil, err
+	}
+	places := make([]interfaces.RoutePlaceRecord, 0, len(rows))
+	for _, row := range rows {
+		places = append(places, interfaces.RoutePlaceRecord{
+			Day: row.SegmentDay,
+			Place: interfaces.RoutePlace{
+				Location:   interfaces.PhotoLocation{Latitude: row.Latitude, Longitude: row.Longitude},
+				ArrivedAt:  row.ArrivedAt,
+				LeftAt:     row.LeftAt,
+				PhotoCount: int(row.PhotoCount),
+			},
+		})
+	}
+	return places, nil
+}
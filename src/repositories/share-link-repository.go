@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type ShareLinkRepo struct {
+	db *database.Queries
+}
+
+func NewShareLinkRepo(db *database.Queries) *ShareLinkRepo {
+	return &ShareLinkRepo{db: db}
+}
+
+func (r *ShareLinkRepo) CreateShareLink(ctx context.Context, request interfaces.CreateShareLinkRepoRequest) (interfaces.ShareLink, error) {
+	link, err := r.db.CreateShareLink(ctx, database.CreateShareLinkParams{
+		OwnerID:       request.OwnerID,
+		TokenHash:     request.TokenHash,
+		PhotoID:       toNullUUID(request.PhotoID),
+		AlbumID:       toNullUUID(request.AlbumID),
+		PasswordHash:  toNullString(request.PasswordHash),
+		AllowDownload: request.AllowDownload,
+		ExpiresAt:     toNullTime(request.ExpiresAt),
+	})
+	if err != nil {
+		log.Printf("Error creating share link: %v", err)
+		return interfaces.ShareLink{}, err
+	}
+	return toShareLink(link), nil
+}
+
+func (r *ShareLinkRepo) GetShareLink(ctx context.Context, id uuid.UUID) (interfaces.ShareLink, error) {
+	link, err := r.db.GetShareLink(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.ShareLink{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting share link: %v", err)
+		return interfaces.ShareLink{}, err
+	}
+	return toShareLink(link), nil
+}
+
+func (r *ShareLinkRepo) GetShareLinkByTokenHash(ctx context.Context, tokenHash []byte) (interfaces.ShareLink, error) {
+	link, err := r.db.GetShareLinkByTokenHash(ctx, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.ShareLink{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting share link: %v", err)
+		return interfaces.ShareLink{}, err
+	}
+	return toShareLink(link), nil
+}
+
+// ListShareLinks returns the owner's links, including revoked and expired ones, newest first.
+func (r *ShareLinkRepo) ListShareLinks(ctx context.Context, ownerID uuid.UUID) ([]interfaces.ShareLink, error) {
+	rows, err := r.db.ListShareLinks(ctx, ownerID)
+	if err != nil {
+		log.Printf("Error listing share links: %v", err)
+		return nil, err
+	}
+	links := make([]interfaces.ShareLink, 0, len(rows))
+	for _, row := range rows {
+		links = append(links, toShareLink(row))
+	}
+	return links, nil
+}
+
+// RevokeShareLink marks a link as revoked; revoking it again keeps the first revocation time.
+func (r *ShareLinkRepo) RevokeShareLink(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.RevokeShareLink(ctx, id); err != nil {
+		log.Printf("Error revoking share link: %v", err)
+		return err
+	}
+	return nil
+}
+
+func toShareLink(row database.ShareLink) interfaces.ShareLink {
+	link := interfaces.ShareLink{
+		ID:            row.ID,
+		OwnerID:       row.OwnerID,
+		HasPassword:   row.PasswordHash.Valid,
+		AllowDownload: row.AllowDownload,
+		ExpiresAt:     nullTimePtr(row.ExpiresAt),
+		RevokedAt:     nullTimePtr(row.RevokedAt),
+		CreatedAt:     row.CreatedAt,
+		PasswordHash:  row.PasswordHash.String,
+	}
+	if row.PhotoID.Valid {
+		link.PhotoID = &row.PhotoID.UUID
+	}
+	if row.AlbumID.Valid {
+		link.AlbumID = &row.AlbumID.UUID
+	}
+	return link
+}
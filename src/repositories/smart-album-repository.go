@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type SmartAlbumRepo struct {
+	db *database.Queries
+}
+
+func NewSmartAlbumRepo(db *database.Queries) *SmartAlbumRepo {
+	return &SmartAlbumRepo{db: db}
+}
+
+// CreateSmartAlbum saves a smart album with its query document.
+func (r *SmartAlbumRepo) CreateSmartAlbum(ctx context.Context, request interfaces.CreateSmartAlbumRepoRequest) (interfaces.SmartAlbum, error) {
+	query, err := json.Marshal(request.Query)
+	if err != nil {
+		return interfaces.SmartAlbum{}, err
+	}
+	album, err := r.db.CreateSmartAlbum(ctx, database.CreateSmartAlbumParams{
+		OwnerID:     request.OwnerID,
+		Title:       request.Title,
+		Description: toNullString(request.Description),
+		Query:       query,
+	})
+	if err != nil {
+		log.Printf("Error creating smart album: %v", err)
+		return interfaces.SmartAlbum{}, err
+	}
+	return toSmartAlbum(album)
+}
+
+func (r *SmartAlbumRepo) GetSmartAlbum(ctx context.Context, id uuid.UUID) (interfaces.SmartAlbum, error) {
+	album, err := r.db.GetSmartAlbum(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.SmartAlbum{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting smart album: %v", err)
+		return interfaces.SmartAlbum{}, err
+	}
+	return toSmartAlbum(album)
+}
+
+// ListSmartAlbums returns the owner's smart albums, newest first.
+func (r *SmartAlbumRepo) ListSmartAlbums(ctx context.Context, ownerID uuid.UUID) ([]interfaces.SmartAlbum, error) {
+	rows, err := r.db.ListSmartAlbums(ctx, ownerID)
+	if err != nil {
+		log.Printf("Error listing smart albums: %v", err)
+		return nil, err
+	}
+	albums := make([]interfaces.SmartAlbum, 0, len(rows))
+	for _, row := range rows {
+		album, err := toSmartAlbum(row)
+		if err != nil {
+			return nil, err
+		}
+		albums = append(albums, album)
+	}
+	return albums, nil
+}
+
+// UpdateSmartAlbum changes the title, description and/or query of a smart album.
+func (r *SmartAlbumRepo) UpdateSmartAlbum(ctx context.Context, request interfaces.UpdateSmartAlbumRepoRequest) error {
+	params := database.UpdateSmartAlbumParams{ID: request.AlbumID, SetDescription: request.SetDescription}
+	if request.Title != nil {
+		params.Title = sql.NullString{String: *request.Title, Valid: true}
+	}
+	if request.Description != nil {
+		params.Description = toNullString(*request.Description)
+	}
+	if request.Query != nil {
+		query, err := json.Marshal(request.Query)
+		if err != nil {
+			return err
+		}
+		params.SetQuery = true
+		params.Query = query
+	}
+	updated, err := r.db.UpdateSmartAlbum(ctx, params)
+	if err != nil {
+		log.Printf("Error updating smart album: %v", err)
+		return err
+	}
+	if updated == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SmartAlbumRepo) DeleteSmartAlbum(ctx context.Context, id uuid.UUID) error {
+	deleted, err := r.db.DeleteSmartAlbum(ctx, id)
+	if err != nil {
+		log.Printf("Error deleting smart album: %v", err)
+		return err
+	}
+	if deleted == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+func toSmartAlbum(row database.SmartAlbum) (interfaces.SmartAlbum, error) {
+	album := interfaces.SmartAlbum{
+		ID:          row.ID,
+		OwnerID:     row.OwnerID,
+		Title:       row.Title,
+		Description: row.Description.String,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	if err := json.Unmarshal(row.Query, &album.Query); err != nil {
+		log.Printf("Error decoding smart album query: %v", err)
+		return interfaces.SmartAlbum{}, err
+	}
+	return album, nil
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type StorageUsageRepo struct {
+	db *database.Queries
+}
+
+func NewStorageUsageRepo(db *database.Queries) *StorageUsageRepo {
+	return &StorageUsageRepo{db: db}
+}
+
+func (r *StorageUsageRepo) GetStorageUsage(ctx context.Context, ownerID uuid.UUID) (interfaces.StorageUsageRecord, error) {
+	usage, err := r.db.GetStorageUsage(ctx, ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.StorageUsageRecord{OwnerID: ownerID}, nil
+	}
+	if err != nil {
+		log.Printf("Error getting storage usage: %v", err)
+		return interfaces.StorageUsageRecord{}, err
+	}
+	return interfaces.StorageUsageRecord{
+		OwnerID:       usage.OwnerID,
+		Tier:          usage.Tier.String,
+		PhotoCount:    usage.PhotoCount,
+		OriginalBytes: usage.OriginalBytes,
+		VersionBytes:  usage.VersionBytes,
+	}, nil
+}
+
+// SetStorageTier moves the owner to a tier; an empty tier means the default one.
+func (r *StorageUsageRepo) SetStorageTier(ctx context.Context, ownerID uuid.UUID, tier string) error {
+	err := r.db.SetStorageTier(ctx, database.SetStorageTierParams{OwnerID: ownerID, Tier: toNullString(tier)})
+	if err != nil {
+		log.Printf("Error setting storage tier: %v", err)
+		return err
+	}
+	return nil
+}
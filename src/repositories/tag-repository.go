@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type TagRepo struct {
+	conn *sql.DB
+	db   *database.Queries
+}
+
+func NewTagRepo(conn *sql.DB, db *database.Queries) *TagRepo {
+	return &TagRepo{conn: conn, db: db}
+}
+
+// AddPhotoTags creates any missing tags for the owner and links them to the photo.
+func (r *TagRepo) AddPhotoTags(ctx context.Context, request interfaces.AddPhotoTagsRepoRequest) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	if err := q.CreateTags(ctx, database.CreateTagsParams{OwnerID: request.OwnerID, Names: request.Names}); err != nil {
+		log.Printf("Error creating tags: %v", err)
+		return err
+	}
+	_, err = q.AddPhotoTags(ctx, database.AddPhotoTagsParams{
+		PhotoID:    request.PhotoID,
+		Source:     request.Source,
+		OwnerID:    request.OwnerID,
+		LowerNames: lowerNames(request.Names),
+	})
+	if err != nil {
+		log.Printf("Error adding photo tags: %v", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemovePhotoTags unlinks the named tags from the photo; unknown names are ignored.
+func (r *TagRepo) RemovePhotoTags(ctx context.Context, photoID uuid.UUID, names []string) error {
+	_, err := r.db.RemovePhotoTags(ctx, database.RemovePhotoTagsParams{PhotoID: photoID, LowerNames: lowerNames(names)})
+	if err != nil {
+		log.Printf("Error removing photo tags: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ListPhotoTags returns the names of a photo's tags in alphabetical order.
+func (r *TagRepo) ListPhotoTags(ctx context.Context, photoID uuid.UUID) ([]string, error) {
+	names, err := r.db.ListPhotoTags(ctx, photoID)
+	if err != nil {
+		log.Printf("Error listing photo tags: %v", err)
+		return nil, err
+	}
+	if names == nil {
+		names = []string{}
+	}
+	return names, nil
+}
+
+// SearchTags returns the owner's tags starting with prefix, most used first.
+func (r *TagRepo) SearchTags(ctx context.Context, ownerID uuid.UUID, prefix string, limit int) ([]interfaces.TagUsage, error) {
+	rows, err := r.db.SearchTags(ctx, database.SearchTagsParams{
+		OwnerID:     ownerID,
+		LowerPrefix: escapeLike(strings.ToLower(prefix)),
+		MaxResults:  int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error searching tags: %v", err)
+		return nil, err
+	}
+	tags := make([]interfaces.TagUsage, 0, len(rows))
+	for _, row := range rows {
+		tags = append(tags, interfaces.TagUsage{ID: row.ID, Name: row.Name, UsageCount: int(row.UsageCount)})
+	}
+	return tags, nil
+}
+
+// ListPhotosByTags returns the owner's photos carrying all (or any) of the tags, newest first.
+func (r *TagRepo) ListPhotosByTags(ctx context.Context, request interfaces.ListPhotosByTagsRepoRequest) ([]interfaces.Photo, error) {
+	rows, err := r.db.ListPhotosByTags(ctx, database.ListPhotosByTagsParams{
+		OwnerID:    request.OwnerID,
+		LowerNames: lowerNames(request.Names),
+		MatchAll:   request.MatchAll,
+		MaxResults: int32(request.Limit),
+		Skip:       int32(request.Offset),
+	})
+	if err != nil {
+		log.Printf("Error listing photos by tags: %v", err)
+		return nil, err
+	}
+	photos := make([]interfaces.Photo, 0, len(rows))
+	for _, row := range rows {
+		photos = append(photos, toPhoto(database.ListPhotosRow(row)))
+	}
+	return photos, nil
+}
+
+func lowerNames(names []string) []string {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+	return lowered
+}
+
+// escapeLike escapes the LIKE wildcards so a prefix is matched literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// tusUploadLease is how long a write may go without progress before its lock lapses and
+// another request can resume the upload.
+const tusUploadLease = 5 * time.Minute
+
+type TusUploadRepo struct {
+	conn *sql.DB
+	db   *database.Queries
+}
+
+func NewTusUploadRepo(conn *sql.DB, db *database.Queries) *TusUploadRepo {
+	return &TusUploadRepo{conn: conn, db: db}
+}
+
+func (r *TusUploadRepo) CreateTusUpload(ctx context.Context, request interfaces.CreateTusUploadRepoRequest) (interfaces.TusUpload, error) {
+	upload, err := r.db.CreateTusUpload(ctx, database.CreateTusUploadParams{
+		ID:           request.ID,
+		OwnerID:      request.OwnerID,
+		UploadLength: request.Length,
+		Metadata:     request.Metadata,
+		FileName:     request.FileName,
+		Description:  toNullString(request.Description),
+		StorageKey:   request.StorageKey,
+		S3UploadID:   request.S3UploadID,
+	})
+	if err != nil {
+		log.Printf("Error creating upload: %v", err)
+		return interfaces.TusUpload{}, err
+	}
+	return toTusUpload(upload), nil
+}
+
+func (r *TusUploadRepo) GetTusUpload(ctx context.Context, id uuid.UUID) (interfaces.TusUpload, error) {
+	upload, err := r.db.GetTusUpload(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.TusUpload{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting upload: %v", err)
+		return interfaces.TusUpload{}, err
+	}
+	return toTusUpload(upload), nil
+}
+
+func (r *TusUploadRepo) ClaimTusUpload(ctx context.Context, id uuid.UUID, offset int64) (interfaces.TusUpload, error) {
+	upload, err := r.db.ClaimTusUpload(ctx, database.ClaimTusUploadParams{
+		LeaseSeconds: tusUploadLease.Seconds(),
+		ID:           id,
+		UploadOffset: offset,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.TusUpload{}, interfaces.ErrConflict
+	}
+	if err != nil {
+		log.Printf("Error claiming upload: %v", err)
+		return interfaces.TusUpload{}, err
+	}
+	return toTusUpload(upload), nil
+}
+
+func (r *TusUploadRepo) ReleaseTusUpload(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.ReleaseTusUpload(ctx, id); err != nil {
+		log.Printf("Error releasing upload: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *TusUploadRepo) AddTusUploadPart(ctx context.Context, id uuid.UUID, part interfaces.TusUploadPart, offset int64, partsBytes int64) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	err = q.AddTusUploadPart(ctx, database.AddTusUploadPartParams{
+		UploadID:   id,
+		PartNumber: part.PartNumber,
+		Etag:       part.ETag,
+		SizeBytes:  part.SizeBytes,
+	})
+	if err != nil {
+		log.Printf("Error adding upload part: %v", err)
+		return err
+	}
+	err = q.UpdateTusUploadProgress(ctx, database.UpdateTusUploadProgressParams{
+		UploadOffset: offset,
+		PartsBytes:   partsBytes,
+		LeaseSeconds: tusUploadLease.Seconds(),
+		ID:           id,
+	})
+	if err != nil {
+		log.Printf("Error updating upload progress: %v", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TusUploadRepo) UpdateTusUploadProgress(ctx context.Context, id uuid.UUID, offset int64, partsBytes int64) error {
+	err := r.db.UpdateTusUploadProgress(ctx, database.UpdateTusUploadProgressParams{
+		UploadOffset: offset,
+		PartsBytes:   partsBytes,
+		LeaseSeconds: tusUploadLease.Seconds(),
+		ID:           id,
+	})
+	if err != nil {
+		log.Printf("Error updating upload progress: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *TusUploadRepo) ListTusUploadParts(ctx context.Context, id uuid.UUID) ([]interfaces.TusUploadPart, error) {
+	rows, err := r.db.ListTusUploadParts(ctx, id)
+	if err != nil {
+		log.Printf("Error listing upload parts: %v", err)
+		return nil, err
+	}
+	parts := make([]interfaces.TusUploadPart, 0, len(rows))
+	for _, row := range rows {
+		parts = append(parts, interfaces.TusUploadPart{PartNumber: row.PartNumber, ETag: row.Etag, SizeBytes: row.SizeBytes})
+	}
+	return parts, nil
+}
+
+func (r *TusUploadRepo) MarkTusUploadAssembled(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.MarkTusUploadAssembled(ctx, id); err != nil {
+		log.Printf("Error marking upload assembled: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *TusUploadRepo) SetTusUploadPhoto(ctx context.Context, id uuid.UUID, photoID uuid.UUID) error {
+	err := r.db.SetTusUploadPhoto(ctx, database.SetTusUploadPhotoParams{ID: id, PhotoID: toNullUUID(&photoID)})
+	if err != nil {
+		log.Printf("Error setting upload photo: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *TusUploadRepo) DeleteTusUpload(ctx context.Context, id uuid.UUID) error {
+	deleted, err := r.db.DeleteTusUpload(ctx, id)
+	if err != nil {
+		log.Printf("Error deleting upload: %v", err)
+		return err
+	}
+	if deleted == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+func (r *TusUploadRepo) ListStaleTusUploads(ctx context.Context, updatedBefore time.Time, limit int) ([]interfaces.TusUpload, error) {
+	rows, err := r.db.ListStaleTusUploads(ctx, database.ListStaleTusUploadsParams{
+		UpdatedBefore: updatedBefore,
+		MaxUploads:    int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error listing stale uploads: %v", err)
+		return nil, err
+	}
+	uploads := make([]interfaces.TusUpload, 0, len(rows))
+	for _, row := range rows {
+		uploads = append(uploads, toTusUpload(row))
+	}
+	return uploads, nil
+}
+
+func toTusUpload(row database.TusUpload) interfaces.TusUpload {
+	return interfaces.TusUpload{
+		ID:          row.ID,
+		OwnerID:     row.OwnerID,
+		Length:      row.UploadLength,
+		Offset:      row.UploadOffset,
+		PartsBytes:  row.PartsBytes,
+		Metadata:    row.Metadata,
+		FileName:    row.FileName,
+		Description: row.Description.String,
+		StorageKey:  row.StorageKey,
+		S3UploadID:  row.S3UploadID,
+		AssembledAt: nullTimePtr(row.AssembledAt),
+		PhotoID:     nullUUIDPtr(row.PhotoID),
+	}
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// uploadIntentLease is how long a completion may take before its lock lapses and the
+// intent can be completed by another request.
+const uploadIntentLease = 2 * time.Minute
+
+type UploadIntentRepo struct {
+	db *database.Queries
+}
+
+func NewUploadIntentRepo(db *database.Queries) *UploadIntentRepo {
+	return &UploadIntentRepo{db: db}
+}
+
+func (r *UploadIntentRepo) CreateUploadIntent(ctx context.Context, request interfaces.CreateUploadIntentRepoRequest) (interfaces.UploadIntent, error) {
+	intent, err := r.db.CreateUploadIntent(ctx, database.CreateUploadIntentParams{
+		ID:          request.ID,
+		OwnerID:     request.OwnerID,
+		StorageKey:  request.StorageKey,
+		FileName:    request.FileName,
+		Description: toNullString(request.Description),
+		ContentType: request.ContentType,
+		SizeBytes:   request.SizeBytes,
+		ExpiresAt:   request.ExpiresAt,
+	})
+	if err != nil {
+		log.Printf("Error creating upload intent: %v", err)
+		return interfaces.UploadIntent{}, err
+	}
+	return toUploadIntent(intent), nil
+}
+
+func (r *UploadIntentRepo) GetUploadIntent(ctx context.Context, id uuid.UUID) (interfaces.UploadIntent, error) {
+	intent, err := r.db.GetUploadIntent(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.UploadIntent{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting upload intent: %v", err)
+		return interfaces.UploadIntent{}, err
+	}
+	return toUploadIntent(intent), nil
+}
+
+func (r *UploadIntentRepo) ClaimUploadIntent(ctx context.Context, id uuid.UUID) (interfaces.UploadIntent, error) {
+	intent, err := r.db.ClaimUploadIntent(ctx, database.ClaimUploadIntentParams{
+		LeaseSeconds: uploadIntentLease.Seconds(),
+		ID:           id,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.UploadIntent{}, interfaces.ErrConflict
+	}
+	if err != nil {
+		log.Printf("Error claiming upload intent: %v", err)
+		return interfaces.UploadIntent{}, err
+	}
+	return toUploadIntent(intent), nil
+}
+
+func (r *UploadIntentRepo) ReleaseUploadIntent(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.ReleaseUploadIntent(ctx, id); err != nil {
+		log.Printf("Error releasing upload intent: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *UploadIntentRepo) MarkUploadIntentCompleted(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.MarkUploadIntentCompleted(ctx, id); err != nil {
+		log.Printf("Error marking upload intent completed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *UploadIntentRepo) ListExpiredUploadIntents(ctx context.Context, limit int) ([]interfaces.UploadIntent, error) {
+	rows, err := r.db.ListExpiredUploadIntents(ctx, int32(limit))
+	if err != nil {
+		log.Printf("Error listing expired upload intents: %v", err)
+		return nil, err
+	}
+	intents := make([]interfaces.UploadIntent, 0, len(rows))
+	for _, row := range rows {
+		intents = append(intents, toUploadIntent(row))
+	}
+	return intents, nil
+}
+
+func (r *UploadIntentRepo) DeleteUploadIntent(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.DeleteUploadIntent(ctx, id); err != nil {
+		log.Printf("Error deleting upload intent: %v", err)
+		return err
+	}
+	return nil
+}
+
+func toUploadIntent(row database.UploadIntent) interfaces.UploadIntent {
+	return interfaces.UploadIntent{
+		ID:          row.ID,
+		OwnerID:     row.OwnerID,
+		StorageKey:  row.StorageKey,
+		FileName:    row.FileName,
+		Description: row.Description.String,
+		ContentType: row.ContentType,
+		SizeBytes:   row.SizeBytes,
+		ExpiresAt:   row.ExpiresAt,
+		CompletedAt: nullTimePtr(row.CompletedAt),
+	}
+}
@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"photo-service/src/interfaces"
+	"photo-service/src/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type WebhookRepo struct {
+	conn *sql.DB
+	db   *database.Queries
+}
+
+func NewWebhookRepo(conn *sql.DB, db *database.Queries) *WebhookRepo {
+	return &WebhookRepo{conn: conn, db: db}
+}
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, request interfaces.CreateWebhookRepoRequest) (interfaces.Webhook, error) {
+	endpoint, err := r.db.CreateWebhookEndpoint(ctx, database.CreateWebhookEndpointParams{
+		OwnerID:  request.OwnerID,
+		ApiKeyID: toNullUUID(request.APIKeyID),
+		Url:      request.URL,
+		Secret:   request.Secret,
+		Events:   request.Events,
+	})
+	if err != nil {
+		log.Printf("Error creating webhook: %v", err)
+		return interfaces.Webhook{}, err
+	}
+	return toWebhook(endpoint), nil
+}
+
+func (r *WebhookRepo) GetWebhook(ctx context.Context, id uuid.UUID) (interfaces.Webhook, error) {
+	endpoint, err := r.db.GetWebhookEndpoint(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.Webhook{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting webhook: %v", err)
+		return interfaces.Webhook{}, err
+	}
+	return toWebhook(endpoint), nil
+}
+
+// ListWebhooks returns the owner's webhooks, newest first.
+func (r *WebhookRepo) ListWebhooks(ctx context.Context, ownerID uuid.UUID, apiKeyID *uuid.UUID) ([]interfaces.Webhook, error) {
+	rows, err := r.db.ListWebhookEndpoints(ctx, database.ListWebhookEndpointsParams{
+		OwnerID:  ownerID,
+		ApiKeyID: toNullUUID(apiKeyID),
+	})
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		return nil, err
+	}
+	return toWebhooks(rows), nil
+}
+
+func (r *WebhookRepo) ListWebhooksForEvent(ctx context.Context, ownerID uuid.UUID, eventType string) ([]interfaces.Webhook, error) {
+	rows, err := r.db.ListWebhookEndpointsForEvent(ctx, database.ListWebhookEndpointsForEventParams{
+		OwnerID:   ownerID,
+		EventType: eventType,
+	})
+	if err != nil {
+		log.Printf("Error listing webhooks for event: %v", err)
+		return nil, err
+	}
+	return toWebhooks(rows), nil
+}
+
+func (r *WebhookRepo) UpdateWebhook(ctx context.Context, request interfaces.UpdateWebhookRepoRequest) error {
+	params := database.UpdateWebhookEndpointParams{ID: request.ID}
+	if request.URL != nil {
+		params.Url = sql.NullString{String: *request.URL, Valid: true}
+	}
+	if request.Events != nil {
+		params.SetEvents = true
+		params.Events = request.Events
+	}
+	if request.Enabled != nil {
+		params.Enabled = sql.NullBool{Bool: *request.Enabled, Valid: true}
+	}
+	updated, err := r.db.UpdateWebhookEndpoint(ctx, params)
+	if err != nil {
+		log.Printf("Error updating webhook: %v", err)
+		return err
+	}
+	if updated == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	deleted, err := r.db.DeleteWebhookEndpoint(ctx, id)
+	if err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		return err
+	}
+	if deleted == 0 {
+		return interfaces.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepo) ResetWebhookFailures(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.ResetWebhookEndpointFailures(ctx, id); err != nil {
+		log.Printf("Error resetting webhook failures: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookRepo) RecordWebhookFailure(ctx context.Context, id uuid.UUID, disableAfter int) (bool, error) {
+	enabled, err := r.db.RecordWebhookEndpointFailure(ctx, database.RecordWebhookEndpointFailureParams{
+		DisableAfter: int32(disableAfter),
+		ID:           id,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error recording webhook failure: %v", err)
+		return false, err
+	}
+	return enabled, nil
+}
+
+// CreateWebhookDelivery logs a delivery and enqueues the job sending it, together.
+func (r *WebhookRepo) CreateWebhookDelivery(ctx context.Context, request interfaces.CreateWebhookDeliveryRepoRequest) (interfaces.WebhookDelivery, error) {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return interfaces.WebhookDelivery{}, err
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	delivery, err := q.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		ID:         request.ID,
+		EndpointID: request.EndpointID,
+		EventID:    request.EventID,
+		EventType:  request.EventType,
+		Payload:    request.Payload,
+	})
+	if err != nil {
+		log.Printf("Error creating webhook delivery: %v", err)
+		return interfaces.WebhookDelivery{}, err
+	}
+	if _, err := enqueueJob(ctx, q, request.Job); err != nil {
+		return interfaces.WebhookDelivery{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing webhook delivery: %v", err)
+		return interfaces.WebhookDelivery{}, err
+	}
+	return toWebhookDelivery(delivery), nil
+}
+
+func (r *WebhookRepo) GetWebhookDelivery(ctx context.Context, id uuid.UUID) (interfaces.WebhookDelivery, error) {
+	delivery, err := r.db.GetWebhookDelivery(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return interfaces.WebhookDelivery{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error getting webhook delivery: %v", err)
+		return interfaces.WebhookDelivery{}, err
+	}
+	return toWebhookDelivery(delivery), nil
+}
+
+// ListWebhookDeliveries returns the webhook's latest deliveries, newest first.
+func (r *WebhookRepo) ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]interfaces.WebhookDelivery, error) {
+	rows, err := r.db.ListWebhookDeliveries(ctx, database.ListWebhookDeliveriesParams{
+		EndpointID: webhookID,
+		Limit:      int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v", err)
+		return nil, err
+	}
+	deliveries := make([]interfaces.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, toWebhookDelivery(row))
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) RecordWebhookAttempt(ctx context.Context, id uuid.UUID, attempt interfaces.WebhookAttempt) error {
+	params := database.RecordWebhookDeliveryAttemptParams{
+		Status:       attempt.Status,
+		ResponseBody: toNullString(attempt.ResponseBody),
+		LastError:    toNullString(attempt.Error),
+		ID:           id,
+	}
+	if attempt.ResponseStatus != 0 {
+		params.ResponseStatus = sql.NullInt32{Int32: int32(attempt.ResponseStatus), Valid: true}
+	}
+	if err := r.db.RecordWebhookDeliveryAttempt(ctx, params); err != nil {
+		log.Printf("Error recording webhook delivery attempt: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookRepo) SetWebhookDeliveryStatus(ctx context.Context, id uuid.UUID, status string, lastError string) error {
+	err := r.db.SetWebhookDeliveryStatus(ctx, database.SetWebhookDeliveryStatusParams{
+		Status:    status,
+		LastError: toNullString(lastError),
+		ID:        id,
+	})
+	if err != nil {
+		log.Printf("Error setting webhook delivery status: %v", err)
+		return err
+	}
+	return nil
+}
+
+func toWebhooks(rows []database.WebhookEndpoint) []interfaces.Webhook {
+	webhooks := make([]interfaces.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhooks = append(webhooks, toWebhook(row))
+	}
+	return webhooks
+}
+
+func toWebhook(row database.WebhookEndpoint) interfaces.Webhook {
+	return interfaces.Webhook{
+		ID:                  row.ID,
+		OwnerID:             row.OwnerID,
+		APIKeyID:            nullUUIDPtr(row.ApiKeyID),
+		URL:                 row.Url,
+		Events:              row.Events,
+		Enabled:             row.Enabled,
+		ConsecutiveFailures: int(row.ConsecutiveFailures),
+		DisabledAt:          nullTimePtr(row.DisabledAt),
+		CreatedAt:           row.CreatedAt,
+		UpdatedAt:           row.UpdatedAt,
+		Secret:              row.Secret,
+	}
+}
+
+func toWebhookDelivery(row database.WebhookDelivery) interfaces.WebhookDelivery {
+	delivery := interfaces.WebhookDelivery{
+		ID:            row.ID,
+		WebhookID:     row.EndpointID,
+		EventID:       row.EventID,
+		EventType:     row.EventType,
+		Payload:       row.Payload,
+		Status:        row.Status,
+		Attempts:      int(row.Attempts),
+		ResponseBody:  row.ResponseBody.String,
+		LastError:     row.LastError.String,
+		LastAttemptAt: nullTimePtr(row.LastAttemptAt),
+		CreatedAt:     row.CreatedAt,
+	}
+	if row.ResponseStatus.Valid {
+		status := int(row.ResponseStatus.Int32)
+		delivery.ResponseStatus = &status
+	}
+	return delivery
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// albumRoleRank orders the roles; each role may do everything the lower ones may.
+var albumRoleRank = map[interfaces.AlbumRole]int{
+	interfaces.AlbumRoleViewer:      1,
+	interfaces.AlbumRoleContributor: 2,
+	interfaces.AlbumRoleEditor:      3,
+	interfaces.AlbumRoleOwner:       4,
+}
+
+// albumActionRole is the lowest role allowed to take each album action.
+var albumActionRole = map[interfaces.AlbumAction]interfaces.AlbumRole{
+	interfaces.AlbumActionView:      interfaces.AlbumRoleViewer,
+	interfaces.AlbumActionAddPhotos: interfaces.AlbumRoleContributor,
+	interfaces.AlbumActionEdit:      interfaces.AlbumRoleEditor,
+	interfaces.AlbumActionManage:    interfaces.AlbumRoleOwner,
+}
+
+// AccessPolicy is the single place deciding who may do what with photos and albums.
+// Owners have full control over their photos and albums. Album members see the album and
+// every photo in it and, depending on their role, may change the album; photos themselves
+// can only be changed by their owner.
+type AccessPolicy struct {
+	photoRepo  interfaces.IPhotoRepository
+	albumRepo  interfaces.IAlbumRepository
+	memberRepo interfaces.IAlbumMemberRepository
+}
+
+func NewAccessPolicy(
+	photoRepo interfaces.IPhotoRepository,
+	albumRepo interfaces.IAlbumRepository,
+	memberRepo interfaces.IAlbumMemberRepository,
+) *AccessPolicy {
+	return &AccessPolicy{photoRepo: photoRepo, albumRepo: albumRepo, memberRepo: memberRepo}
+}
+
+func (p *AccessPolicy) AuthorizeUser(userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("%w: not authenticated", interfaces.ErrUnauthorized)
+	}
+	return nil
+}
+
+// AuthorizePhoto loads a photo the user may act on. Photos the user cannot see are not
+// found, whether they exist or not.
+func (p *AccessPolicy) AuthorizePhoto(ctx context.Context, userID uuid.UUID, photoID uuid.UUID, action interfaces.PhotoAction) (interfaces.PhotoRecord, error) {
+	if err := p.AuthorizeUser(userID); err != nil {
+		return interfaces.PhotoRecord{}, err
+	}
+	photo, err := p.photoRepo.GetPhoto(ctx, photoID)
+	if err != nil {
+		return interfaces.PhotoRecord{}, err
+	}
+	if photo.OwnerID == userID {
+		return photo, nil
+	}
+	shared, err := p.memberRepo.IsPhotoShared(ctx, photoID, userID)
+	if err != nil {
+		return interfaces.PhotoRecord{}, err
+	}
+	if !shared {
+		return interfaces.PhotoRecord{}, interfaces.ErrNotFound
+	}
+	if action != interfaces.PhotoActionView {
+		return interfaces.PhotoRecord{}, fmt.Errorf("%w: only the owner can change this photo", interfaces.ErrForbidden)
+	}
+	return photo, nil
+}
+
+// AuthorizeAlbum loads an album the user may act on, with the user's role. Albums the user
+// is not a member of are not found.
+func (p *AccessPolicy) AuthorizeAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID, action interfaces.AlbumAction) (interfaces.Album, error) {
+	if err := p.AuthorizeUser(userID); err != nil {
+		return interfaces.Album{}, err
+	}
+	required, ok := albumActionRole[action]
+	if !ok {
+		return interfaces.Album{}, fmt.Errorf("unknown album action %q", action)
+	}
+	album, err := p.albumRepo.GetAlbum(ctx, albumID)
+	if err != nil {
+		return interfaces.Album{}, err
+	}
+	album.Role = interfaces.AlbumRoleOwner
+	if album.OwnerID != userID {
+		member, err := p.memberRepo.GetAlbumMember(ctx, albumID, userID)
+		if err != nil {
+			return interfaces.Album{}, err
+		}
+		album.Role = member.Role
+	}
+	if !hasAlbumRole(album.Role, required) {
+		return interfaces.Album{}, fmt.Errorf("%w: the %s role cannot %s this album", interfaces.ErrForbidden, album.Role, albumActionVerb(action))
+	}
+	return album, nil
+}
+
+// hasAlbumRole reports whether role includes everything min may do.
+func hasAlbumRole(role interfaces.AlbumRole, min interfaces.AlbumRole) bool {
+	return albumRoleRank[role] >= albumRoleRank[min]
+}
+
+func albumActionVerb(action interfaces.AlbumAction) string {
+	switch action {
+	case interfaces.AlbumActionAddPhotos:
+		return "add photos to"
+	case interfaces.AlbumActionManage:
+		return "manage"
+	}
+	return string(action)
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+const maxAlbumPhotosPerRequest = 500
+
+type AlbumService struct {
+	repo       interfaces.IAlbumRepository
+	memberRepo interfaces.IAlbumMemberRepository
+	policy     interfaces.IAccessPolicy
+}
+
+func NewAlbumService(
+	repo interfaces.IAlbumRepository,
+	memberRepo interfaces.IAlbumMemberRepository,
+	policy interfaces.IAccessPolicy,
+) *AlbumService {
+	return &AlbumService{repo: repo, memberRepo: memberRepo, policy: policy}
+}
+
+func (s *AlbumService) CreateAlbum(ctx context.Context, request interfaces.CreateAlbumRequest) (interfaces.Album, error) {
+	if err := s.policy.AuthorizeUser(request.UserID); err != nil {
+		return interfaces.Album{}, err
+	}
+	title, err := validateAlbumTitle(request.Title)
+	if err != nil {
+		return interfaces.Album{}, err
+	}
+	if len(request.Description) > 1024 {
+		return interfaces.Album{}, fmt.Errorf("%w: description cannot exceed 1024 characters", interfaces.ErrInvalidArgument)
+	}
+	album, err := s.repo.CreateAlbum(ctx, interfaces.CreateAlbumRepoRequest{
+		OwnerID:     request.UserID,
+		Title:       title,
+		Description: request.Description,
+	})
+	if err != nil {
+		return interfaces.Album{}, err
+	}
+	album.Role = interfaces.AlbumRoleOwner
+	return album, nil
+}
+
+func (s *AlbumService) GetAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) (interfaces.Album, error) {
+	return s.policy.AuthorizeAlbum(ctx, userID, albumID, interfaces.AlbumActionView)
+}
+
+// ListAlbums lists the albums the user owns and those shared with them.
+func (s *AlbumService) ListAlbums(ctx context.Context, userID uuid.UUID) ([]interfaces.Album, error) {
+	if err := s.policy.AuthorizeUser(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAlbums(ctx, userID)
+}
+
+func (s *AlbumService) UpdateAlbum(ctx context.Context, request interfaces.UpdateAlbumRequest) (interfaces.Album, error) {
+	if request.Title == nil && !request.SetDescription {
+		return interfaces.Album{}, fmt.Errorf("%w: nothing to update", interfaces.ErrInvalidArgument)
+	}
+	repoRequest := interfaces.UpdateAlbumRepoRequest{
+		AlbumID:        request.AlbumID,
+		SetDescription: request.SetDescription,
+		Description:    request.Description,
+	}
+	if request.Title != nil {
+		title, err := validateAlbumTitle(*request.Title)
+		if err != nil {
+			return interfaces.Album{}, err
+		}
+		repoRequest.Title = &title
+	}
+	if request.Description != nil && len(*request.Description) > 1024 {
+		return interfaces.Album{}, fmt.Errorf("%w: description cannot exceed 1024 characters", interfaces.ErrInvalidArgument)
+	}
+
+	album, err := s.policy.AuthorizeAlbum(ctx, request.UserID, request.AlbumID, interfaces.AlbumActionEdit)
+	if err != nil {
+		return interfaces.Album{}, err
+	}
+	if err := s.repo.UpdateAlbum(ctx, repoRequest); err != nil {
+		return interfaces.Album{}, err
+	}
+	return s.reloadAlbum(ctx, album)
+}
+
+func (s *AlbumService) DeleteAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) error {
+	if _, err := s.policy.AuthorizeAlbum(ctx, userID, albumID, interfaces.AlbumActionManage); err != nil {
+		return err
+	}
+	return s.repo.DeleteAlbum(ctx, albumID)
+}
+
+// AddPhotos appends the user's photos to the album; photos already in it are left where they are.
+func (s *AlbumService) AddPhotos(ctx context.Context, request interfaces.AlbumPhotosRequest) (interfaces.Album, error) {
+	if err := validatePhotoIDs(request.PhotoIDs); err != nil {
+		return interfaces.Album{}, err
+	}
+	album, err := s.policy.AuthorizeAlbum(ctx, request.UserID, request.AlbumID, interfaces.AlbumActionAddPhotos)
+	if err != nil {
+		return interfaces.Album{}, err
+	}
+	if _, err := s.repo.AddPhotos(ctx, request.AlbumID, request.UserID, request.PhotoIDs); err != nil {
+		return interfaces.Album{}, err
+	}
+	return s.reloadAlbum(ctx, album)
+}
+
+// RemovePhoto takes a photo out of the album. Contributors may only remove their own photos.
+func (s *AlbumService) RemovePhoto(ctx context.Context, userID uuid.UUID, albumID uuid.UUID, photoID uuid.UUID) error {
+	album, err := s.policy.AuthorizeAlbum(ctx, userID, albumID, interfaces.AlbumActionAddPhotos)
+	if err != nil {
+		return err
+	}
+	if !hasAlbumRole(album.Role, interfaces.AlbumRoleEditor) {
+		if _, err := s.policy.AuthorizePhoto(ctx, userID, photoID, interfaces.PhotoActionEdit); err != nil {
+			return err
+		}
+	}
+	return s.repo.RemovePhoto(ctx, albumID, photoID)
+}
+
+// ReorderPhotos replaces the manual order of the album with the given list. Photos in the
+// trash are left out of the list and move behind the others.
+func (s *AlbumService) ReorderPhotos(ctx context.Context, request interfaces.AlbumPhotosRequest) error {
+	if _, err := s.policy.AuthorizeAlbum(ctx, request.UserID, request.AlbumID, interfaces.AlbumActionEdit); err != nil {
+		return err
+	}
+	return s.repo.ReorderPhotos(ctx, request.AlbumID, request.PhotoIDs)
+}
+
+func (s *AlbumService) SetCover(ctx context.Context, userID uuid.UUID, albumID uuid.UUID, photoID uuid.UUID) (interfaces.Album, error) {
+	album, err := s.policy.AuthorizeAlbum(ctx, userID, albumID, interfaces.AlbumActionEdit)
+	if err != nil {
+		return interfaces.Album{}, err
+	}
+	if err := s.repo.SetCover(ctx, albumID, photoID); err != nil {
+		return interfaces.Album{}, err
+	}
+	return s.reloadAlbum(ctx, album)
+}
+
+func (s *AlbumService) ListAlbumPhotos(ctx context.Context, request interfaces.ListAlbumPhotosRequest) ([]interfaces.AlbumPhoto, error) {
+	if _, err := s.policy.AuthorizeAlbum(ctx, request.UserID, request.AlbumID, interfaces.AlbumActionView); err != nil {
+		return nil, err
+	}
+	photos, err := s.repo.ListAlbumPhotos(ctx, request.AlbumID, request.Limit, request.Offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range photos {
+		redactPrivateLocation(request.UserID, &photos[i].Photo)
+	}
+	return photos, nil
+}
+
+// ListMembers lists who the album is shared with; every member can see the others.
+func (s *AlbumService) ListMembers(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) ([]interfaces.AlbumMember, error) {
+	if _, err := s.policy.AuthorizeAlbum(ctx, userID, albumID, interfaces.AlbumActionView); err != nil {
+		return nil, err
+	}
+	return s.memberRepo.ListAlbumMembers(ctx, albumID)
+}
+
+// SetMember shares the album with a user, or changes the role they have.
+func (s *AlbumService) SetMember(ctx context.Context, request interfaces.SetAlbumMemberRequest) (interfaces.AlbumMember, error) {
+	switch request.Role {
+	case interfaces.AlbumRoleViewer, interfaces.AlbumRoleContributor, interfaces.AlbumRoleEditor:
+	default:
+		return interfaces.AlbumMember{}, fmt.Errorf("%w: role must be viewer, contributor or editor", interfaces.ErrInvalidArgument)
+	}
+	if request.MemberID == uuid.Nil {
+		return interfaces.AlbumMember{}, fmt.Errorf("%w: invalid member ID", interfaces.ErrInvalidArgument)
+	}
+	album, err := s.policy.AuthorizeAlbum(ctx, request.UserID, request.AlbumID, interfaces.AlbumActionManage)
+	if err != nil {
+		return interfaces.AlbumMember{}, err
+	}
+	if request.MemberID == album.OwnerID {
+		return interfaces.AlbumMember{}, fmt.Errorf("%w: the owner cannot be a member of their own album", interfaces.ErrInvalidArgument)
+	}
+	return s.memberRepo.SetAlbumMember(ctx, interfaces.SetAlbumMemberRepoRequest{
+		AlbumID: request.AlbumID,
+		UserID:  request.MemberID,
+		Role:    request.Role,
+		AddedBy: request.UserID,
+	})
+}
+
+func (s *AlbumService) RemoveMember(ctx context.Context, userID uuid.UUID, albumID uuid.UUID, memberID uuid.UUID) error {
+	action := interfaces.AlbumActionManage
+	if memberID == userID {
+		action = interfaces.AlbumActionView
+	}
+	if _, err := s.policy.AuthorizeAlbum(ctx, userID, albumID, action); err != nil {
+		return err
+	}
+	return s.memberRepo.RemoveAlbumMember(ctx, albumID, memberID)
+}
+
+// reloadAlbum reads an album back after a change, keeping the caller's role.
+func (s *AlbumService) reloadAlbum(ctx context.Context, album interfaces.Album) (interfaces.Album, error) {
+	updated, err := s.repo.GetAlbum(ctx, album.ID)
+	if err != nil {
+		return interfaces.Album{}, err
+	}
+	updated.Role = album.Role
+	return updated, nil
+}
+
+func validateAlbumTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || len(title) > 255 {
+		return "", fmt.Errorf("%w: title must be between 1 and 255 characters", interfaces.ErrInvalidArgument)
+	}
+	return title, nil
+}
+
+func validatePhotoIDs(photoIDs []uuid.UUID) error {
+	if len(photoIDs) == 0 || len(photoIDs) > maxAlbumPhotosPerRequest {
+		return fmt.Errorf("%w: photo_ids must contain between 1 and %d photos", interfaces.ErrInvalidArgument, maxAlbumPhotosPerRequest)
+	}
+	return nil
+}
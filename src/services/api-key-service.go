@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeyMarker starts every key so leaked keys are easy to recognize in code and logs.
+	apiKeyMarker     = "psk_"
+	apiKeyBytes      = 32
+	apiKeyPrefixSize = 12
+	maxAPIKeyName    = 100
+)
+
+var apiKeyScopes = map[interfaces.Scope]bool{
+	interfaces.ScopePhotosRead:  true,
+	interfaces.ScopePhotosWrite: true,
+	interfaces.ScopeAdmin:       true,
+}
+
+type APIKeyService struct {
+	repo interfaces.IAPIKeyRepository
+}
+
+func NewAPIKeyService(repo interfaces.IAPIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// CreateAPIKey mints a new key. The key itself is only returned here.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, request interfaces.CreateAPIKeyRequest) (interfaces.CreatedAPIKey, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPIKeyName {
+		return interfaces.CreatedAPIKey{}, fmt.Errorf("%w: name must be between 1 and %d characters", interfaces.ErrInvalidArgument, maxAPIKeyName)
+	}
+	if len(request.Scopes) == 0 {
+		return interfaces.CreatedAPIKey{}, fmt.Errorf("%w: a key needs at least one scope", interfaces.ErrInvalidArgument)
+	}
+	seen := make(map[interfaces.Scope]bool, len(request.Scopes))
+	scopes := make([]interfaces.Scope, 0, len(request.Scopes))
+	for _, scope := range request.Scopes {
+		if !apiKeyScopes[scope] {
+			return interfaces.CreatedAPIKey{}, fmt.Errorf("%w: unknown scope %q", interfaces.ErrInvalidArgument, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if request.OwnerID != nil && *request.OwnerID == uuid.Nil {
+		return interfaces.CreatedAPIKey{}, fmt.Errorf("%w: invalid owner ID", interfaces.ErrInvalidArgument)
+	}
+	if request.OwnerID != nil && request.OnBehalfOf {
+		return interfaces.CreatedAPIKey{}, fmt.Errorf("%w: a key bound to an owner cannot act on behalf of other users", interfaces.ErrInvalidArgument)
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return interfaces.CreatedAPIKey{}, fmt.Errorf("%w: expires_at must be in the future", interfaces.ErrInvalidArgument)
+	}
+
+	key, err := newAPIKey()
+	if err != nil {
+		return interfaces.CreatedAPIKey{}, err
+	}
+	created, err := s.repo.CreateAPIKey(ctx, interfaces.CreateAPIKeyRepoRequest{
+		Name:       name,
+		KeyPrefix:  key[:apiKeyPrefixSize],
+		KeyHash:    hashAPIKey(key),
+		Scopes:     scopes,
+		OwnerID:    request.OwnerID,
+		OnBehalfOf: request.OnBehalfOf,
+		CreatedBy:  request.CreatedBy,
+		ExpiresAt:  utcTime(request.ExpiresAt),
+	})
+	if err != nil {
+		return interfaces.CreatedAPIKey{}, err
+	}
+	return interfaces.CreatedAPIKey{APIKey: created, Key: key}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]interfaces.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	return s.repo.RevokeAPIKey(ctx, id)
+}
+
+// VerifyAPIKey resolves a presented key. Unknown, revoked and expired keys get the same
+// answer. Keys bound to an owner act as that user; keys minted to act on behalf of users
+// act as the user the caller names.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, key string) (interfaces.Principal, error) {
+	invalid := fmt.Errorf("%w: invalid API key", interfaces.ErrUnauthorized)
+	if !strings.HasPrefix(key, apiKeyMarker) {
+		return interfaces.Principal{}, invalid
+	}
+	apiKey, err := s.repo.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return interfaces.Principal{}, invalid
+	}
+	if err != nil {
+		return interfaces.Principal{}, err
+	}
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !time.Now().UTC().Before(*apiKey.ExpiresAt)) {
+		return interfaces.Principal{}, invalid
+	}
+	// Usage tracking must not fail the request; the repository logs errors.
+	_ = s.repo.TouchAPIKey(ctx, apiKey.ID)
+
+	principal := interfaces.Principal{APIKeyID: &apiKey.ID, OnBehalfOf: apiKey.OnBehalfOf, Scopes: apiKey.Scopes}
+	if apiKey.OwnerID != nil {
+		principal.UserID = *apiKey.OwnerID
+	}
+	return principal, nil
+}
+
+func newAPIKey() (string, error) {
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("could not generate API key")
+	}
+	return apiKeyMarker + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashAPIKey(key string) []byte {
+	hash := sha256.Sum256([]byte(key))
+	return hash[:]
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// subscriberBuffer is how many events a subscriber may fall behind before its stream
+	// is closed.
+	subscriberBuffer = 64
+	// eventListenRetry is the pause before listening again after the listener failed.
+	eventListenRetry = 5 * time.Second
+	// eventPruneInterval is how often replay buffers drop events older than replayAge.
+	eventPruneInterval = time.Minute
+)
+
+// EventPublishers publishes each event to all of its publishers, with the same ID and
+// time.
+type EventPublishers []interfaces.IEventPublisher
+
+func (p EventPublishers) Publish(ctx context.Context, event interfaces.Event) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EventBroker streams events to connected clients. Events are published through the
+// repository, so every instance receives them, and each instance keeps the latest events
+// of every user for clients that reconnect.
+type EventBroker struct {
+	repo       interfaces.IEventRepository
+	replaySize int
+	replayAge  time.Duration
+
+	mu    sync.Mutex
+	users map[uuid.UUID]*userEvents
+	// evicted holds the dropped sequence of users whose buffer was removed once empty, so
+	// their clients are still told to reset.
+	evicted map[uuid.UUID]int64
+	// horizon is the number of the latest event that may have been missed, while not
+	// listening. Clients that saw only events up to it cannot be caught up.
+	horizon int64
+	latest  int64
+	closed  bool
+}
+
+// userEvents is what the broker keeps for one user.
+type userEvents struct {
+	replay []interfaces.SequencedEvent
+	// dropped is the number of the latest event pushed out of the replay buffer.
+	dropped     int64
+	subscribers map[*eventSubscriber]struct{}
+}
+
+type eventSubscriber struct {
+	events chan interfaces.SequencedEvent
+	closed bool
+}
+
+// NewEventBroker keeps up to replaySize events per user, for up to replayAge.
+func NewEventBroker(repo interfaces.IEventRepository, replaySize int, replayAge time.Duration) *EventBroker {
+	return &EventBroker{
+		repo:       repo,
+		replaySize: max(replaySize, 1),
+		replayAge:  replayAge,
+		users:      map[uuid.UUID]*userEvents{},
+		evicted:    map[uuid.UUID]int64{},
+		horizon:    math.MaxInt64,
+	}
+}
+
+// Publish sends the event to every instance; local subscribers receive it from the
+// listener like everyone else.
+func (b *EventBroker) Publish(ctx context.Context, event interfaces.Event) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	_, err := b.repo.NotifyEvent(ctx, event)
+	return err
+}
+
+func (b *EventBroker) Subscribe(userID uuid.UUID, lastSeq int64) interfaces.EventSubscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscriber := &eventSubscriber{events: make(chan interfaces.SequencedEvent, subscriberBuffer)}
+	subscription := interfaces.EventSubscription{Events: subscriber.events}
+	if b.closed {
+		close(subscriber.events)
+		subscription.Close = func() {}
+		return subscription
+	}
+
+	user := b.user(userID)
+	if lastSeq > 0 && (lastSeq < b.horizon || lastSeq < user.dropped) {
+		subscription.Reset = true
+		subscription.ResetSeq = b.latest
+	} else {
+		for _, event := range user.replay {
+			if event.Seq > lastSeq {
+				subscription.Replay = append(subscription.Replay, event)
+			}
+		}
+	}
+	user.subscribers[subscriber] = struct{}{}
+	subscription.Close = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.unsubscribe(userID, subscriber)
+	}
+	return subscription
+}
+
+// Run listens for published events until ctx ends.
+func (b *EventBroker) Run(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(eventPruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.prune()
+			}
+		}
+	}()
+	for {
+		if err := b.repo.ListenEvents(ctx, b.deliver, b.synced); err != nil {
+			log.Printf("Error listening for events, retrying in %v: %v", eventListenRetry, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventListenRetry):
+		}
+	}
+}
+
+// Close ends every stream, so clients reconnect to another instance.
+func (b *EventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for userID, user := range b.users {
+		for subscriber := range user.subscribers {
+			b.unsubscribe(userID, subscriber)
+		}
+	}
+}
+
+func (b *EventBroker) deliver(event interfaces.SequencedEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.latest = max(b.latest, event.Seq)
+	user := b.user(event.Event.OwnerID)
+	user.replay = append(user.replay, event)
+	if len(user.replay) > b.replaySize {
+		user.dropped = user.replay[0].Seq
+		user.replay = append(user.replay[:0], user.replay[1:]...)
+	}
+	for subscriber := range user.subscribers {
+		select {
+		case subscriber.events <- event:
+		default:
+			b.unsubscribe(event.Event.OwnerID, subscriber)
+		}
+	}
+}
+
+// synced records that events up to seq may have been missed. Open streams may lack
+// some of them, so they are ended and their clients reconnect and catch up.
+func (b *EventBroker) synced(seq int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.horizon = seq
+	b.latest = max(b.latest, seq)
+	for userID, user := range b.users {
+		for subscriber := range user.subscribers {
+			b.unsubscribe(userID, subscriber)
+		}
+	}
+}
+
+// prune drops events older than replayAge. Clients that saw only dropped events are
+// told to reset.
+func (b *EventBroker) prune() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cutoff := time.Now().Add(-b.replayAge)
+	for userID, user := range b.users {
+		kept := 0
+		for kept < len(user.replay) && user.replay[kept].Event.CreatedAt.Before(cutoff) {
+			user.dropped = user.replay[kept].Seq
+			kept++
+		}
+		user.replay = append(user.replay[:0], user.replay[kept:]...)
+		if len(user.replay) == 0 && len(user.subscribers) == 0 {
+			delete(b.users, userID)
+			if user.dropped > 0 {
+				b.evicted[userID] = user.dropped
+			}
+		}
+	}
+}
+
+// user returns what is kept for a user. The caller holds b.mu.
+func (b *EventBroker) user(userID uuid.UUID) *userEvents {
+	user, ok := b.users[userID]
+	if !ok {
+		user = &userEvents{dropped: b.evicted[userID], subscribers: map[*eventSubscriber]struct{}{}}
+		b.users[userID] = user
+		delete(b.evicted, userID)
+	}
+	return user
+}
+
+// unsubscribe ends a stream. The caller holds b.mu.
+func (b *EventBroker) unsubscribe(userID uuid.UUID, subscriber *eventSubscriber) {
+	if subscriber.closed {
+		return
+	}
+	subscriber.closed = true
+	close(subscriber.events)
+	if user, ok := b.users[userID]; ok {
+		delete(user.subscribers, subscriber)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+type GeotagService struct {
+	photoMetadataRepo interfaces.IPhotoMetadataRepository
+}
+
+func NewGeotagService(photoMetadataRepo interfaces.IPhotoMetadataRepository) *GeotagService {
+	return &GeotagService{photoMetadataRepo: photoMetadataRepo}
+}
+
+// GeotagFromGPX matches the user's photos without a location against a GPX track by capture time.
+// Unless DryRun is set, the matched positions are written to photo_metadata.
+func (s *GeotagService) GeotagFromGPX(ctx context.Context, request interfaces.GeotagFromGPXRequest) (interfaces.GeotagResult, error) {
+	if request.MaxGap <= 0 {
+		return interfaces.GeotagResult{}, fmt.Errorf("%w: max gap must be positive", interfaces.ErrInvalidArgument)
+	}
+	track, err := parseGPXTrack(request.GPXData)
+	if err != nil {
+		return interfaces.GeotagResult{}, fmt.Errorf("%w: %v", interfaces.ErrInvalidArgument, err)
+	}
+	if len(track) == 0 {
+		return interfaces.GeotagResult{}, fmt.Errorf("%w: GPX file has no timestamped track points", interfaces.ErrInvalidArgument)
+	}
+
+	photos, err := s.photoMetadataRepo.ListPhotosWithoutLocation(ctx, request.UserID)
+	if err != nil {
+		return interfaces.GeotagResult{}, err
+	}
+
+	result := interfaces.GeotagResult{
+		DryRun:      request.DryRun,
+		TrackPoints: len(track),
+		Matched:     []interfaces.GeotagMatch{},
+		Unmatched:   []uuid.UUID{},
+	}
+	locations := make(map[uuid.UUID]interfaces.PhotoLocation)
+	for _, photo := range photos {
+		t := photo.CapturedAt.Add(request.ClockOffset)
+		point, gap, interpolated, ok := locateOnTrack(track, t, request.MaxGap)
+		if !ok {
+			result.Unmatched = append(result.Unmatched, photo.PhotoID)
+			continue
+		}
+		location := interfaces.PhotoLocation{Latitude: point.latitude, Longitude: point.longitude}
+		locations[photo.PhotoID] = location
+		result.Matched = append(result.Matched, interfaces.GeotagMatch{
+			PhotoID:      photo.PhotoID,
+			CapturedAt:   photo.CapturedAt,
+			Location:     location,
+			GapSeconds:   gap.Seconds(),
+			Interpolated: interpolated,
+		})
+	}
+
+	if request.DryRun || len(locations) == 0 {
+		return result, nil
+	}
+	result.Updated, err = s.photoMetadataRepo.SetPhotoLocations(ctx, interfaces.SetPhotoLocationsRepoRequest{
+		OwnerID:   request.UserID,
+		Locations: locations,
+	})
+	if err != nil {
+		return interfaces.GeotagResult{}, err
+	}
+	log.Printf("Geotagged %d of %d photos from GPX track", result.Updated, len(photos))
+	return result, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type gpxDocument struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time"`
+}
+
+type trackPoint struct {
+	time      time.Time
+	latitude  float64
+	longitude float64
+}
+
+// parseGPXTrack reads every timestamped track point of a GPX file, sorted by time.
+func parseGPXTrack(data []byte) ([]trackPoint, error) {
+	var doc gpxDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid GPX file: %v", err)
+	}
+
+	var points []trackPoint
+	for _, track := range doc.Tracks {
+		for _, segment := range track.Segments {
+			for _, p := range segment.Points {
+				if p.Time == "" {
+					continue
+				}
+				t, err := time.Parse(time.RFC3339, p.Time)
+				if err != nil {
+					return nil, fmt.Errorf("invalid GPX point time %q: %v", p.Time, err)
+				}
+				points = append(points, trackPoint{time: t.UTC(), latitude: p.Lat, longitude: p.Lon})
+			}
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].time.Before(points[j].time)
+	})
+	return points, nil
+}
+
+// locateOnTrack finds the position at time t. Between two points no more than maxGap apart
+// the position is interpolated; otherwise the nearest point within maxGap is used.
+func locateOnTrack(points []trackPoint, t time.Time, maxGap time.Duration) (trackPoint, time.Duration, bool, bool) {
+	if len(points) == 0 {
+		return trackPoint{}, 0, false, false
+	}
+	i := sort.Search(len(points), func(i int) bool {
+		return !points[i].time.Before(t)
+	})
+
+	if i < len(points) && points[i].time.Equal(t) {
+		return points[i], 0, false, true
+	}
+	if i == 0 {
+		gap := points[0].time.Sub(t)
+		return points[0], gap, false, gap <= maxGap
+	}
+	if i == len(points) {
+		last := points[len(points)-1]
+		gap := t.Sub(last.time)
+		return last, gap, false, gap <= maxGap
+	}
+
+	prev, next := points[i-1], points[i]
+	sincePrev, untilNext := t.Sub(prev.time), next.time.Sub(t)
+	gap := min(sincePrev, untilNext)
+	if next.time.Sub(prev.time) <= maxGap {
+		return interpolate(prev, next, t), gap, true, true
+	}
+	if sincePrev <= untilNext {
+		return prev, sincePrev, false, sincePrev <= maxGap
+	}
+	return next, untilNext, false, untilNext <= maxGap
+}
+
+// interpolate places t linearly between two track points.
+func interpolate(prev, next trackPoint, t time.Time) trackPoint {
+	fraction := float64(t.Sub(prev.time)) / float64(next.time.Sub(prev.time))
+	deltaLon := next.longitude - prev.longitude
+	// Take the short way around when the track crosses the antimeridian.
+	if deltaLon > 180 {
+		deltaLon -= 360
+	} else if deltaLon < -180 {
+		deltaLon += 360
+	}
+	longitude := prev.longitude + deltaLon*fraction
+	if longitude > 180 {
+		longitude -= 360
+	} else if longitude < -180 {
+		longitude += 360
+	}
+	return trackPoint{
+		time:      t,
+		latitude:  prev.latitude + (next.latitude-prev.latitude)*fraction,
+		longitude: longitude,
+	}
+}
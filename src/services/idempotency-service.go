@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"photo-service/src/interfaces"
+)
+
+const (
+	// idempotencyWait is how long a duplicate waits for the request holding its key to
+	// finish before it is turned away.
+	idempotencyWait = 10 * time.Second
+	// idempotencyPoll is how often a waiting duplicate checks on that request.
+	idempotencyPoll = 250 * time.Millisecond
+)
+
+// IdempotencyService makes retried requests that carry an Idempotency-Key run once: the
+// first claims the key and stores its response, the others get that response replayed.
+type IdempotencyService struct {
+	repo interfaces.IIdempotencyKeyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyService keeps keys for ttl; after that a key can be used again.
+func NewIdempotencyService(repo interfaces.IIdempotencyKeyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, ttl: ttl}
+}
+
+func (s *IdempotencyService) Begin(ctx context.Context, id interfaces.IdempotencyKeyID, fingerprint string) (*interfaces.StoredResponse, error) {
+	deadline := time.Now().Add(idempotencyWait)
+	for {
+		claimed, err := s.repo.ClaimIdempotencyKey(ctx, id, fingerprint, s.expiredBefore())
+		if err != nil {
+			return nil, err
+		}
+		if claimed {
+			return nil, nil
+		}
+		record, err := s.repo.GetIdempotencyKey(ctx, id)
+		// A key released in the meantime can be claimed on the next attempt.
+		if err != nil && !errors.Is(err, interfaces.ErrNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			if record.Fingerprint != fingerprint {
+				return nil, fmt.Errorf("%w: Idempotency-Key was already used for a different request", interfaces.ErrConflict)
+			}
+			if record.Response != nil {
+				return record.Response, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, interfaces.ErrIdempotencyKeyInUse
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(idempotencyPoll):
+		}
+	}
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, id interfaces.IdempotencyKeyID, response interfaces.StoredResponse) error {
+	return s.repo.CompleteIdempotencyKey(ctx, id, response)
+}
+
+func (s *IdempotencyService) Release(ctx context.Context, id interfaces.IdempotencyKeyID) error {
+	return s.repo.ReleaseIdempotencyKey(ctx, id)
+}
+
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpiredIdempotencyKeys(ctx, s.expiredBefore())
+}
+
+func (s *IdempotencyService) expiredBefore() time.Time {
+	return time.Now().UTC().Add(-s.ttl)
+}
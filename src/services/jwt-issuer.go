@@ -0,0 +1,69 @@
+package services
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// JWTIssuer signs tokens the JWTVerifier accepts. The service itself never issues tokens;
+// this is for tests and local development, where there is no identity provider.
+type JWTIssuer struct {
+	method   jwt.SigningMethod
+	key      interface{}
+	kid      string
+	Issuer   string
+	Audience string
+	// Scopes are extra scopes such as admin, written to the scope claim.
+	Scopes []string
+}
+
+func NewHS256Issuer(secret []byte) *JWTIssuer {
+	return &JWTIssuer{method: jwt.SigningMethodHS256, key: secret}
+}
+
+// NewRS256Issuer signs with an RSA key; kid must match the key's entry in the JWKS the
+// verifier loads, see PublicJWKS.
+func NewRS256Issuer(key *rsa.PrivateKey, kid string) *JWTIssuer {
+	return &JWTIssuer{method: jwt.SigningMethodRS256, key: key, kid: kid}
+}
+
+// IssueToken returns a token for the user that expires after ttl.
+func (i *JWTIssuer) IssueToken(userID uuid.UUID, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Issuer:    i.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Scope: strings.Join(i.Scopes, " "),
+	}
+	if i.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{i.Audience}
+	}
+	token := jwt.NewWithClaims(i.method, claims)
+	if i.kid != "" {
+		token.Header["kid"] = i.kid
+	}
+	return token.SignedString(i.key)
+}
+
+// PublicJWKS returns a JWKS document holding the public half of an RS256 issuer's key.
+func PublicJWKS(key *rsa.PublicKey, kid string) ([]byte, error) {
+	return json.Marshal(jsonWebKeySet{Keys: []jsonWebKey{{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: jwt.SigningMethodRS256.Alg(),
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+}
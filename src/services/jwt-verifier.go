@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"photo-service/src/interfaces"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	jwtLeeway = 30 * time.Second
+	// jwksRefreshInterval throttles refetching a JWKS URL when a token names an unknown key.
+	jwksRefreshInterval = time.Minute
+	jwksFetchTimeout    = 10 * time.Second
+	maxJWKSSize         = 1 << 20
+)
+
+// JWTConfig selects the keys accepted by the JWT verifier. HS256 tokens are checked with
+// the shared secret and RS256 tokens with the RSA keys of a JWKS document, read from a
+// local file or fetched from a URL. At least one of them must be set.
+type JWTConfig struct {
+	HS256Secret []byte
+	JWKSFile    string
+	JWKSURL     string
+	// Issuer and Audience are required in every token when set.
+	Issuer   string
+	Audience string
+}
+
+// jwtClaims are the claims read from a token. Scope is the OAuth space-separated list; it
+// only matters for extra scopes such as admin, every user may read and write their photos.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope,omitempty"`
+}
+
+type JWTVerifier struct {
+	secret  []byte
+	jwks    *jwksKeySet
+	options []jwt.ParserOption
+}
+
+func NewJWTVerifier(ctx context.Context, config JWTConfig) (*JWTVerifier, error) {
+	if len(config.HS256Secret) == 0 && config.JWKSFile == "" && config.JWKSURL == "" {
+		return nil, errors.New("no JWT signing keys configured")
+	}
+	if config.JWKSFile != "" && config.JWKSURL != "" {
+		return nil, errors.New("load the JWKS from either a file or a URL, not both")
+	}
+
+	verifier := &JWTVerifier{secret: config.HS256Secret}
+	methods := []string{}
+	if len(config.HS256Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if config.JWKSFile != "" || config.JWKSURL != "" {
+		verifier.jwks = &jwksKeySet{file: config.JWKSFile, url: config.JWKSURL}
+		if err := verifier.jwks.load(ctx); err != nil {
+			return nil, err
+		}
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	verifier.options = []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if config.Issuer != "" {
+		verifier.options = append(verifier.options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		verifier.options = append(verifier.options, jwt.WithAudience(config.Audience))
+	}
+	return verifier, nil
+}
+
+// VerifyToken validates the signature and claims of a JWT and returns the user named by its
+// subject.
+func (v *JWTVerifier) VerifyToken(ctx context.Context, token string) (interfaces.Principal, error) {
+	var claims jwtClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		return v.signingKey(ctx, token)
+	}, v.options...)
+	if err != nil {
+		return interfaces.Principal{}, fmt.Errorf("%w: invalid token", interfaces.ErrUnauthorized)
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil || userID == uuid.Nil {
+		return interfaces.Principal{}, fmt.Errorf("%w: token subject is not a user ID", interfaces.ErrUnauthorized)
+	}
+	principal := interfaces.Principal{
+		UserID: userID,
+		Scopes: []interfaces.Scope{interfaces.ScopePhotosRead, interfaces.ScopePhotosWrite},
+	}
+	for _, scope := range strings.Fields(claims.Scope) {
+		if interfaces.Scope(scope) == interfaces.ScopeAdmin {
+			principal.Scopes = append(principal.Scopes, interfaces.ScopeAdmin)
+		}
+	}
+	return principal, nil
+}
+
+// signingKey picks the key for a token by its algorithm, so a token can never be checked
+// with a key meant for another algorithm.
+func (v *JWTVerifier) signingKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		return v.jwks.key(ctx, kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// jwksKeySet holds the RSA keys of a JWKS document by key ID. Keys loaded from a URL are
+// refetched when a token names a key that is not known yet, to pick up rotations.
+type jwksKeySet struct {
+	file string
+	url  string
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	lastFetched time.Time
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (s *jwksKeySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	stale := s.url != "" && time.Since(s.lastFetched) > jwksRefreshInterval
+	s.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if stale {
+		if err := s.load(ctx); err != nil {
+			log.Printf("Error refreshing JWKS: %v", err)
+		}
+		s.mu.RLock()
+		key, ok = s.keys[kid]
+		s.mu.RUnlock()
+		if ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (s *jwksKeySet) load(ctx context.Context) error {
+	var data []byte
+	var err error
+	if s.file != "" {
+		data, err = os.ReadFile(s.file)
+	} else {
+		data, err = fetchJWKS(ctx, s.url)
+	}
+	if err != nil {
+		return fmt.Errorf("could not load JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.keys = keys
+	s.lastFetched = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func fetchJWKS(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", url, res.Status)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, maxJWKSSize))
+}
+
+// parseJWKS reads the RSA signing keys of a JWKS document; other key types are skipped.
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Alg != "" && jwk.Alg != jwt.SigningMethodRS256.Alg()) {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid JWKS key %q: bad exponent", jwk.Kid)
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no RSA signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	dublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+	photoshopAPP13      = "Photoshop 3.0\x00"
+)
+
+// extractIPTCKeywords reads the IPTC-IIM keywords (dataset 2:25) from the Photoshop APP13
+// segment of a JPEG file.
+func extractIPTCKeywords(fileBytes []byte) []string {
+	if len(fileBytes) < 4 || fileBytes[0] != 0xFF || fileBytes[1] != 0xD8 {
+		return nil
+	}
+	var keywords []string
+	pos := 2
+	for pos+4 <= len(fileBytes) && fileBytes[pos] == 0xFF {
+		marker := fileBytes[pos+1]
+		// Start of scan: the metadata segments are all before the image data.
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(fileBytes[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(fileBytes) {
+			break
+		}
+		segment := fileBytes[pos+4 : pos+2+length]
+		if marker == 0xED && bytes.HasPrefix(segment, []byte(photoshopAPP13)) {
+			keywords = append(keywords, parsePhotoshopResources(segment[len(photoshopAPP13):])...)
+		}
+		pos += 2 + length
+	}
+	return keywords
+}
+
+// parsePhotoshopResources walks the 8BIM image resource blocks and decodes the IPTC one (0x0404).
+func parsePhotoshopResources(data []byte) []string {
+	var keywords []string
+	pos := 0
+	for pos+12 <= len(data) && bytes.Equal(data[pos:pos+4], []byte("8BIM")) {
+		resourceID := binary.BigEndian.Uint16(data[pos+4 : pos+6])
+		// The resource name is a Pascal string padded to an even length.
+		nameLength := int(data[pos+6])
+		nameEnd := pos + 7 + nameLength
+		if (1+nameLength)%2 != 0 {
+			nameEnd++
+		}
+		if nameEnd+4 > len(data) {
+			break
+		}
+		size := int(binary.BigEndian.Uint32(data[nameEnd : nameEnd+4]))
+		start := nameEnd + 4
+		if size < 0 || start+size > len(data) {
+			break
+		}
+		if resourceID == 0x0404 {
+			keywords = append(keywords, parseIPTCRecords(data[start:start+size])...)
+		}
+		pos = start + size
+		if size%2 != 0 {
+			pos++
+		}
+	}
+	return keywords
+}
+
+func parseIPTCRecords(data []byte) []string {
+	var keywords []string
+	pos := 0
+	for pos+5 <= len(data) && data[pos] == 0x1C {
+		record, dataset := data[pos+1], data[pos+2]
+		size := int(binary.BigEndian.Uint16(data[pos+3 : pos+5]))
+		// Extended-length datasets are never used for keywords.
+		if size&0x8000 != 0 || pos+5+size > len(data) {
+			break
+		}
+		if record == 2 && dataset == 25 {
+			keywords = append(keywords, decodeIPTCString(data[pos+5:pos+5+size]))
+		}
+		pos += 5 + size
+	}
+	return keywords
+}
+
+// decodeIPTCString treats the value as UTF-8 and falls back to Latin-1, the IIM default.
+func decodeIPTCString(value []byte) string {
+	if utf8.Valid(value) {
+		return string(value)
+	}
+	runes := make([]rune, len(value))
+	for i, b := range value {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// extractXMPKeywords reads dc:subject from an embedded XMP packet. The packet is plain XML
+// in JPEG, PNG, TIFF and HEIF files alike, so it is located by searching for it.
+func extractXMPKeywords(fileBytes []byte) []string {
+	start := bytes.Index(fileBytes, []byte("<x:xmpmeta"))
+	if start < 0 {
+		return nil
+	}
+	end := bytes.Index(fileBytes[start:], []byte("</x:xmpmeta>"))
+	if end < 0 {
+		return nil
+	}
+	packet := fileBytes[start : start+end+len("</x:xmpmeta>")]
+
+	var keywords []string
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+	inSubject, inItem := false, false
+	var item strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space == dublinCoreNamespace && t.Name.Local == "subject" {
+				inSubject = true
+			} else if inSubject && t.Name.Local == "li" {
+				inItem = true
+				item.Reset()
+			}
+		case xml.CharData:
+			if inItem {
+				item.Write(t)
+			}
+		case xml.EndElement:
+			if t.Name.Space == dublinCoreNamespace && t.Name.Local == "subject" {
+				inSubject = false
+			} else if inItem && t.Name.Local == "li" {
+				inItem = false
+				keywords = append(keywords, item.String())
+			}
+		}
+	}
+	return keywords
+}
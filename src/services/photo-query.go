@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPhotoQueryLimit    = 50
+	maxPhotoQueryLimit        = 200
+	maxPhotoQueryRadiusMeters = 50000
+)
+
+var photoFormats = map[string]bool{
+	"jpeg": true,
+	"png":  true,
+	"gif":  true,
+	"webp": true,
+	"heic": true,
+	"tiff": true,
+}
+
+// QueryPhotos runs a photo query document over the user's photos, one page at a time.
+func (s *PhotoService) QueryPhotos(ctx context.Context, request interfaces.QueryPhotosRequest) (interfaces.PhotoQueryPage, error) {
+	if err := s.policy.AuthorizeUser(request.UserID); err != nil {
+		return interfaces.PhotoQueryPage{}, err
+	}
+	return queryPhotoPage(ctx, s.repo, request.UserID, request.Query)
+}
+
+// queryPhotoPage compiles and runs a photo query, returning one page and the cursor of the next.
+func queryPhotoPage(ctx context.Context, repo interfaces.IPhotoRepository, userID uuid.UUID, query interfaces.PhotoQuery) (interfaces.PhotoQueryPage, error) {
+	repoRequest, err := compilePhotoQuery(userID, query)
+	if err != nil {
+		return interfaces.PhotoQueryPage{}, err
+	}
+	// Fetch one extra photo to know whether there is a next page.
+	repoRequest.Limit++
+	photos, err := repo.QueryPhotos(ctx, repoRequest)
+	if err != nil {
+		return interfaces.PhotoQueryPage{}, err
+	}
+
+	page := interfaces.PhotoQueryPage{Photos: photos}
+	if len(photos) == repoRequest.Limit {
+		page.Photos = photos[:len(photos)-1]
+		page.NextCursor = encodePhotoCursor(repoRequest.Sort, page.Photos[len(page.Photos)-1])
+	}
+	return page, nil
+}
+
+// compilePhotoQuery validates a query document and turns it into a repository request.
+// Only filters the photo indexes can serve are accepted: text goes through the full-text
+// index, camera names and formats are matched exactly and geo filters must be a
+// bounding box that does not cross the antimeridian or a radius of at most 50km.
+func compilePhotoQuery(userID uuid.UUID, query interfaces.PhotoQuery) (interfaces.PhotoQueryRepoRequest, error) {
+	request := interfaces.PhotoQueryRepoRequest{
+		OwnerID:     userID,
+		HasLocation: query.HasLocation,
+		Limit:       query.Limit,
+		Sort:        query.Sort,
+	}
+
+	if query.Text != "" {
+		tsQuery, err := buildTSQuery(query.Text)
+		if err != nil {
+			return request, err
+		}
+		request.TSQuery = tsQuery
+	}
+
+	if query.Tags != nil {
+		names, err := normalizeTags(query.Tags.Names)
+		if err != nil {
+			return request, err
+		}
+		request.TagNames = lowerTagNames(names)
+		switch query.Tags.Match {
+		case "", "all":
+			request.TagMatchAll = true
+		case "any":
+		default:
+			return request, fmt.Errorf("%w: tags.match must be all or any", interfaces.ErrInvalidArgument)
+		}
+	}
+
+	if query.CapturedAt != nil {
+		if err := validateTimeRange("captured_at", query.CapturedAt); err != nil {
+			return request, err
+		}
+		request.CapturedFrom, request.CapturedTo = utcTime(query.CapturedAt.From), utcTime(query.CapturedAt.To)
+	}
+	if query.UploadedAt != nil {
+		if err := validateTimeRange("uploaded_at", query.UploadedAt); err != nil {
+			return request, err
+		}
+		request.UploadedFrom, request.UploadedTo = utcTime(query.UploadedAt.From), utcTime(query.UploadedAt.To)
+	}
+
+	if query.BBox != nil && query.Near != nil {
+		return request, fmt.Errorf("%w: use either bbox or near, not both", interfaces.ErrInvalidArgument)
+	}
+	if (query.BBox != nil || query.Near != nil) && query.HasLocation != nil && !*query.HasLocation {
+		return request, fmt.Errorf("%w: geo filters only match photos with a location", interfaces.ErrInvalidArgument)
+	}
+	if box := query.BBox; box != nil {
+		if err := validateBoundingBox(*box); err != nil {
+			return request, err
+		}
+		request.BBox = box
+	}
+	if near := query.Near; near != nil {
+		if err := validateLocation(interfaces.PhotoLocation{Latitude: near.Latitude, Longitude: near.Longitude}); err != nil {
+			return request, err
+		}
+		if near.RadiusMeters <= 0 || near.RadiusMeters > maxPhotoQueryRadiusMeters {
+			return request, fmt.Errorf("%w: near.radius_m must be greater than 0 and at most %d", interfaces.ErrInvalidArgument, maxPhotoQueryRadiusMeters)
+		}
+		request.Near = near
+	}
+
+	if query.Camera != nil {
+		cameraMake, err := cameraFilterValue("camera.make", query.Camera.Make)
+		if err != nil {
+			return request, err
+		}
+		cameraModel, err := cameraFilterValue("camera.model", query.Camera.Model)
+		if err != nil {
+			return request, err
+		}
+		if cameraMake == "" && cameraModel == "" {
+			return request, fmt.Errorf("%w: camera needs a make or a model", interfaces.ErrInvalidArgument)
+		}
+		request.CameraMake, request.CameraModel = cameraMake, cameraModel
+	}
+
+	seen := make(map[string]bool, len(query.Formats))
+	for _, format := range query.Formats {
+		format = strings.ToLower(format)
+		if !photoFormats[format] {
+			return request, fmt.Errorf("%w: unsupported format %q", interfaces.ErrInvalidArgument, format)
+		}
+		if !seen[format] {
+			seen[format] = true
+			request.Formats = append(request.Formats, format)
+		}
+	}
+
+	switch request.Sort {
+	case "":
+		request.Sort = interfaces.PhotoSortUploadedDesc
+		if request.TSQuery != "" {
+			request.Sort = interfaces.PhotoSortRelevance
+		}
+	case interfaces.PhotoSortRelevance:
+		if request.TSQuery == "" {
+			return request, fmt.Errorf("%w: sort relevance needs a text filter", interfaces.ErrInvalidArgument)
+		}
+	case interfaces.PhotoSortUploadedDesc, interfaces.PhotoSortUploadedAsc,
+		interfaces.PhotoSortCapturedDesc, interfaces.PhotoSortCapturedAsc:
+	default:
+		return request, fmt.Errorf("%w: unknown sort %q", interfaces.ErrInvalidArgument, request.Sort)
+	}
+
+	if request.Limit == 0 {
+		request.Limit = defaultPhotoQueryLimit
+	}
+	if request.Limit < 1 || request.Limit > maxPhotoQueryLimit {
+		return request, fmt.Errorf("%w: limit must be between 1 and %d", interfaces.ErrInvalidArgument, maxPhotoQueryLimit)
+	}
+
+	if query.Cursor != "" {
+		after, err := decodePhotoCursor(query.Cursor, request.Sort)
+		if err != nil {
+			return request, err
+		}
+		request.After = &after
+	}
+	return request, nil
+}
+
+func validateTimeRange(name string, r *interfaces.TimeRange) error {
+	if r.From != nil && r.To != nil && !r.From.Before(*r.To) {
+		return fmt.Errorf("%w: %s.from must be before %s.to", interfaces.ErrInvalidArgument, name, name)
+	}
+	return nil
+}
+
+// utcTime converts a time for comparison with the UTC timestamp columns.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func validateBoundingBox(box interfaces.BoundingBox) error {
+	for _, corner := range []interfaces.PhotoLocation{
+		{Latitude: box.MinLatitude, Longitude: box.MinLongitude},
+		{Latitude: box.MaxLatitude, Longitude: box.MaxLongitude},
+	} {
+		if err := validateLocation(corner); err != nil {
+			return err
+		}
+	}
+	if box.MinLatitude >= box.MaxLatitude {
+		return fmt.Errorf("%w: bbox min_latitude must be below max_latitude", interfaces.ErrInvalidArgument)
+	}
+	if box.MinLongitude >= box.MaxLongitude {
+		return fmt.Errorf("%w: bbox min_longitude must be below max_longitude; boxes crossing the antimeridian are not supported", interfaces.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// cameraFilterValue rejects patterns: camera names are looked up by exact value.
+func cameraFilterValue(name, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.ContainsAny(value, "%*?") {
+		return "", fmt.Errorf("%w: %s is matched exactly; wildcards are not supported", interfaces.ErrInvalidArgument, name)
+	}
+	if utf8.RuneCountInString(value) > maxCameraNameLength {
+		return "", fmt.Errorf("%w: %s must be at most %d characters", interfaces.ErrInvalidArgument, name, maxCameraNameLength)
+	}
+	return value, nil
+}
+
+func lowerTagNames(names []string) []string {
+	lower := make([]string, len(names))
+	for i, name := range names {
+		lower[i] = strings.ToLower(name)
+	}
+	return lower
+}
+
+// encodePhotoCursor builds the opaque cursor pointing after the given photo.
+func encodePhotoCursor(sort string, photo interfaces.QueriedPhoto) string {
+	cursor := interfaces.PhotoCursor{Sort: sort, ID: photo.ID}
+	switch sort {
+	case interfaces.PhotoSortRelevance:
+		rank := photo.Rank
+		cursor.Rank = &rank
+	case interfaces.PhotoSortCapturedDesc, interfaces.PhotoSortCapturedAsc:
+		cursor.Time = photo.CapturedAt
+	default:
+		createdAt := photo.CreatedAt
+		cursor.Time = &createdAt
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePhotoCursor reads a cursor, which is only valid for the sort order it was made with.
+func decodePhotoCursor(encoded string, sort string) (interfaces.PhotoCursor, error) {
+	var cursor interfaces.PhotoCursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.ID == uuid.Nil {
+		return cursor, fmt.Errorf("%w: invalid cursor", interfaces.ErrInvalidArgument)
+	}
+	if cursor.Sort != sort {
+		return cursor, fmt.Errorf("%w: cursor was issued for sort %q", interfaces.ErrInvalidArgument, cursor.Sort)
+	}
+	if (sort == interfaces.PhotoSortRelevance) != (cursor.Rank != nil) || (sort != interfaces.PhotoSortRelevance) != (cursor.Time != nil) {
+		return cursor, fmt.Errorf("%w: invalid cursor", interfaces.ErrInvalidArgument)
+	}
+	return cursor, nil
+}
@@ -2,32 +2,82 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"path/filepath"
 	"photo-service/src/interfaces"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/dsoprea/go-exif/v3"
 	"github.com/google/uuid"
 )
 
+const (
+	// metadataHeaderBytes is how much of a stored file is read for its metadata. EXIF,
+	// IPTC and XMP blocks sit near the start of the file.
+	metadataHeaderBytes = 512 << 10
+	// processPhotoAttempts is how often extracting a photo's metadata is tried before the
+	// photo is marked failed.
+	processPhotoAttempts = 5
+	// purgeBatchSize is how many trashed photos one purge transaction deletes.
+	purgeBatchSize = 100
+)
+
 type PhotoService struct {
 	repo                interfaces.IPhotoRepository
 	fileUploaderService interfaces.IFileUpload
+	fileReader          interfaces.IFileReader
 	photoMetadataRepo   interfaces.IPhotoMetadataRepository
+	tagRepo             interfaces.ITagRepository
+	policy              interfaces.IAccessPolicy
+	quota               interfaces.IStorageQuotaService
+	events              interfaces.IEventPublisher
+	// trashRetention is how long deleted photos stay in the trash before they are purged.
+	trashRetention time.Duration
 }
 
 func NewPhotoService(
 	repo interfaces.IPhotoRepository,
 	fileUploaderService interfaces.IFileUpload,
+	fileReader interfaces.IFileReader,
 	photoMetadataRepo interfaces.IPhotoMetadataRepository,
+	tagRepo interfaces.ITagRepository,
+	policy interfaces.IAccessPolicy,
+	quota interfaces.IStorageQuotaService,
+	events interfaces.IEventPublisher,
+	trashRetention time.Duration,
 ) *PhotoService {
-	return &PhotoService{repo: repo, fileUploaderService: fileUploaderService, photoMetadataRepo: photoMetadataRepo}
+	return &PhotoService{
+		repo:                repo,
+		fileUploaderService: fileUploaderService,
+		fileReader:          fileReader,
+		photoMetadataRepo:   photoMetadataRepo,
+		tagRepo:             tagRepo,
+		policy:              policy,
+		quota:               quota,
+		events:              events,
+		trashRetention:      trashRetention,
+	}
 }
 
 func (s *PhotoService) CreatePhoto(ctx context.Context, request interfaces.CreatePhotoRequest) (string, error) {
+	if err := s.policy.AuthorizeUser(request.UserID); err != nil {
+		return "", err
+	}
+	// Reject uploads over quota before storing the file; the repository checks again
+	// atomically in case concurrent uploads got there first.
+	quota, err := s.quota.CheckUpload(ctx, request.UserID, int64(len(request.FileData)))
+	if err != nil {
+		return "", err
+	}
 	uniqueId := uuid.New()
 	uploadRequest := interfaces.UploadFileRequest{
 		UserID:   request.UserID.String(),
@@ -40,55 +90,501 @@ func (s *PhotoService) CreatePhoto(ctx context.Context, request interfaces.Creat
 		log.Printf("Error uploading file to S3: %v", err)
 		return "", err
 	}
-	req := interfaces.CreatePhotoRepoRequest{
+	photoId, err := s.createPhotoFromFile(ctx, interfaces.CreateStoredPhotoRequest{
+		PhotoID:     uniqueId,
 		UserID:      request.UserID,
 		Description: request.Description,
+		FileName:    request.FileName,
+		FileData:    request.FileData,
+		SizeBytes:   int64(len(request.FileData)),
 		URL:         url,
+	}, quota)
+	if err != nil {
+		if removeErr := s.fileUploaderService.Delete(ctx, url); removeErr != nil {
+			log.Printf("Error removing file of failed upload: %v", removeErr)
+		}
+		return "", err
+	}
+	return photoId, nil
+}
+
+// CreateStoredPhoto creates a photo from a file that is already stored at request.URL, such
+// as an assembled resumable upload. The file is left in place when this fails.
+func (s *PhotoService) CreateStoredPhoto(ctx context.Context, request interfaces.CreateStoredPhotoRequest) (string, error) {
+	if err := s.policy.AuthorizeUser(request.UserID); err != nil {
+		return "", err
+	}
+	if request.PhotoID == uuid.Nil {
+		request.PhotoID = uuid.New()
+	}
+	if request.SizeBytes == 0 {
+		request.SizeBytes = int64(len(request.FileData))
+	}
+	quota, err := s.quota.CheckUpload(ctx, request.UserID, request.SizeBytes)
+	if err != nil {
+		return "", err
+	}
+	return s.createPhotoFromFile(ctx, request, quota)
+}
+
+// createPhotoFromFile creates the photo row for a stored file, counted against quota, and
+// queues the extraction of its metadata. The photo is processing until that has run.
+func (s *PhotoService) createPhotoFromFile(ctx context.Context, request interfaces.CreateStoredPhotoRequest, quota interfaces.StorageQuota) (string, error) {
+	sizeBytes := request.SizeBytes
+	job, err := processPhotoJob(request.PhotoID)
+	if err != nil {
+		return "", err
+	}
+	req := interfaces.CreatePhotoRepoRequest{
+		ID:          request.PhotoID,
+		UserID:      request.UserID,
+		Description: request.Description,
+		URL:         request.URL,
+		Format:      detectPhotoFormat(request.FileName, request.FileData),
+		SizeBytes:   sizeBytes,
+		Quota:       quota,
+		Status:      interfaces.PhotoStatusProcessing,
+		Jobs:        []interfaces.NewJob{job},
+	}
+	// Upload intents only pass the head of the file along; processing hashes those files
+	// from storage, so a later replace with the same bytes is still recognized.
+	if int64(len(request.FileData)) == sizeBytes {
+		req.SHA256 = fileSHA256(request.FileData)
 	}
 	photoId, err := s.repo.CreatePhoto(ctx, req)
+	if errors.Is(err, interfaces.ErrQuotaExceeded) {
+		return "", fmt.Errorf("%w: uploading %s would exceed the quota", err, formatBytes(sizeBytes))
+	}
 	if err != nil {
 		return "", err
 	}
-	lat, long, time, err2 := extractExifData(request.FileData)
-	if err2 != nil {
-		log.Printf("Error extracting EXIF data: %v", err2)
+	s.publish(ctx, interfaces.EventPhotoCreated, request.UserID, request.PhotoID, interfaces.PhotoStatusProcessing)
+	return photoId, nil
+}
+
+// processPhotoJob is the job extracting the metadata of a photo.
+func processPhotoJob(photoID uuid.UUID) (interfaces.NewJob, error) {
+	payload, err := json.Marshal(interfaces.ProcessPhotoPayload{PhotoID: photoID.String()})
+	if err != nil {
+		return interfaces.NewJob{}, err
 	}
-	if lat != 0 || long != 0 {
-		log.Printf("EXIF data found. Creating photo metadata... lat %v, long %v, time %v", lat, long, time)
-		photoUUID, err3 := uuid.Parse(photoId)
-		if err3 != nil {
-			log.Printf("Error parsing photo UUID: %v", err3)
+	return interfaces.NewJob{
+		Kind:        interfaces.JobKindProcessPhoto,
+		Payload:     payload,
+		MaxAttempts: processPhotoAttempts,
+	}, nil
+}
+
+// ProcessPhoto imports the embedded keywords and EXIF metadata of a new photo and marks it
+// ready. Both steps are safe to repeat, so a failed run can simply be retried. Photos
+// deleted in the meantime are skipped; restoring them queues processing again.
+func (s *PhotoService) ProcessPhoto(ctx context.Context, photoID uuid.UUID) error {
+	photo, err := s.repo.GetPhoto(ctx, photoID)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fileData, err := s.fileReader.GetObjectHead(ctx, photo.URL, metadataHeaderBytes)
+	if err != nil {
+		return fmt.Errorf("reading file of photo %s: %w", photoID, err)
+	}
+	if photo.CurrentSHA256 == "" {
+		if err := s.hashPhotoFile(ctx, photo); err != nil {
+			return err
 		}
+	}
+	s.importEmbeddedKeywords(ctx, photo.OwnerID, photoID.String(), fileData)
+	exifData, err := extractExifData(fileData)
+	if err != nil {
+		log.Printf("Error extracting EXIF data: %v", err)
+	}
+	lat, long, time := exifData.Latitude, exifData.Longitude, exifData.CreatedAt
+	hasLocation := lat != 0 || long != 0
+	hasCamera := exifData.CameraMake != "" || exifData.CameraModel != ""
+	// Keep the capture time even without GPS so the photo can be geotagged later.
+	metadata := interfaces.PhotoVersionMetadata{CameraMake: exifData.CameraMake, CameraModel: exifData.CameraModel}
+	if hasLocation {
+		metadata.Location = &interfaces.PhotoLocation{Latitude: lat, Longitude: long}
+		metadata.Altitude = exifData.Altitude
+	}
+	if !time.IsZero() {
+		metadata.CapturedAt = &time
+	}
+	if err := s.repo.SetPhotoVersionMetadata(ctx, photoID, photo.CurrentVersion, metadata); err != nil {
+		return err
+	}
+	if hasLocation || !time.IsZero() || hasCamera {
+		log.Printf("EXIF data found. Creating photo metadata... lat %v, long %v, time %v", lat, long, time)
 		req := interfaces.CreatePhotoMetadataRepoRequest{
-			Id:        photoUUID,
-			Latitude:  &lat,
-			Longitude: &long,
-			CreatedAt: &time,
+			Id:          photoID,
+			CameraMake:  exifData.CameraMake,
+			CameraModel: exifData.CameraModel,
+			Source:      interfaces.MetadataSourceExif,
 		}
-		_, err = s.photoMetadataRepo.CreatePhotoMetadata(ctx, req)
-		if err != nil {
+		if hasLocation {
+			req.Latitude = &lat
+			req.Longitude = &long
+			req.Altitude = exifData.Altitude
+		}
+		if !time.IsZero() {
+			req.CreatedAt = &time
+		}
+		if _, err := s.photoMetadataRepo.CreatePhotoMetadata(ctx, req); err != nil {
 			log.Printf("Error creating photo metadata: %v", err)
-			return "", err
+			return err
 		}
 	}
-	return photoId, err
+	if err := s.repo.SetPhotoStatus(ctx, photoID, interfaces.PhotoStatusReady); err != nil {
+		return err
+	}
+	s.publish(ctx, interfaces.EventPhotoProcessed, photo.OwnerID, photoID, interfaces.PhotoStatusReady)
+	return nil
+}
+
+// hashPhotoFile records the digest of a current file that was stored without one.
+func (s *PhotoService) hashPhotoFile(ctx context.Context, photo interfaces.PhotoRecord) error {
+	sum, err := s.fileReader.HashObject(ctx, photo.URL)
+	if err != nil {
+		return fmt.Errorf("hashing file of photo %s: %w", photo.ID, err)
+	}
+	return s.repo.SetPhotoVersionSHA256(ctx, photo.ID, photo.CurrentVersion, sum)
+}
+
+func (s *PhotoService) FailPhotoProcessing(ctx context.Context, photoID uuid.UUID) error {
+	photo, err := s.repo.GetPhoto(ctx, photoID)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetPhotoStatus(ctx, photoID, interfaces.PhotoStatusFailed); err != nil {
+		return err
+	}
+	s.publish(ctx, interfaces.EventPhotoProcessed, photo.OwnerID, photoID, interfaces.PhotoStatusFailed)
+	return nil
+}
+
+// publish announces a change to a photo. Events are best effort: a failure is logged and
+// does not fail the change.
+func (s *PhotoService) publish(ctx context.Context, eventType string, ownerID uuid.UUID, photoID uuid.UUID, status string) {
+	err := s.events.Publish(ctx, interfaces.Event{
+		Type:    eventType,
+		OwnerID: ownerID,
+		Data:    interfaces.PhotoEventData{PhotoID: photoID, Status: status},
+	})
+	if err != nil {
+		log.Printf("Error publishing %s event of photo %s: %v", eventType, photoID, err)
+	}
+}
+
+// ProcessPhotoJobs handles JobKindProcessPhoto jobs with s.
+func ProcessPhotoJobs(s interfaces.IPhotoService) interfaces.JobHandler {
+	photoID := func(job interfaces.Job) (uuid.UUID, error) {
+		var payload interfaces.ProcessPhotoPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return uuid.Nil, err
+		}
+		return uuid.Parse(payload.PhotoID)
+	}
+	return interfaces.JobHandler{
+		Run: func(ctx context.Context, job interfaces.Job) error {
+			id, err := photoID(job)
+			if err != nil {
+				return err
+			}
+			return s.ProcessPhoto(ctx, id)
+		},
+		Dead: func(ctx context.Context, job interfaces.Job, _ error) {
+			id, err := photoID(job)
+			if err != nil {
+				return
+			}
+			if err := s.FailPhotoProcessing(ctx, id); err != nil {
+				log.Printf("Error marking photo %s failed: %v", id, err)
+			}
+		},
+	}
+}
+
+// importEmbeddedKeywords tags a new photo with its IPTC and XMP keywords. Failures are
+// logged and do not fail processing.
+func (s *PhotoService) importEmbeddedKeywords(ctx context.Context, userID uuid.UUID, photoId string, fileData []byte) {
+	photoUUID, err := uuid.Parse(photoId)
+	if err != nil {
+		log.Printf("Error parsing photo UUID: %v", err)
+		return
+	}
+	sources := []struct {
+		source   string
+		keywords []string
+	}{
+		{interfaces.TagSourceIPTC, extractIPTCKeywords(fileData)},
+		{interfaces.TagSourceXMP, extractXMPKeywords(fileData)},
+	}
+	for _, source := range sources {
+		names := embeddedKeywordTags(source.keywords)
+		if len(names) == 0 {
+			continue
+		}
+		err := s.tagRepo.AddPhotoTags(ctx, interfaces.AddPhotoTagsRepoRequest{
+			PhotoID: photoUUID,
+			OwnerID: userID,
+			Names:   names,
+			Source:  source.source,
+		})
+		if err != nil {
+			log.Printf("Error importing %s keywords: %v", source.source, err)
+		}
+	}
+}
+
+// ListPhotos lists the user's photos, newest first, optionally filtered by tags.
+func (s *PhotoService) ListPhotos(ctx context.Context, request interfaces.ListPhotosRequest) ([]interfaces.Photo, error) {
+	if err := s.policy.AuthorizeUser(request.UserID); err != nil {
+		return nil, err
+	}
+	if len(request.Tags) == 0 {
+		return s.repo.ListPhotos(ctx, request.UserID, request.Limit, request.Offset)
+	}
+	names, err := normalizeTags(request.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return s.tagRepo.ListPhotosByTags(ctx, interfaces.ListPhotosByTagsRepoRequest{
+		OwnerID:  request.UserID,
+		Names:    names,
+		MatchAll: request.MatchAll,
+		Limit:    request.Limit,
+		Offset:   request.Offset,
+	})
+}
+
+// SearchPhotos runs a full-text search over the user's photo descriptions.
+func (s *PhotoService) SearchPhotos(ctx context.Context, request interfaces.SearchPhotosRequest) ([]interfaces.PhotoSearchResult, error) {
+	if err := s.policy.AuthorizeUser(request.UserID); err != nil {
+		return nil, err
+	}
+	tsQuery, err := buildTSQuery(request.Query)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.SearchPhotos(ctx, request.UserID, tsQuery, request.Limit, request.Offset)
+}
+
+// GetPhoto returns a photo the viewer owns or that is in an album shared with them.
+func (s *PhotoService) GetPhoto(ctx context.Context, viewerID uuid.UUID, photoID uuid.UUID) (interfaces.Photo, error) {
+	if _, err := s.policy.AuthorizePhoto(ctx, viewerID, photoID, interfaces.PhotoActionView); err != nil {
+		return interfaces.Photo{}, err
+	}
+	photo, err := s.repo.GetPhotoDetail(ctx, photoID)
+	if err != nil {
+		return interfaces.Photo{}, err
+	}
+	redactPrivateLocation(viewerID, &photo)
+	return photo, nil
+}
+
+// DeletePhoto moves one of the user's photos to the trash.
+func (s *PhotoService) DeletePhoto(ctx context.Context, userID uuid.UUID, photoID uuid.UUID) error {
+	photo, err := s.policy.AuthorizePhoto(ctx, userID, photoID, interfaces.PhotoActionEdit)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.TrashPhoto(ctx, photoID); err != nil {
+		return err
+	}
+	s.publish(ctx, interfaces.EventPhotoDeleted, photo.OwnerID, photoID, "")
+	return nil
+}
+
+// ListTrash lists the user's deleted photos, most recently deleted first.
+func (s *PhotoService) ListTrash(ctx context.Context, request interfaces.ListTrashRequest) ([]interfaces.TrashedPhoto, error) {
+	if err := s.policy.AuthorizeUser(request.UserID); err != nil {
+		return nil, err
+	}
+	photos, err := s.repo.ListTrashedPhotos(ctx, request.UserID, request.Limit, request.Offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range photos {
+		photos[i].PurgeAt = photos[i].DeletedAt.Add(s.trashRetention)
+	}
+	return photos, nil
+}
+
+// RestorePhoto takes one of the user's photos out of the trash.
+func (s *PhotoService) RestorePhoto(ctx context.Context, userID uuid.UUID, photoID uuid.UUID) error {
+	if err := s.policy.AuthorizeUser(userID); err != nil {
+		return err
+	}
+	job, err := processPhotoJob(photoID)
+	if err != nil {
+		return err
+	}
+	err = s.repo.RestorePhoto(ctx, interfaces.RestorePhotoRepoRequest{
+		ID:        photoID,
+		OwnerID:   userID,
+		Reprocess: []interfaces.NewJob{job},
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, interfaces.EventPhotoRestored, userID, photoID, "")
+	return nil
+}
+
+func (s *PhotoService) EmptyTrash(ctx context.Context, userID uuid.UUID) (int, error) {
+	if err := s.policy.AuthorizeUser(userID); err != nil {
+		return 0, err
+	}
+	return s.purge(ctx, interfaces.PurgePhotosRepoRequest{OwnerID: &userID})
+}
+
+func (s *PhotoService) PurgeTrash(ctx context.Context) (int, error) {
+	deletedBefore := time.Now().UTC().Add(-s.trashRetention)
+	return s.purge(ctx, interfaces.PurgePhotosRepoRequest{DeletedBefore: &deletedBefore})
+}
+
+// purge deletes the selected trashed photos with their stored files, a batch at a time.
+func (s *PhotoService) purge(ctx context.Context, request interfaces.PurgePhotosRepoRequest) (int, error) {
+	request.Limit = purgeBatchSize
+	total := 0
+	for {
+		purged, err := s.repo.PurgePhotos(ctx, request)
+		if err != nil {
+			return total, err
+		}
+		for _, photo := range purged {
+			// The photo is gone either way; a file left behind only costs storage.
+			for _, file := range photo.Files {
+				if err := s.fileUploaderService.Delete(ctx, file); err != nil {
+					log.Printf("Error removing file of purged photo %s: %v", photo.ID, err)
+				}
+			}
+			s.publish(ctx, interfaces.EventPhotoPurged, photo.OwnerID, photo.ID, "")
+		}
+		total += len(purged)
+		if len(purged) < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// GetNearbyPhotos lists the viewer's photos taken close to the given photo, ordered by distance.
+func (s *PhotoService) GetNearbyPhotos(ctx context.Context, request interfaces.GetNearbyPhotosRequest) ([]interfaces.NearbyPhoto, error) {
+	photo, err := s.policy.AuthorizePhoto(ctx, request.ViewerID, request.PhotoID, interfaces.PhotoActionView)
+	if err != nil {
+		return nil, err
+	}
+	if photo.OwnerID != request.ViewerID && photo.HasLocation {
+		// Distances to a location hidden in a private zone would give it away.
+		detail, err := s.repo.GetPhotoDetail(ctx, request.PhotoID)
+		if err != nil {
+			return nil, err
+		}
+		photo.HasLocation = !detail.LocationPrivate
+	}
+	if !photo.HasLocation {
+		return nil, fmt.Errorf("%w: photo has no location", interfaces.ErrInvalidArgument)
+	}
+	if request.TimeWindow != nil && photo.CapturedAt == nil {
+		return nil, fmt.Errorf("%w: photo has no capture time", interfaces.ErrInvalidArgument)
+	}
+	photos, err := s.photoMetadataRepo.ListNearbyPhotos(ctx, interfaces.ListNearbyPhotosRepoRequest{
+		PhotoID:      request.PhotoID,
+		ViewerID:     request.ViewerID,
+		RadiusMeters: request.RadiusMeters,
+		TimeWindow:   request.TimeWindow,
+		Limit:        request.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range photos {
+		redactPrivateLocation(request.ViewerID, &photos[i].Photo)
+	}
+	return photos, nil
+}
+
+// redactPrivateLocation hides a location inside the owner's private zones from everyone else.
+func redactPrivateLocation(viewerID uuid.UUID, photo *interfaces.Photo) {
+	if photo.LocationPrivate && photo.OwnerID != viewerID {
+		photo.Location = nil
+	}
+}
+
+// UpdatePhotoMetadata lets the owner set or clear a photo's location and capture time by hand.
+func (s *PhotoService) UpdatePhotoMetadata(ctx context.Context, request interfaces.UpdatePhotoMetadataRequest) (interfaces.PhotoMetadata, error) {
+	if !request.SetLocation && !request.SetCapturedAt {
+		return interfaces.PhotoMetadata{}, fmt.Errorf("%w: nothing to update", interfaces.ErrInvalidArgument)
+	}
+	if request.SetLocation && request.Location != nil {
+		if err := validateLocation(*request.Location); err != nil {
+			return interfaces.PhotoMetadata{}, err
+		}
+	}
+
+	if _, err := s.policy.AuthorizePhoto(ctx, request.UserID, request.PhotoID, interfaces.PhotoActionEdit); err != nil {
+		return interfaces.PhotoMetadata{}, err
+	}
+
+	err := s.photoMetadataRepo.UpdatePhotoMetadataManually(ctx, interfaces.UpdatePhotoMetadataRepoRequest{
+		PhotoID:       request.PhotoID,
+		SetLocation:   request.SetLocation,
+		Location:      request.Location,
+		SetCapturedAt: request.SetCapturedAt,
+		CapturedAt:    request.CapturedAt,
+	})
+	if err != nil {
+		return interfaces.PhotoMetadata{}, err
+	}
+	return s.photoMetadataRepo.GetPhotoMetadata(ctx, request.PhotoID)
+}
+
+func validateLocation(location interfaces.PhotoLocation) error {
+	if location.Latitude < -90 || location.Latitude > 90 {
+		return fmt.Errorf("%w: latitude must be between -90 and 90", interfaces.ErrInvalidArgument)
+	}
+	if location.Longitude < -180 || location.Longitude > 180 {
+		return fmt.Errorf("%w: longitude must be between -180 and 180", interfaces.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// maxCameraNameLength matches the photo_metadata camera columns.
+const maxCameraNameLength = 64
+
+// exifData holds the EXIF values the service stores; zero values mean the tag was missing.
+type exifData struct {
+	Latitude    float64
+	Longitude   float64
+	Altitude    *float64
+	CreatedAt   time.Time
+	CameraMake  string
+	CameraModel string
 }
 
 // Extract EXIF data from the image file bytes
-func extractExifData(fileBytes []byte) (float64, float64, time.Time, error) {
+func extractExifData(fileBytes []byte) (exifData, error) {
 	var latitude, longitude float64
+	var altitude *float64
 	var createdAt time.Time
+	var cameraMake, cameraModel string
 	var latSign = 1
 	var longSign = 1
+	var altitudeSign = 1.0
 
 	rawExif, err := exif.SearchAndExtractExif(fileBytes)
 	if err != nil {
 		if err.Error() == "no exif data" {
 			log.Printf("No EXIF data found in the image")
-			return latitude, longitude, createdAt, err
+			return exifData{}, err
 		}
 		log.Printf("Error extracting EXIF: %v", err)
-		return latitude, longitude, createdAt, fmt.Errorf("error extracting EXIF: %v", err)
+		return exifData{}, fmt.Errorf("error extracting EXIF: %v", err)
 	}
 
 	log.Printf("EXIF data found. Parsing...")
@@ -96,7 +592,7 @@ func extractExifData(fileBytes []byte) (float64, float64, time.Time, error) {
 	entries, _, err := exif.GetFlatExifDataUniversalSearch(rawExif, nil, true)
 	if err != nil {
 		log.Printf("Error getting flat EXIF data: %v", err)
-		return latitude, longitude, createdAt, fmt.Errorf("error getting flat EXIF data: %v", err)
+		return exifData{}, fmt.Errorf("error getting flat EXIF data: %v", err)
 	}
 
 	for _, entry := range entries {
@@ -116,6 +612,10 @@ func extractExifData(fileBytes []byte) (float64, float64, time.Time, error) {
 			if err != nil {
 				log.Printf("Error parsing creation date: %v", err)
 			}
+		case "Make":
+			cameraMake = exifText(entry.Value)
+		case "Model":
+			cameraModel = exifText(entry.Value)
 		case "GPSLongitudeRef":
 			if entry.Value == "W" {
 				longSign = -1
@@ -124,6 +624,18 @@ func extractExifData(fileBytes []byte) (float64, float64, time.Time, error) {
 			if entry.Value == "S" {
 				latSign = -1
 			}
+		case "GPSAltitude":
+			value, err := parseFraction(strings.Trim(entry.Formatted, "[]"))
+			if err != nil {
+				log.Printf("Error parsing altitude: %v", err)
+			} else {
+				altitude = &value
+			}
+		case "GPSAltitudeRef":
+			// 1 means the altitude is below sea level.
+			if ref, ok := entry.Value.([]byte); ok && len(ref) > 0 && ref[0] == 1 {
+				altitudeSign = -1
+			}
 		}
 	}
 
@@ -136,7 +648,58 @@ func extractExifData(fileBytes []byte) (float64, float64, time.Time, error) {
 
 	latitude *= float64(latSign)
 	longitude *= float64(longSign)
-	return latitude, longitude, createdAt, nil
+	if altitude != nil {
+		*altitude *= altitudeSign
+	}
+	return exifData{
+		Latitude:    latitude,
+		Longitude:   longitude,
+		Altitude:    altitude,
+		CreatedAt:   createdAt,
+		CameraMake:  cameraMake,
+		CameraModel: cameraModel,
+	}, nil
+}
+
+// exifText cleans an ASCII EXIF value, which cameras often pad with NULs and spaces.
+func exifText(value interface{}) string {
+	text, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	text = strings.TrimSpace(strings.Trim(text, "\x00"))
+	if utf8.RuneCountInString(text) > maxCameraNameLength {
+		text = string([]rune(text)[:maxCameraNameLength])
+	}
+	return text
+}
+
+// fileSHA256 is the hex SHA-256 digest of a file.
+func fileSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// detectPhotoFormat identifies the image format from its content, falling back to the
+// file extension for formats the content sniffer does not know (HEIC, TIFF).
+func detectPhotoFormat(fileName string, data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return "jpeg"
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	}
+	switch strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), ".")) {
+	case "heic", "heif":
+		return "heic"
+	case "tif", "tiff":
+		return "tiff"
+	}
+	return ""
 }
 
 // Helper function to parse GPS coordinates
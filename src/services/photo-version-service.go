@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// PhotoVersionService replaces photo files while keeping the earlier ones. Without bucket
+// versioning every version is stored under a key of its own; with it, new versions
+// overwrite the photo's key and are told apart by their object versions.
+type PhotoVersionService struct {
+	repo     interfaces.IPhotoVersionRepository
+	files    interfaces.IFileUpload
+	versions interfaces.IVersionedFileStorage
+	policy   interfaces.IAccessPolicy
+	quota    interfaces.IStorageQuotaService
+	events   interfaces.IEventPublisher
+}
+
+func NewPhotoVersionService(
+	repo interfaces.IPhotoVersionRepository,
+	files interfaces.IFileUpload,
+	versions interfaces.IVersionedFileStorage,
+	policy interfaces.IAccessPolicy,
+	quota interfaces.IStorageQuotaService,
+	events interfaces.IEventPublisher,
+) *PhotoVersionService {
+	return &PhotoVersionService{
+		repo:     repo,
+		files:    files,
+		versions: versions,
+		policy:   policy,
+		quota:    quota,
+		events:   events,
+	}
+}
+
+func (s *PhotoVersionService) ReplacePhotoFile(ctx context.Context, request interfaces.ReplacePhotoFileRequest) (interfaces.PhotoVersion, error) {
+	if len(request.FileData) == 0 {
+		return interfaces.PhotoVersion{}, fmt.Errorf("%w: file is empty", interfaces.ErrInvalidArgument)
+	}
+	photo, err := s.policy.AuthorizePhoto(ctx, request.UserID, request.PhotoID, interfaces.PhotoActionEdit)
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	current, err := s.repo.GetPhotoVersion(ctx, photo.ID, photo.CurrentVersion)
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	// Files stored without a digest get one while processing; until then the same bytes
+	// are stored again as a new version.
+	sum := fileSHA256(request.FileData)
+	if current.SHA256 == sum {
+		return current, nil
+	}
+	sizeBytes := int64(len(request.FileData))
+	quota, err := s.quota.CheckVersionUpload(ctx, photo.OwnerID, sizeBytes)
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	job, err := processPhotoJob(photo.ID)
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+
+	key, storageVersionID, err := s.store(ctx, photo, current, request)
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	version, err := s.repo.CreatePhotoVersion(ctx, interfaces.CreatePhotoVersionRepoRequest{
+		PhotoID:          photo.ID,
+		StorageKey:       key,
+		StorageVersionID: storageVersionID,
+		SHA256:           sum,
+		SizeBytes:        sizeBytes,
+		Format:           detectPhotoFormat(request.FileName, request.FileData),
+		Quota:            quota,
+		Jobs:             []interfaces.NewJob{job},
+	})
+	if err != nil {
+		s.discard(ctx, key, storageVersionID)
+		if errors.Is(err, interfaces.ErrQuotaExceeded) {
+			return interfaces.PhotoVersion{}, fmt.Errorf("%w: uploading %s would exceed the quota", err, formatBytes(sizeBytes))
+		}
+		return interfaces.PhotoVersion{}, err
+	}
+	s.publish(ctx, photo.OwnerID, photo.ID, version.Version)
+	return version, nil
+}
+
+// store saves the file of a new version and returns where it is.
+func (s *PhotoVersionService) store(ctx context.Context, photo interfaces.PhotoRecord, current interfaces.PhotoVersion, request interfaces.ReplacePhotoFileRequest) (string, string, error) {
+	if !s.versions.Versioned() {
+		key, err := s.files.Upload(ctx, interfaces.UploadFileRequest{
+			UserID:   photo.OwnerID.String(),
+			Id:       uuid.New().String(),
+			FileName: request.FileName,
+			FileData: request.FileData,
+		})
+		if err != nil {
+			log.Printf("Error uploading file to S3: %v", err)
+			return "", "", err
+		}
+		return key, "", nil
+	}
+	// Files stored as a photo was created have no known object version yet; record it
+	// before it stops being the latest one.
+	if current.StorageVersionID == "" && current.StorageKey == photo.URL {
+		versionID, err := s.versions.LatestVersionID(ctx, photo.URL)
+		if err != nil {
+			return "", "", err
+		}
+		if err := s.repo.SetStorageVersionID(ctx, photo.ID, current.Version, versionID); err != nil {
+			return "", "", err
+		}
+	}
+	versionID, err := s.versions.PutVersion(ctx, photo.URL, request.FileName, request.FileData)
+	if err != nil {
+		return "", "", err
+	}
+	return photo.URL, versionID, nil
+}
+
+// discard removes the file of a version that was not created. Overwriting a key is undone
+// by deleting the new object version, which brings back the previous one.
+func (s *PhotoVersionService) discard(ctx context.Context, key string, storageVersionID string) {
+	var err error
+	if storageVersionID != "" {
+		err = s.versions.DeleteVersion(ctx, key, storageVersionID)
+	} else {
+		err = s.files.Delete(ctx, key)
+	}
+	if err != nil {
+		log.Printf("Error removing file of failed photo version: %v", err)
+	}
+}
+
+func (s *PhotoVersionService) ListVersions(ctx context.Context, userID uuid.UUID, photoID uuid.UUID) ([]interfaces.PhotoVersion, error) {
+	// Earlier versions may show locations the owner has since hidden.
+	if _, err := s.policy.AuthorizePhoto(ctx, userID, photoID, interfaces.PhotoActionEdit); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPhotoVersions(ctx, photoID)
+}
+
+// RollbackPhoto makes an earlier version current without adding a new one. A file kept as
+// an object version is copied back over the photo's key, so its URL stays the same.
+func (s *PhotoVersionService) RollbackPhoto(ctx context.Context, userID uuid.UUID, photoID uuid.UUID, version int) (interfaces.PhotoVersion, error) {
+	photo, err := s.policy.AuthorizePhoto(ctx, userID, photoID, interfaces.PhotoActionEdit)
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	target, err := s.repo.GetPhotoVersion(ctx, photo.ID, version)
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	if target.Current {
+		return target, nil
+	}
+	job, err := processPhotoJob(photo.ID)
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	key := target.StorageKey
+	if target.StorageVersionID != "" {
+		if err := s.versions.RestoreVersion(ctx, photo.URL, target.StorageKey, target.StorageVersionID); err != nil {
+			return interfaces.PhotoVersion{}, err
+		}
+		key = photo.URL
+	}
+	err = s.repo.SetCurrentVersion(ctx, interfaces.SetCurrentVersionRepoRequest{
+		PhotoID:    photo.ID,
+		Version:    target.Version,
+		StorageKey: key,
+		Jobs:       []interfaces.NewJob{job},
+	})
+	if err != nil {
+		return interfaces.PhotoVersion{}, err
+	}
+	target.Current = true
+	s.publish(ctx, photo.OwnerID, photo.ID, target.Version)
+	return target, nil
+}
+
+// publish announces that another version became current. Events are best effort.
+func (s *PhotoVersionService) publish(ctx context.Context, ownerID uuid.UUID, photoID uuid.UUID, version int) {
+	err := s.events.Publish(ctx, interfaces.Event{
+		Type:    interfaces.EventPhotoUpdated,
+		OwnerID: ownerID,
+		Data: interfaces.PhotoEventData{
+			PhotoID: photoID,
+			Status:  interfaces.PhotoStatusProcessing,
+			Version: version,
+		},
+	})
+	if err != nil {
+		log.Printf("Error publishing %s event of photo %s: %v", interfaces.EventPhotoUpdated, photoID, err)
+	}
+}
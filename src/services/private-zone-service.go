@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+const maxPrivateZoneRadiusMeters = 50000
+
+type PrivateZoneService struct {
+	repo interfaces.IPrivateZoneRepository
+}
+
+func NewPrivateZoneService(repo interfaces.IPrivateZoneRepository) *PrivateZoneService {
+	return &PrivateZoneService{repo: repo}
+}
+
+func (s *PrivateZoneService) CreatePrivateZone(ctx context.Context, request interfaces.SavePrivateZoneRequest) (interfaces.PrivateZone, error) {
+	repoRequest, err := buildPrivateZoneRepoRequest(request)
+	if err != nil {
+		return interfaces.PrivateZone{}, err
+	}
+	id, err := s.repo.CreatePrivateZone(ctx, repoRequest)
+	if err != nil {
+		return interfaces.PrivateZone{}, err
+	}
+	return s.repo.GetPrivateZone(ctx, id)
+}
+
+func (s *PrivateZoneService) GetPrivateZone(ctx context.Context, userID uuid.UUID, zoneID uuid.UUID) (interfaces.PrivateZone, error) {
+	zone, err := s.repo.GetPrivateZone(ctx, zoneID)
+	if err != nil {
+		return interfaces.PrivateZone{}, err
+	}
+	if zone.OwnerID != userID {
+		return interfaces.PrivateZone{}, interfaces.ErrNotFound
+	}
+	return zone, nil
+}
+
+func (s *PrivateZoneService) ListPrivateZones(ctx context.Context, userID uuid.UUID) ([]interfaces.PrivateZone, error) {
+	return s.repo.ListPrivateZones(ctx, userID)
+}
+
+func (s *PrivateZoneService) UpdatePrivateZone(ctx context.Context, request interfaces.SavePrivateZoneRequest) (interfaces.PrivateZone, error) {
+	repoRequest, err := buildPrivateZoneRepoRequest(request)
+	if err != nil {
+		return interfaces.PrivateZone{}, err
+	}
+	repoRequest.ID = request.ZoneID
+	if err := s.repo.UpdatePrivateZone(ctx, repoRequest); err != nil {
+		return interfaces.PrivateZone{}, err
+	}
+	return s.repo.GetPrivateZone(ctx, request.ZoneID)
+}
+
+func (s *PrivateZoneService) DeletePrivateZone(ctx context.Context, userID uuid.UUID, zoneID uuid.UUID) error {
+	return s.repo.DeletePrivateZone(ctx, zoneID, userID)
+}
+
+// buildPrivateZoneRepoRequest validates a zone definition and turns polygons into GeoJSON.
+func buildPrivateZoneRepoRequest(request interfaces.SavePrivateZoneRequest) (interfaces.SavePrivateZoneRepoRequest, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || len(name) > 255 {
+		return interfaces.SavePrivateZoneRepoRequest{}, fmt.Errorf("%w: name must be between 1 and 255 characters", interfaces.ErrInvalidArgument)
+	}
+	repoRequest := interfaces.SavePrivateZoneRepoRequest{OwnerID: request.UserID, Name: name}
+
+	isCircle := request.Center != nil || request.RadiusMeters != nil
+	if isCircle == (len(request.Polygon) > 0) {
+		return interfaces.SavePrivateZoneRepoRequest{}, fmt.Errorf("%w: a zone needs either a center and radius or a polygon", interfaces.ErrInvalidArgument)
+	}
+
+	if isCircle {
+		if request.Center == nil || request.RadiusMeters == nil {
+			return interfaces.SavePrivateZoneRepoRequest{}, fmt.Errorf("%w: a circular zone needs both a center and a radius", interfaces.ErrInvalidArgument)
+		}
+		if err := validateLocation(*request.Center); err != nil {
+			return interfaces.SavePrivateZoneRepoRequest{}, err
+		}
+		if *request.RadiusMeters <= 0 || *request.RadiusMeters > maxPrivateZoneRadiusMeters {
+			return interfaces.SavePrivateZoneRepoRequest{}, fmt.Errorf("%w: radius must be greater than 0 and at most %d meters", interfaces.ErrInvalidArgument, maxPrivateZoneRadiusMeters)
+		}
+		repoRequest.Kind = interfaces.PrivateZoneKindCircle
+		repoRequest.Center = request.Center
+		repoRequest.RadiusMeters = request.RadiusMeters
+		return repoRequest, nil
+	}
+
+	polygon, err := polygonGeoJSON(request.Polygon)
+	if err != nil {
+		return interfaces.SavePrivateZoneRepoRequest{}, err
+	}
+	repoRequest.Kind = interfaces.PrivateZoneKindPolygon
+	repoRequest.PolygonGeoJSON = polygon
+	return repoRequest, nil
+}
+
+// polygonGeoJSON builds a closed GeoJSON polygon ring from the given vertices.
+func polygonGeoJSON(points []interfaces.PhotoLocation) (string, error) {
+	ring := make([][2]float64, 0, len(points)+1)
+	for _, point := range points {
+		if err := validateLocation(point); err != nil {
+			return "", err
+		}
+		ring = append(ring, [2]float64{point.Longitude, point.Latitude})
+	}
+	if ring[0] != ring[len(ring)-1] {
+		ring = append(ring, ring[0])
+	}
+	if len(ring) < 4 {
+		return "", fmt.Errorf("%w: a polygon needs at least three distinct points", interfaces.ErrInvalidArgument)
+	}
+	geometry, err := json.Marshal(map[string]interface{}{
+		"type":        "Polygon",
+		"coordinates": [][][2]float64{ring},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(geometry), nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"photo-service/src/interfaces"
+)
+
+// rateLimitSweepInterval is how often idle buckets are dropped from memory.
+const rateLimitSweepInterval = time.Minute
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	limit   interfaces.RateLimit
+}
+
+// MemoryRateLimitStore keeps token buckets in process memory. Every instance of the
+// service limits on its own; use RedisRateLimitStore to share the limits.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSwept time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*tokenBucket{}, lastSwept: time.Now(), now: time.Now}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit interfaces.RateLimit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSwept) >= rateLimitSweepInterval {
+		s.sweep(now)
+	}
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = bucket
+	}
+	bucket.limit = limit
+	bucket.refill(now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0, nil
+	}
+	return false, tokenWait(bucket.tokens, limit.Rate), nil
+}
+
+// sweep drops buckets that have refilled completely; they are recreated full on demand.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, bucket := range s.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= float64(bucket.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSwept = now
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.updated = now
+	}
+}
+
+// tokenWait is how long a bucket holding tokens takes to refill to one token.
+func tokenWait(tokens float64, rate float64) time.Duration {
+	if rate <= 0 {
+		return time.Hour
+	}
+	return time.Duration(math.Ceil((1 - tokens) / rate * float64(time.Second)))
+}
+
+// InFlightLimiter counts a resource held by the requests of this process, such as the
+// upload bytes being buffered. It is deliberately not shared between instances: it
+// protects the memory of the process that holds the bytes.
+type InFlightLimiter struct {
+	mu    sync.Mutex
+	used  int64
+	limit int64
+}
+
+func NewInFlightLimiter(limit int64) *InFlightLimiter {
+	return &InFlightLimiter{limit: limit}
+}
+
+// Acquire takes n if that stays within the limit. A single request larger than the whole
+// limit is let through when nothing else is in flight, so it can still be served.
+func (l *InFlightLimiter) Acquire(n int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.used > 0 && l.used+n > l.limit {
+		return false
+	}
+	l.used += n
+	return true
+}
+
+func (l *InFlightLimiter) Release(n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= n
+	if l.used < 0 {
+		l.used = 0
+	}
+}
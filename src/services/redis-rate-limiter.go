@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"photo-service/src/interfaces"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes from a bucket stored as a hash, using the server's
+// clock so every instance sees the same time. It returns whether a token was taken and
+// otherwise the milliseconds until one is available. Idle buckets expire once they would
+// be full again.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+if now > updated then
+	tokens = math.min(burst, tokens + (now - updated) / 1000 * rate)
+end
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, wait}
+`)
+
+// RedisRateLimitStore keeps token buckets in Redis, or any server speaking its protocol
+// with Lua scripting, so all instances of the service share the limits.
+type RedisRateLimitStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+func NewRedisRateLimitStore(client redis.Scripter) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client, prefix: "ratelimit:"}
+}
+
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, limit interfaces.RateLimit) (bool, time.Duration, error) {
+	if limit.Rate <= 0 {
+		return false, 0, fmt.Errorf("rate limit %q has no refill rate", key)
+	}
+	result, err := tokenBucketScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Rate, limit.Burst).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("could not take rate limit token: %w", err)
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit reply %v", result)
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
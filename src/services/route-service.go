@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"photo-service/src/interfaces"
+)
+
+const (
+	maxRouteRange            = 366 * 24 * time.Hour
+	placeClusterRadiusMeters = 250
+)
+
+type RouteService struct {
+	repo interfaces.IRouteRepository
+}
+
+func NewRouteService(repo interfaces.IRouteRepository) *RouteService {
+	return &RouteService{repo: repo}
+}
+
+// GetRoutes reconstructs the user's movements from geotagged photos ordered by capture time.
+func (s *RouteService) GetRoutes(ctx context.Context, request interfaces.GetRoutesRequest) (interfaces.RouteCollection, error) {
+	if !request.To.After(request.From) {
+		return interfaces.RouteCollection{}, fmt.Errorf("%w: to must be after from", interfaces.ErrInvalidArgument)
+	}
+	if request.To.Sub(request.From) > maxRouteRange {
+		return interfaces.RouteCollection{}, fmt.Errorf("%w: the range cannot exceed 366 days", interfaces.ErrInvalidArgument)
+	}
+
+	repoRequest := interfaces.ListRoutesRepoRequest{
+		OwnerID: request.UserID,
+		From:    request.From,
+		To:      request.To,
+		PerTrip: request.PerTrip,
+	}
+	segments, err := s.repo.ListRouteSegments(ctx, repoRequest)
+	if err != nil {
+		return interfaces.RouteCollection{}, err
+	}
+	places, err := s.repo.ListRoutePlaces(ctx, repoRequest, placeClusterRadiusMeters)
+	if err != nil {
+		return interfaces.RouteCollection{}, err
+	}
+	placesByDay := make(map[string][]interfaces.RoutePlace)
+	for _, place := range places {
+		day := place.Day.Format("2006-01-02")
+		placesByDay[day] = append(placesByDay[day], place.Place)
+	}
+
+	collection := interfaces.RouteCollection{Type: "FeatureCollection", Features: []interfaces.RouteFeature{}}
+	for _, segment := range segments {
+		geometry, err := lineStringGeoJSON(segment.LineGeoJSON)
+		if err != nil {
+			return interfaces.RouteCollection{}, err
+		}
+		day := segment.Day.Format("2006-01-02")
+		dayPlaces := placesByDay[day]
+		if dayPlaces == nil {
+			dayPlaces = []interfaces.RoutePlace{}
+		}
+		collection.Features = append(collection.Features, interfaces.RouteFeature{
+			Type:     "Feature",
+			Geometry: geometry,
+			Properties: interfaces.RouteProperties{
+				Day:                 day,
+				StartedAt:           segment.StartedAt,
+				EndedAt:             segment.EndedAt,
+				PhotoCount:          segment.PhotoCount,
+				DistanceMeters:      segment.DistanceMeters,
+				ElevationGainMeters: segment.ElevationGainMeters,
+				Places:              dayPlaces,
+			},
+		})
+		collection.TotalDistanceMeters += segment.DistanceMeters
+		if segment.ElevationGainMeters != nil {
+			if collection.TotalElevationGainMeters == nil {
+				collection.TotalElevationGainMeters = new(float64)
+			}
+			*collection.TotalElevationGainMeters += *segment.ElevationGainMeters
+		}
+	}
+	return collection, nil
+}
+
+// lineStringGeoJSON makes sure a line has two positions; a day with a single photo
+// comes back from PostGIS as a one-point line, which GeoJSON does not allow.
+func lineStringGeoJSON(raw string) (json.RawMessage, error) {
+	var line struct {
+		Type        string      `json:"type"`
+		Coordinates [][]float64 `json:"coordinates"`
+	}
+	if err := json.Unmarshal([]byte(raw), &line); err != nil {
+		return nil, fmt.Errorf("invalid route geometry: %v", err)
+	}
+	if len(line.Coordinates) != 1 {
+		return json.RawMessage(raw), nil
+	}
+	line.Coordinates = append(line.Coordinates, line.Coordinates[0])
+	return json.Marshal(line)
+}
@@ -3,18 +3,29 @@ package services
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"mime"
+	"net/url"
+	"path"
 	"path/filepath"
 	"photo-service/src/interfaces"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 type S3Uploader struct {
 	client *s3.Client
 	bucket string
+	// versioned is set when the bucket keeps the earlier contents of overwritten files.
+	versioned bool
 }
 
 func NewS3Uploader(client *s3.Client, bucket string) *S3Uploader {
@@ -24,11 +35,26 @@ func NewS3Uploader(client *s3.Client, bucket string) *S3Uploader {
 	}
 }
 
-func (u *S3Uploader) Upload(ctx context.Context, request interfaces.UploadFileRequest) (string, error) {
-	contentType := mime.TypeByExtension(filepath.Ext(request.FileName))
-	if contentType == "" {
-		contentType = "application/octet-stream" // Default to binary if unknown
+// DetectVersioning checks whether the bucket has versioning enabled, and keeps photo
+// versions as object versions from then on when it has.
+func (u *S3Uploader) DetectVersioning(ctx context.Context) (bool, error) {
+	output, err := u.client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(u.bucket),
+	})
+	if err != nil {
+		log.Println("Failed to get S3 bucket versioning:", err)
+		return false, err
 	}
+	u.versioned = output.Status == types.BucketVersioningStatusEnabled
+	return u.versioned, nil
+}
+
+func (u *S3Uploader) Versioned() bool {
+	return u.versioned
+}
+
+func (u *S3Uploader) Upload(ctx context.Context, request interfaces.UploadFileRequest) (string, error) {
+	contentType := contentTypeOf(request.FileName)
 	path := request.UserID + "/" + request.Id + "--" + request.FileName
 	_, err := u.client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(u.bucket),
@@ -43,3 +69,345 @@ func (u *S3Uploader) Upload(ctx context.Context, request interfaces.UploadFileRe
 
 	return path, nil
 }
+
+// contentTypeOf guesses a file's content type from its name.
+func contentTypeOf(fileName string) string {
+	contentType := mime.TypeByExtension(filepath.Ext(fileName))
+	if contentType == "" {
+		contentType = "application/octet-stream" // Default to binary if unknown
+	}
+	return contentType
+}
+
+// PutVersion overwrites the file at key and returns the version ID S3 gave the new
+// contents, which is empty unless the bucket is versioned.
+func (u *S3Uploader) PutVersion(ctx context.Context, key string, fileName string, data []byte) (string, error) {
+	output, err := u.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentTypeOf(fileName)),
+	})
+	if err != nil {
+		log.Println("Failed to upload file version to S3:", err)
+		return "", err
+	}
+	return aws.ToString(output.VersionId), nil
+}
+
+// LatestVersionID returns the version ID of the current contents of key, or ErrNotFound
+// when there is no file.
+func (u *S3Uploader) LatestVersionID(ctx context.Context, key string) (string, error) {
+	output, err := u.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return "", interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Println("Failed to head S3 object:", err)
+		return "", err
+	}
+	return aws.ToString(output.VersionId), nil
+}
+
+// RestoreVersion copies a version of sourceKey over the file at key, which gets a new
+// version with the same contents.
+func (u *S3Uploader) RestoreVersion(ctx context.Context, key string, sourceKey string, versionID string) error {
+	source := (&url.URL{Path: u.bucket + "/" + sourceKey}).EscapedPath() + "?versionId=" + url.QueryEscape(versionID)
+	_, err := u.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(u.bucket),
+		Key:        aws.String(key),
+		CopySource: aws.String(source),
+	})
+	if err != nil {
+		log.Println("Failed to copy S3 object version:", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteVersion removes one version of key for good.
+func (u *S3Uploader) DeleteVersion(ctx context.Context, key string, versionID string) error {
+	_, err := u.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket:    aws.String(u.bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+	if err != nil {
+		log.Println("Failed to delete S3 object version:", err)
+		return err
+	}
+	return nil
+}
+
+// Delete removes a stored file. Deleting a file that does not exist is not an error. In a
+// versioned bucket every version of the file is removed, so its storage is freed.
+func (u *S3Uploader) Delete(ctx context.Context, key string) error {
+	if u.versioned {
+		return u.deleteAllVersions(ctx, key)
+	}
+	_, err := u.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Println("Failed to delete file from S3:", err)
+		return err
+	}
+	return nil
+}
+
+func (u *S3Uploader) deleteAllVersions(ctx context.Context, key string) error {
+	pages := s3.NewListObjectVersionsPaginator(u.client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(u.bucket),
+		Prefix: aws.String(key),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			log.Println("Failed to list S3 object versions:", err)
+			return err
+		}
+		var versionIDs []string
+		for _, version := range page.Versions {
+			if aws.ToString(version.Key) == key {
+				versionIDs = append(versionIDs, aws.ToString(version.VersionId))
+			}
+		}
+		for _, marker := range page.DeleteMarkers {
+			if aws.ToString(marker.Key) == key {
+				versionIDs = append(versionIDs, aws.ToString(marker.VersionId))
+			}
+		}
+		for _, versionID := range versionIDs {
+			if err := u.DeleteVersion(ctx, key, versionID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CreateMultipartUpload starts assembling the file at key from parts.
+func (u *S3Uploader) CreateMultipartUpload(ctx context.Context, key string) (string, error) {
+	contentType := contentTypeOf(key)
+	output, err := u.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		log.Println("Failed to create S3 multipart upload:", err)
+		return "", err
+	}
+	return aws.ToString(output.UploadId), nil
+}
+
+// UploadPart stores a part and returns its ETag. Every part but the last must be at least
+// 5 MiB.
+func (u *S3Uploader) UploadPart(ctx context.Context, key string, uploadID string, partNumber int32, data []byte) (string, error) {
+	output, err := u.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(u.bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		log.Println("Failed to upload S3 part:", err)
+		return "", err
+	}
+	return aws.ToString(output.ETag), nil
+}
+
+func (u *S3Uploader) CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []interfaces.UploadedPart) error {
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, types.CompletedPart{ETag: aws.String(part.ETag), PartNumber: aws.Int32(part.PartNumber)})
+	}
+	_, err := u.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(u.bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		log.Println("Failed to complete S3 multipart upload:", err)
+		return err
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards the parts of a multipart upload. Uploads that are gone
+// already, aborted before or by a lifecycle rule, count as aborted.
+func (u *S3Uploader) AbortMultipartUpload(ctx context.Context, key string, uploadID string) error {
+	_, err := u.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(u.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	var noSuchUpload *types.NoSuchUpload
+	if errors.As(err, &noSuchUpload) {
+		return nil
+	}
+	if err != nil {
+		log.Println("Failed to abort S3 multipart upload:", err)
+		return err
+	}
+	return nil
+}
+
+// PutObject stores data under key as is.
+func (u *S3Uploader) PutObject(ctx context.Context, key string, data []byte) error {
+	_, err := u.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		log.Println("Failed to put S3 object:", err)
+		return err
+	}
+	return nil
+}
+
+func (u *S3Uploader) GetObject(ctx context.Context, key string) ([]byte, error) {
+	output, err := u.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Println("Failed to get S3 object:", err)
+		return nil, err
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+// HeadObject describes the file at key, or returns ErrNotFound when there is none.
+func (u *S3Uploader) HeadObject(ctx context.Context, key string) (interfaces.StoredObject, error) {
+	output, err := u.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return interfaces.StoredObject{}, interfaces.ErrNotFound
+	}
+	if err != nil {
+		log.Println("Failed to head S3 object:", err)
+		return interfaces.StoredObject{}, err
+	}
+	return interfaces.StoredObject{
+		SizeBytes:   aws.ToInt64(output.ContentLength),
+		ContentType: aws.ToString(output.ContentType),
+	}, nil
+}
+
+// GetObjectHead reads the first n bytes of the file at key, or all of a shorter file.
+func (u *S3Uploader) GetObjectHead(ctx context.Context, key string, n int64) ([]byte, error) {
+	output, err := u.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=0-%d", n-1)),
+	})
+	if err != nil {
+		log.Println("Failed to get S3 object range:", err)
+		return nil, err
+	}
+	defer output.Body.Close()
+	return io.ReadAll(io.LimitReader(output.Body, n))
+}
+
+// HashObject streams the file at key through SHA-256, so large files are never held in
+// memory.
+func (u *S3Uploader) HashObject(ctx context.Context, key string) (string, error) {
+	output, err := u.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Println("Failed to get S3 object:", err)
+		return "", err
+	}
+	defer output.Body.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, output.Body); err != nil {
+		log.Println("Failed to read S3 object:", err)
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// PresignUpload presigns a PUT and a form POST of the file at request.Key. Both only accept
+// a file of exactly request.SizeBytes bytes with request.ContentType.
+func (u *S3Uploader) PresignUpload(ctx context.Context, request interfaces.PresignUploadRequest) (interfaces.PresignedUpload, error) {
+	presigner := s3.NewPresignClient(u.client)
+	put, err := presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(u.bucket),
+		Key:           aws.String(request.Key),
+		ContentType:   aws.String(request.ContentType),
+		ContentLength: aws.Int64(request.SizeBytes),
+	}, s3.WithPresignExpires(request.Expires))
+	if err != nil {
+		log.Println("Failed to presign S3 PUT:", err)
+		return interfaces.PresignedUpload{}, err
+	}
+	headers := map[string]string{}
+	for name, values := range put.SignedHeader {
+		// The client sets Host itself.
+		if !strings.EqualFold(name, "Host") && len(values) > 0 {
+			headers[name] = values[0]
+		}
+	}
+
+	post, err := presigner.PresignPostObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(request.Key),
+	}, func(options *s3.PresignPostOptions) {
+		options.Expires = request.Expires
+		options.Conditions = []interface{}{
+			[]interface{}{"content-length-range", request.SizeBytes, request.SizeBytes},
+			map[string]string{"Content-Type": request.ContentType},
+		}
+	})
+	if err != nil {
+		log.Println("Failed to presign S3 POST:", err)
+		return interfaces.PresignedUpload{}, err
+	}
+	fields := post.Values
+	fields["Content-Type"] = request.ContentType
+
+	return interfaces.PresignedUpload{
+		PutURL:     put.URL,
+		PutHeaders: headers,
+		PostURL:    post.URL,
+		PostFields: fields,
+	}, nil
+}
+
+// PresignURL returns a temporary GET URL for a stored file.
+func (u *S3Uploader) PresignURL(ctx context.Context, request interfaces.PresignURLRequest) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(request.Key),
+	}
+	if request.Download {
+		// Keys are "<user>/<photo>--<file name>"; offer the original file name.
+		fileName := path.Base(request.Key)
+		if _, name, ok := strings.Cut(fileName, "--"); ok {
+			fileName = name
+		}
+		input.ResponseContentDisposition = aws.String(mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+	presigned, err := s3.NewPresignClient(u.client).PresignGetObject(ctx, input, s3.WithPresignExpires(request.Expires))
+	if err != nil {
+		log.Println("Failed to presign S3 URL:", err)
+		return "", err
+	}
+	return presigned.URL, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"photo-service/src/interfaces"
+)
+
+const (
+	maxSearchQueryLength = 256
+	maxSearchQueryTerms  = 32
+)
+
+// buildTSQuery turns a user search string into to_tsquery input. Words are ANDed together,
+// "quoted words" must appear next to each other and a trailing * matches any word with
+// that prefix. Everything but letters and digits is dropped, so the result is always a
+// valid tsquery.
+func buildTSQuery(query string) (string, error) {
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return "", fmt.Errorf("%w: q must be at most %d characters", interfaces.ErrInvalidArgument, maxSearchQueryLength)
+	}
+
+	var terms []string
+	words := 0
+	// Splitting on quotes leaves phrases at the odd indexes; an unclosed quote runs to the end.
+	for i, part := range strings.Split(query, `"`) {
+		var lexemes []string
+		for _, word := range strings.Fields(part) {
+			wordLexemes := searchLexemes(word)
+			if len(wordLexemes) == 0 {
+				continue
+			}
+			words++
+			if i%2 == 1 {
+				lexemes = append(lexemes, wordLexemes...)
+			} else {
+				terms = append(terms, strings.Join(wordLexemes, " <-> "))
+			}
+		}
+		if len(lexemes) > 0 {
+			terms = append(terms, "("+strings.Join(lexemes, " <-> ")+")")
+		}
+	}
+	if words == 0 {
+		return "", fmt.Errorf("%w: q must contain at least one word", interfaces.ErrInvalidArgument)
+	}
+	if words > maxSearchQueryTerms {
+		return "", fmt.Errorf("%w: q must contain at most %d words", interfaces.ErrInvalidArgument, maxSearchQueryTerms)
+	}
+	return strings.Join(terms, " & "), nil
+}
+
+// searchLexemes splits a word into quoted tsquery lexemes on anything that is not a letter
+// or digit, so "e-mail" becomes 'e' and 'mail'. A trailing * makes the last one a prefix match.
+func searchLexemes(word string) []string {
+	prefix := strings.HasSuffix(word, "*")
+	parts := strings.FieldsFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	lexemes := make([]string, 0, len(parts))
+	for _, part := range parts {
+		lexemes = append(lexemes, "'"+part+"'")
+	}
+	if prefix && len(lexemes) > 0 {
+		lexemes[len(lexemes)-1] += ":*"
+	}
+	return lexemes
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	shareTokenBytes      = 32
+	shareURLExpiry       = 15 * time.Minute
+	maxSharePasswordSize = 72 // bcrypt ignores anything longer
+	defaultSharedPhotos  = 50
+)
+
+type ShareLinkService struct {
+	repo      interfaces.IShareLinkRepository
+	photoRepo interfaces.IPhotoRepository
+	albumRepo interfaces.IAlbumRepository
+	signer    interfaces.IFileURLSigner
+	policy    interfaces.IAccessPolicy
+	// Password attempts per link, whichever addresses they come from.
+	attempts      interfaces.IRateLimitStore
+	passwordLimit interfaces.RateLimit
+}
+
+func NewShareLinkService(
+	repo interfaces.IShareLinkRepository,
+	photoRepo interfaces.IPhotoRepository,
+	albumRepo interfaces.IAlbumRepository,
+	signer interfaces.IFileURLSigner,
+	policy interfaces.IAccessPolicy,
+	attempts interfaces.IRateLimitStore,
+	passwordLimit interfaces.RateLimit,
+) *ShareLinkService {
+	return &ShareLinkService{
+		repo:          repo,
+		photoRepo:     photoRepo,
+		albumRepo:     albumRepo,
+		signer:        signer,
+		policy:        policy,
+		attempts:      attempts,
+		passwordLimit: passwordLimit,
+	}
+}
+
+// CreateShareLink issues a new link to one of the user's photos or albums.
+func (s *ShareLinkService) CreateShareLink(ctx context.Context, request interfaces.CreateShareLinkRequest) (interfaces.CreatedShareLink, error) {
+	if (request.PhotoID == nil) == (request.AlbumID == nil) {
+		return interfaces.CreatedShareLink{}, fmt.Errorf("%w: share either a photo or an album", interfaces.ErrInvalidArgument)
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return interfaces.CreatedShareLink{}, fmt.Errorf("%w: expires_at must be in the future", interfaces.ErrInvalidArgument)
+	}
+	if len(request.Password) > maxSharePasswordSize {
+		return interfaces.CreatedShareLink{}, fmt.Errorf("%w: password cannot exceed %d bytes", interfaces.ErrInvalidArgument, maxSharePasswordSize)
+	}
+	if err := s.checkShareTarget(ctx, request); err != nil {
+		return interfaces.CreatedShareLink{}, err
+	}
+
+	token, tokenHash, err := newShareToken()
+	if err != nil {
+		return interfaces.CreatedShareLink{}, err
+	}
+	repoRequest := interfaces.CreateShareLinkRepoRequest{
+		OwnerID:       request.UserID,
+		TokenHash:     tokenHash,
+		PhotoID:       request.PhotoID,
+		AlbumID:       request.AlbumID,
+		AllowDownload: request.AllowDownload,
+		ExpiresAt:     utcTime(request.ExpiresAt),
+	}
+	if request.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return interfaces.CreatedShareLink{}, err
+		}
+		repoRequest.PasswordHash = string(hash)
+	}
+	link, err := s.repo.CreateShareLink(ctx, repoRequest)
+	if err != nil {
+		return interfaces.CreatedShareLink{}, err
+	}
+	return interfaces.CreatedShareLink{ShareLink: link, Token: token}, nil
+}
+
+func (s *ShareLinkService) ListShareLinks(ctx context.Context, userID uuid.UUID) ([]interfaces.ShareLink, error) {
+	return s.repo.ListShareLinks(ctx, userID)
+}
+
+func (s *ShareLinkService) RevokeShareLink(ctx context.Context, userID uuid.UUID, linkID uuid.UUID) error {
+	link, err := s.repo.GetShareLink(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	if link.OwnerID != userID {
+		return interfaces.ErrNotFound
+	}
+	return s.repo.RevokeShareLink(ctx, linkID)
+}
+
+// OpenShareLink resolves a token for an anonymous visitor. Unknown, revoked and expired
+// links all look the same so tokens cannot be probed.
+func (s *ShareLinkService) OpenShareLink(ctx context.Context, request interfaces.OpenShareLinkRequest) (interfaces.SharedContent, error) {
+	link, err := s.repo.GetShareLinkByTokenHash(ctx, hashShareToken(request.Token))
+	if err != nil {
+		return interfaces.SharedContent{}, err
+	}
+	if link.RevokedAt != nil || (link.ExpiresAt != nil && !time.Now().UTC().Before(*link.ExpiresAt)) {
+		return interfaces.SharedContent{}, interfaces.ErrNotFound
+	}
+	if link.HasPassword {
+		if request.Password == "" {
+			return interfaces.SharedContent{}, fmt.Errorf("%w: this link needs a password", interfaces.ErrUnauthorized)
+		}
+		if err := s.takePasswordAttempt(ctx, link.ID); err != nil {
+			return interfaces.SharedContent{}, err
+		}
+		if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(request.Password)) != nil {
+			return interfaces.SharedContent{}, fmt.Errorf("%w: wrong password", interfaces.ErrUnauthorized)
+		}
+	}
+
+	content := interfaces.SharedContent{ExpiresAt: link.ExpiresAt}
+	if link.PhotoID != nil {
+		photo, err := s.photoRepo.GetPhotoDetail(ctx, *link.PhotoID)
+		if err != nil {
+			return interfaces.SharedContent{}, err
+		}
+		shared, err := s.sharedPhoto(ctx, photo, link.AllowDownload)
+		if err != nil {
+			return interfaces.SharedContent{}, err
+		}
+		content.Photo = &shared
+		return content, nil
+	}
+
+	album, err := s.albumRepo.GetAlbum(ctx, *link.AlbumID)
+	if err != nil {
+		return interfaces.SharedContent{}, err
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultSharedPhotos
+	}
+	photos, err := s.albumRepo.ListAlbumPhotos(ctx, album.ID, limit, request.Offset)
+	if err != nil {
+		return interfaces.SharedContent{}, err
+	}
+	content.Album = &interfaces.SharedAlbum{
+		Title:       album.Title,
+		Description: album.Description,
+		PhotoCount:  album.PhotoCount,
+		Photos:      make([]interfaces.SharedPhoto, 0, len(photos)),
+	}
+	for _, photo := range photos {
+		shared, err := s.sharedPhoto(ctx, photo.Photo, link.AllowDownload)
+		if err != nil {
+			return interfaces.SharedContent{}, err
+		}
+		content.Album.Photos = append(content.Album.Photos, shared)
+	}
+	return content, nil
+}
+
+// checkShareTarget lets only owners share their photos and albums.
+// takePasswordAttempt counts a password check against the link before bcrypt runs, so a
+// link's password cannot be guessed, nor bcrypt kept busy, faster than passwordLimit.
+func (s *ShareLinkService) takePasswordAttempt(ctx context.Context, linkID uuid.UUID) error {
+	if s.passwordLimit.Rate <= 0 {
+		return nil
+	}
+	allowed, retryAfter, err := s.attempts.Take(ctx, "share-password:"+linkID.String(), s.passwordLimit)
+	if err != nil {
+		log.Printf("Error checking share link password attempts: %v", err)
+		return nil
+	}
+	if !allowed {
+		return &interfaces.RateLimitedError{RetryAfter: retryAfter, Detail: "too many password attempts, try again later"}
+	}
+	return nil
+}
+
+func (s *ShareLinkService) checkShareTarget(ctx context.Context, request interfaces.CreateShareLinkRequest) error {
+	if request.PhotoID != nil {
+		_, err := s.policy.AuthorizePhoto(ctx, request.UserID, *request.PhotoID, interfaces.PhotoActionEdit)
+		return err
+	}
+	_, err := s.policy.AuthorizeAlbum(ctx, request.UserID, *request.AlbumID, interfaces.AlbumActionManage)
+	return err
+}
+
+// sharedPhoto builds the anonymous view of a photo with presigned URLs; private-zone
+// locations are hidden as for any other viewer.
+func (s *ShareLinkService) sharedPhoto(ctx context.Context, photo interfaces.Photo, allowDownload bool) (interfaces.SharedPhoto, error) {
+	redactPrivateLocation(uuid.Nil, &photo)
+	shared := interfaces.SharedPhoto{
+		ID:          photo.ID,
+		Description: photo.Description,
+		Location:    photo.Location,
+		CapturedAt:  photo.CapturedAt,
+	}
+	url, err := s.signer.PresignURL(ctx, interfaces.PresignURLRequest{Key: photo.URL, Expires: shareURLExpiry})
+	if err != nil {
+		return interfaces.SharedPhoto{}, err
+	}
+	shared.URL = url
+	if allowDownload {
+		url, err := s.signer.PresignURL(ctx, interfaces.PresignURLRequest{Key: photo.URL, Download: true, Expires: shareURLExpiry})
+		if err != nil {
+			return interfaces.SharedPhoto{}, err
+		}
+		shared.DownloadURL = url
+	}
+	return shared, nil
+}
+
+// newShareToken returns a random URL-safe token and the hash stored in its place.
+func newShareToken() (string, []byte, error) {
+	raw := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, errors.New("could not generate share token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashShareToken(token), nil
+}
+
+func hashShareToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
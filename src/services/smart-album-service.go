@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+type SmartAlbumService struct {
+	repo      interfaces.ISmartAlbumRepository
+	photoRepo interfaces.IPhotoRepository
+}
+
+func NewSmartAlbumService(repo interfaces.ISmartAlbumRepository, photoRepo interfaces.IPhotoRepository) *SmartAlbumService {
+	return &SmartAlbumService{repo: repo, photoRepo: photoRepo}
+}
+
+func (s *SmartAlbumService) CreateSmartAlbum(ctx context.Context, request interfaces.CreateSmartAlbumRequest) (interfaces.SmartAlbum, error) {
+	title, err := validateAlbumTitle(request.Title)
+	if err != nil {
+		return interfaces.SmartAlbum{}, err
+	}
+	if len(request.Description) > 1024 {
+		return interfaces.SmartAlbum{}, fmt.Errorf("%w: description cannot exceed 1024 characters", interfaces.ErrInvalidArgument)
+	}
+	if err := validateSavedQuery(request.UserID, request.Query); err != nil {
+		return interfaces.SmartAlbum{}, err
+	}
+	return s.repo.CreateSmartAlbum(ctx, interfaces.CreateSmartAlbumRepoRequest{
+		OwnerID:     request.UserID,
+		Title:       title,
+		Description: request.Description,
+		Query:       request.Query,
+	})
+}
+
+func (s *SmartAlbumService) GetSmartAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) (interfaces.SmartAlbum, error) {
+	return s.ownedSmartAlbum(ctx, userID, albumID)
+}
+
+func (s *SmartAlbumService) ListSmartAlbums(ctx context.Context, userID uuid.UUID) ([]interfaces.SmartAlbum, error) {
+	return s.repo.ListSmartAlbums(ctx, userID)
+}
+
+func (s *SmartAlbumService) UpdateSmartAlbum(ctx context.Context, request interfaces.UpdateSmartAlbumRequest) (interfaces.SmartAlbum, error) {
+	if request.Title == nil && !request.SetDescription && request.Query == nil {
+		return interfaces.SmartAlbum{}, fmt.Errorf("%w: nothing to update", interfaces.ErrInvalidArgument)
+	}
+	repoRequest := interfaces.UpdateSmartAlbumRepoRequest{
+		AlbumID:        request.AlbumID,
+		SetDescription: request.SetDescription,
+		Description:    request.Description,
+		Query:          request.Query,
+	}
+	if request.Title != nil {
+		title, err := validateAlbumTitle(*request.Title)
+		if err != nil {
+			return interfaces.SmartAlbum{}, err
+		}
+		repoRequest.Title = &title
+	}
+	if request.Description != nil && len(*request.Description) > 1024 {
+		return interfaces.SmartAlbum{}, fmt.Errorf("%w: description cannot exceed 1024 characters", interfaces.ErrInvalidArgument)
+	}
+	if request.Query != nil {
+		if err := validateSavedQuery(request.UserID, *request.Query); err != nil {
+			return interfaces.SmartAlbum{}, err
+		}
+	}
+
+	if _, err := s.ownedSmartAlbum(ctx, request.UserID, request.AlbumID); err != nil {
+		return interfaces.SmartAlbum{}, err
+	}
+	if err := s.repo.UpdateSmartAlbum(ctx, repoRequest); err != nil {
+		return interfaces.SmartAlbum{}, err
+	}
+	return s.repo.GetSmartAlbum(ctx, request.AlbumID)
+}
+
+func (s *SmartAlbumService) DeleteSmartAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) error {
+	if _, err := s.ownedSmartAlbum(ctx, userID, albumID); err != nil {
+		return err
+	}
+	return s.repo.DeleteSmartAlbum(ctx, albumID)
+}
+
+// ListSmartAlbumPhotos runs the album's saved query, so photos added or edited since it
+// was saved show up without any bookkeeping.
+func (s *SmartAlbumService) ListSmartAlbumPhotos(ctx context.Context, request interfaces.ListSmartAlbumPhotosRequest) (interfaces.PhotoQueryPage, error) {
+	album, err := s.ownedSmartAlbum(ctx, request.UserID, request.AlbumID)
+	if err != nil {
+		return interfaces.PhotoQueryPage{}, err
+	}
+	query := album.Query
+	query.Cursor = request.Cursor
+	query.Limit = request.Limit
+	return queryPhotoPage(ctx, s.photoRepo, request.UserID, query)
+}
+
+// CountSmartAlbumPhotos counts the photos currently matching the album's saved query.
+func (s *SmartAlbumService) CountSmartAlbumPhotos(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) (int64, error) {
+	album, err := s.ownedSmartAlbum(ctx, userID, albumID)
+	if err != nil {
+		return 0, err
+	}
+	repoRequest, err := compilePhotoQuery(userID, album.Query)
+	if err != nil {
+		return 0, err
+	}
+	return s.photoRepo.CountPhotos(ctx, repoRequest)
+}
+
+func (s *SmartAlbumService) ownedSmartAlbum(ctx context.Context, userID uuid.UUID, albumID uuid.UUID) (interfaces.SmartAlbum, error) {
+	album, err := s.repo.GetSmartAlbum(ctx, albumID)
+	if err != nil {
+		return interfaces.SmartAlbum{}, err
+	}
+	if album.OwnerID != userID {
+		return interfaces.SmartAlbum{}, interfaces.ErrNotFound
+	}
+	return album, nil
+}
+
+// validateSavedQuery checks a query before it is saved; paging belongs to each read.
+func validateSavedQuery(userID uuid.UUID, query interfaces.PhotoQuery) error {
+	if query.Cursor != "" || query.Limit != 0 {
+		return fmt.Errorf("%w: a saved query cannot contain cursor or limit", interfaces.ErrInvalidArgument)
+	}
+	_, err := compilePhotoQuery(userID, query)
+	return err
+}
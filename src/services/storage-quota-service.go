@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// defaultStorageTier is the only tier when none are configured; it has no limits.
+const defaultStorageTier = "default"
+
+var storageTierName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// storageUnits are the size suffixes accepted in tier definitions.
+var storageUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+type StorageQuotaService struct {
+	repo        interfaces.IStorageUsageRepository
+	tiers       map[string]interfaces.StorageTier
+	defaultTier string
+}
+
+// NewStorageQuotaService enforces the given tiers. Users without a tier of their own get
+// defaultTier, or the first tier when it is empty. Without tiers storage is unlimited.
+func NewStorageQuotaService(repo interfaces.IStorageUsageRepository, tiers []interfaces.StorageTier, defaultTier string) (*StorageQuotaService, error) {
+	if len(tiers) == 0 {
+		tiers = []interfaces.StorageTier{{Name: defaultStorageTier}}
+	}
+	if defaultTier == "" {
+		defaultTier = tiers[0].Name
+	}
+	s := &StorageQuotaService{repo: repo, tiers: make(map[string]interfaces.StorageTier, len(tiers)), defaultTier: defaultTier}
+	for _, tier := range tiers {
+		s.tiers[tier.Name] = tier
+	}
+	if _, ok := s.tiers[defaultTier]; !ok {
+		return nil, fmt.Errorf("default storage tier %q is not configured", defaultTier)
+	}
+	return s, nil
+}
+
+// ParseStorageTiers reads tier definitions such as "free:5GiB:1000,pro:200GiB:0": a name,
+// the byte limit and the photo limit, where 0 means unlimited.
+func ParseStorageTiers(spec string) ([]interfaces.StorageTier, error) {
+	var tiers []interfaces.StorageTier
+	seen := map[string]bool{}
+	for _, definition := range strings.Split(spec, ",") {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+		parts := strings.Split(definition, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("storage tier %q must look like name:max_bytes:max_photos", definition)
+		}
+		name := parts[0]
+		if !storageTierName.MatchString(name) {
+			return nil, fmt.Errorf("invalid storage tier name %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("storage tier %q is defined twice", name)
+		}
+		seen[name] = true
+		maxBytes, err := parseStorageSize(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("storage tier %q: %w", name, err)
+		}
+		maxPhotos, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || maxPhotos < 0 {
+			return nil, fmt.Errorf("storage tier %q: invalid photo limit %q", name, parts[2])
+		}
+		tiers = append(tiers, interfaces.StorageTier{Name: name, MaxBytes: maxBytes, MaxPhotos: maxPhotos})
+	}
+	return tiers, nil
+}
+
+func parseStorageSize(raw string) (int64, error) {
+	number, unit := raw, int64(1)
+	for _, u := range storageUnits {
+		if strings.HasSuffix(raw, u.suffix) {
+			number, unit = strings.TrimSuffix(raw, u.suffix), u.bytes
+			break
+		}
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64)
+	if err != nil || value < 0 || (value > 0 && value > (1<<63-1)/unit) {
+		return 0, fmt.Errorf("invalid size %q", raw)
+	}
+	return value * unit, nil
+}
+
+func (s *StorageQuotaService) GetUsage(ctx context.Context, userID uuid.UUID) (interfaces.StorageUsage, error) {
+	usage, err := s.repo.GetStorageUsage(ctx, userID)
+	if err != nil {
+		return interfaces.StorageUsage{}, err
+	}
+	tier := s.tierOf(usage)
+	result := interfaces.StorageUsage{
+		UserID:        userID,
+		Tier:          tier.Name,
+		PhotoCount:    usage.PhotoCount,
+		UsedBytes:     usage.OriginalBytes + usage.VersionBytes,
+		OriginalBytes: usage.OriginalBytes,
+		VersionBytes:  usage.VersionBytes,
+	}
+	if tier.MaxBytes > 0 {
+		result.MaxBytes = &tier.MaxBytes
+	}
+	if tier.MaxPhotos > 0 {
+		result.MaxPhotos = &tier.MaxPhotos
+	}
+	return result, nil
+}
+
+// SetTier moves a user to another tier. Usage above the new limits is kept, but further
+// uploads are rejected until the user is back under them.
+func (s *StorageQuotaService) SetTier(ctx context.Context, userID uuid.UUID, tier string) (interfaces.StorageUsage, error) {
+	if _, ok := s.tiers[tier]; !ok {
+		return interfaces.StorageUsage{}, fmt.Errorf("%w: unknown storage tier %q", interfaces.ErrInvalidArgument, tier)
+	}
+	if tier == s.defaultTier {
+		tier = ""
+	}
+	if err := s.repo.SetStorageTier(ctx, userID, tier); err != nil {
+		return interfaces.StorageUsage{}, err
+	}
+	return s.GetUsage(ctx, userID)
+}
+
+func (s *StorageQuotaService) CheckUpload(ctx context.Context, ownerID uuid.UUID, sizeBytes int64) (interfaces.StorageQuota, error) {
+	usage, err := s.repo.GetStorageUsage(ctx, ownerID)
+	if err != nil {
+		return interfaces.StorageQuota{}, err
+	}
+	tier := s.tierOf(usage)
+	if tier.MaxPhotos > 0 && usage.PhotoCount >= tier.MaxPhotos {
+		return interfaces.StorageQuota{}, fmt.Errorf("%w: the %s tier allows %d photos and %d are stored",
+			interfaces.ErrQuotaExceeded, tier.Name, tier.MaxPhotos, usage.PhotoCount)
+	}
+	return checkBytes(usage, tier, sizeBytes)
+}
+
+func (s *StorageQuotaService) CheckVersionUpload(ctx context.Context, ownerID uuid.UUID, sizeBytes int64) (interfaces.StorageQuota, error) {
+	usage, err := s.repo.GetStorageUsage(ctx, ownerID)
+	if err != nil {
+		return interfaces.StorageQuota{}, err
+	}
+	return checkBytes(usage, s.tierOf(usage), sizeBytes)
+}
+
+// checkBytes rejects storing sizeBytes more that would exceed the byte limit of the tier.
+func checkBytes(usage interfaces.StorageUsageRecord, tier interfaces.StorageTier, sizeBytes int64) (interfaces.StorageQuota, error) {
+	used := usage.OriginalBytes + usage.VersionBytes
+	if tier.MaxBytes > 0 && used+sizeBytes > tier.MaxBytes {
+		return interfaces.StorageQuota{}, fmt.Errorf("%w: uploading %s would exceed the %s limit of the %s tier, %s is in use",
+			interfaces.ErrQuotaExceeded, formatBytes(sizeBytes), formatBytes(tier.MaxBytes), tier.Name, formatBytes(used))
+	}
+	return interfaces.StorageQuota{MaxBytes: tier.MaxBytes, MaxPhotos: tier.MaxPhotos}, nil
+}
+
+// tierOf resolves a user's tier. A tier that was removed from the configuration falls
+// back to the default one.
+func (s *StorageQuotaService) tierOf(usage interfaces.StorageUsageRecord) interfaces.StorageTier {
+	if usage.Tier != "" {
+		if tier, ok := s.tiers[usage.Tier]; ok {
+			return tier
+		}
+		log.Printf("Unknown storage tier %q of user %s, using %s", usage.Tier, usage.OwnerID, s.defaultTier)
+	}
+	return s.tiers[s.defaultTier]
+}
+
+// formatBytes renders a size for error messages, in binary units.
+func formatBytes(n int64) string {
+	const unit = 1 << 10
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 3; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"photo-service/src/interfaces"
+)
+
+const (
+	maxTagLength       = 64
+	maxTagsPerRequest  = 50
+	defaultTagSuggests = 10
+)
+
+type TagService struct {
+	repo   interfaces.ITagRepository
+	policy interfaces.IAccessPolicy
+}
+
+func NewTagService(repo interfaces.ITagRepository, policy interfaces.IAccessPolicy) *TagService {
+	return &TagService{repo: repo, policy: policy}
+}
+
+// AddPhotoTags tags one of the user's photos and returns all of its tags.
+func (s *TagService) AddPhotoTags(ctx context.Context, request interfaces.PhotoTagsRequest) ([]string, error) {
+	names, err := normalizeTags(request.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.policy.AuthorizePhoto(ctx, request.UserID, request.PhotoID, interfaces.PhotoActionEdit); err != nil {
+		return nil, err
+	}
+	err = s.repo.AddPhotoTags(ctx, interfaces.AddPhotoTagsRepoRequest{
+		PhotoID: request.PhotoID,
+		OwnerID: request.UserID,
+		Names:   names,
+		Source:  interfaces.TagSourceManual,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListPhotoTags(ctx, request.PhotoID)
+}
+
+// RemovePhotoTags removes tags from one of the user's photos and returns the remaining ones.
+func (s *TagService) RemovePhotoTags(ctx context.Context, request interfaces.PhotoTagsRequest) ([]string, error) {
+	names, err := normalizeTags(request.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.policy.AuthorizePhoto(ctx, request.UserID, request.PhotoID, interfaces.PhotoActionEdit); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RemovePhotoTags(ctx, request.PhotoID, names); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPhotoTags(ctx, request.PhotoID)
+}
+
+// SearchTags suggests the user's tags starting with a prefix, with how often each is used.
+func (s *TagService) SearchTags(ctx context.Context, request interfaces.SearchTagsRequest) ([]interfaces.TagUsage, error) {
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultTagSuggests
+	}
+	return s.repo.SearchTags(ctx, request.UserID, normalizeTagName(request.Prefix), limit)
+}
+
+// normalizeTags validates tag names and removes case-insensitive duplicates, keeping the first spelling.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 || len(tags) > maxTagsPerRequest {
+		return nil, fmt.Errorf("%w: tags must contain between 1 and %d names", interfaces.ErrInvalidArgument, maxTagsPerRequest)
+	}
+	names := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		name := normalizeTagName(tag)
+		if name == "" || utf8.RuneCountInString(name) > maxTagLength {
+			return nil, fmt.Errorf("%w: tag names must be between 1 and %d characters", interfaces.ErrInvalidArgument, maxTagLength)
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// normalizeTagName trims a tag name and collapses inner whitespace.
+func normalizeTagName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// embeddedKeywordTags turns keywords read from a file into valid tag names, dropping the rest.
+func embeddedKeywordTags(keywords []string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, keyword := range keywords {
+		name := normalizeTagName(keyword)
+		key := strings.ToLower(name)
+		if name == "" || utf8.RuneCountInString(name) > maxTagLength || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+		if len(names) == maxTagsPerRequest {
+			break
+		}
+	}
+	return names
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// TusPartSize is the size of the multipart upload parts. S3 needs at least 5 MiB for
+	// every part but the last, so smaller writes are staged until a part is full. Each write
+	// holds a buffer of this size.
+	TusPartSize        = 8 << 20
+	maxTusFileName     = 100
+	maxTusDescription  = 255
+	tusDefaultFileName = "upload"
+	expiredTusBatch    = 100
+)
+
+// TusUploadService implements resumable uploads. Bytes are stored as parts of an S3
+// multipart upload as they arrive; once all are in, the parts are assembled into the file
+// and the photo is created from it.
+type TusUploadService struct {
+	repo    interfaces.ITusUploadRepository
+	storage interfaces.IMultipartStorage
+	photos  interfaces.IPhotoService
+	quota   interfaces.IStorageQuotaService
+	maxSize int64
+	// expiry is how long an upload is kept after its last write.
+	expiry time.Duration
+}
+
+func NewTusUploadService(
+	repo interfaces.ITusUploadRepository,
+	storage interfaces.IMultipartStorage,
+	photos interfaces.IPhotoService,
+	quota interfaces.IStorageQuotaService,
+	maxSize int64,
+	expiry time.Duration,
+) *TusUploadService {
+	return &TusUploadService{repo: repo, storage: storage, photos: photos, quota: quota, maxSize: maxSize, expiry: expiry}
+}
+
+func (s *TusUploadService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// CreateUpload starts an upload. Uploads that would not fit in the user's quota are
+// refused up front.
+func (s *TusUploadService) CreateUpload(ctx context.Context, request interfaces.CreateTusUploadRequest) (interfaces.TusUpload, error) {
+	if request.Length <= 0 || request.Length > s.maxSize {
+		return interfaces.TusUpload{}, fmt.Errorf("%w: Upload-Length must be between 1 and %d", interfaces.ErrInvalidArgument, s.maxSize)
+	}
+	if utf8.RuneCountInString(request.Description) > maxTusDescription {
+		return interfaces.TusUpload{}, fmt.Errorf("%w: description must be at most %d characters", interfaces.ErrInvalidArgument, maxTusDescription)
+	}
+	if _, err := s.quota.CheckUpload(ctx, request.UserID, request.Length); err != nil {
+		return interfaces.TusUpload{}, err
+	}
+
+	id := uuid.New()
+	fileName := cleanFileName(request.FileName)
+	// Same layout as files uploaded in one piece: "<user>/<id>--<file name>".
+	key := request.UserID.String() + "/" + id.String() + "--" + fileName
+	s3UploadID, err := s.storage.CreateMultipartUpload(ctx, key)
+	if err != nil {
+		return interfaces.TusUpload{}, err
+	}
+	upload, err := s.repo.CreateTusUpload(ctx, interfaces.CreateTusUploadRepoRequest{
+		ID:          id,
+		OwnerID:     request.UserID,
+		Length:      request.Length,
+		Metadata:    request.Metadata,
+		FileName:    fileName,
+		Description: request.Description,
+		StorageKey:  key,
+		S3UploadID:  s3UploadID,
+	})
+	if err != nil {
+		if abortErr := s.storage.AbortMultipartUpload(ctx, key, s3UploadID); abortErr != nil {
+			log.Printf("Error aborting multipart upload: %v", abortErr)
+		}
+		return interfaces.TusUpload{}, err
+	}
+	return upload, nil
+}
+
+// GetUpload returns one of the user's uploads; other users' uploads are not found.
+func (s *TusUploadService) GetUpload(ctx context.Context, userID uuid.UUID, id uuid.UUID) (interfaces.TusUpload, error) {
+	upload, err := s.repo.GetTusUpload(ctx, id)
+	if err != nil {
+		return interfaces.TusUpload{}, err
+	}
+	if upload.OwnerID != userID {
+		return interfaces.TusUpload{}, interfaces.ErrNotFound
+	}
+	return upload, nil
+}
+
+func (s *TusUploadService) WriteUpload(ctx context.Context, request interfaces.WriteTusUploadRequest) (interfaces.TusUpload, error) {
+	upload, err := s.GetUpload(ctx, request.UserID, request.ID)
+	if err != nil {
+		return interfaces.TusUpload{}, err
+	}
+	if request.Offset != upload.Offset {
+		return interfaces.TusUpload{}, fmt.Errorf("%w: the upload is at offset %d", interfaces.ErrConflict, upload.Offset)
+	}
+	upload, err = s.repo.ClaimTusUpload(ctx, upload.ID, request.Offset)
+	if errors.Is(err, interfaces.ErrConflict) {
+		return interfaces.TusUpload{}, fmt.Errorf("%w: the upload is being written by another request", interfaces.ErrConflict)
+	}
+	if err != nil {
+		return interfaces.TusUpload{}, err
+	}
+	defer func() {
+		// Release even when the client has gone away, so it can resume right away.
+		if err := s.repo.ReleaseTusUpload(context.WithoutCancel(ctx), upload.ID); err != nil {
+			log.Printf("Error releasing upload %s: %v", upload.ID, err)
+		}
+	}()
+
+	if upload.Offset < upload.Length {
+		upload, err = s.receive(ctx, upload, request.Body)
+		if err != nil {
+			return interfaces.TusUpload{}, err
+		}
+	}
+	if upload.Offset == upload.Length && upload.PhotoID == nil {
+		return s.finish(ctx, upload)
+	}
+	return upload, nil
+}
+
+// receive stores the bytes of body in full parts, staging the remainder. What was read is
+// kept even if the client disconnects halfway, so it can resume from there.
+func (s *TusUploadService) receive(ctx context.Context, upload interfaces.TusUpload, body io.Reader) (interfaces.TusUpload, error) {
+	ctx = context.WithoutCancel(ctx)
+	parts, err := s.repo.ListTusUploadParts(ctx, upload.ID)
+	if err != nil {
+		return upload, err
+	}
+	nextPart := int32(len(parts)) + 1
+
+	buf := make([]byte, TusPartSize)
+	filled := 0
+	staged := upload.Offset - upload.PartsBytes
+	if staged > 0 {
+		data, err := s.storage.GetObject(ctx, stagingKey(upload.ID))
+		if err != nil {
+			return upload, err
+		}
+		// Staging is written before the offset is recorded, so after a failed update it can
+		// hold bytes the client will send again. Its start is always what was recorded.
+		if int64(len(data)) < staged {
+			return upload, fmt.Errorf("staged data of upload %s has %d bytes, expected %d", upload.ID, len(data), staged)
+		}
+		filled = copy(buf, data[:staged])
+	}
+
+	start := upload.Offset
+	body = io.LimitReader(body, upload.Length-upload.Offset)
+	for {
+		n, readErr := io.ReadFull(body, buf[filled:])
+		filled += n
+		upload.Offset += int64(n)
+		if filled == len(buf) || (upload.Offset == upload.Length && filled > 0) {
+			etag, err := s.storage.UploadPart(ctx, upload.StorageKey, upload.S3UploadID, nextPart, buf[:filled])
+			if err != nil {
+				return upload, err
+			}
+			upload.PartsBytes += int64(filled)
+			part := interfaces.TusUploadPart{PartNumber: nextPart, ETag: etag, SizeBytes: int64(filled)}
+			if err := s.repo.AddTusUploadPart(ctx, upload.ID, part, upload.Offset, upload.PartsBytes); err != nil {
+				return upload, err
+			}
+			nextPart++
+			filled = 0
+		}
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+				log.Printf("Error reading upload %s, keeping %d bytes: %v", upload.ID, upload.Offset, readErr)
+			}
+			break
+		}
+	}
+
+	if upload.Offset == start {
+		return upload, nil
+	}
+	if filled > 0 {
+		if err := s.storage.PutObject(ctx, stagingKey(upload.ID), buf[:filled]); err != nil {
+			return upload, err
+		}
+	} else if staged > 0 {
+		// The staged bytes went into a part.
+		if err := s.storage.Delete(ctx, stagingKey(upload.ID)); err != nil {
+			log.Printf("Error removing staged data of upload %s: %v", upload.ID, err)
+		}
+	}
+	if err := s.repo.UpdateTusUploadProgress(ctx, upload.ID, upload.Offset, upload.PartsBytes); err != nil {
+		return upload, err
+	}
+	return upload, nil
+}
+
+// finish assembles the parts of a complete upload and creates the photo from the file.
+// Failures leave the upload complete, so a write of no bytes can try again.
+func (s *TusUploadService) finish(ctx context.Context, upload interfaces.TusUpload) (interfaces.TusUpload, error) {
+	if upload.AssembledAt == nil {
+		parts, err := s.repo.ListTusUploadParts(ctx, upload.ID)
+		if err != nil {
+			return upload, err
+		}
+		uploaded := make([]interfaces.UploadedPart, 0, len(parts))
+		for _, part := range parts {
+			uploaded = append(uploaded, interfaces.UploadedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+		}
+		if err := s.storage.CompleteMultipartUpload(ctx, upload.StorageKey, upload.S3UploadID, uploaded); err != nil {
+			return upload, err
+		}
+		if err := s.repo.MarkTusUploadAssembled(ctx, upload.ID); err != nil {
+			return upload, err
+		}
+	}
+
+	// The format is told from the start of the file; the rest stays in storage.
+	header, err := s.storage.GetObjectHead(ctx, upload.StorageKey, min(upload.Length, metadataHeaderBytes))
+	if err != nil {
+		return upload, err
+	}
+	photoID, err := s.photos.CreateStoredPhoto(ctx, interfaces.CreateStoredPhotoRequest{
+		UserID:      upload.OwnerID,
+		Description: upload.Description,
+		FileName:    upload.FileName,
+		FileData:    header,
+		SizeBytes:   upload.Length,
+		URL:         upload.StorageKey,
+	})
+	if err != nil {
+		return upload, err
+	}
+	id, err := uuid.Parse(photoID)
+	if err != nil {
+		return upload, err
+	}
+	if err := s.repo.SetTusUploadPhoto(ctx, upload.ID, id); err != nil {
+		return upload, err
+	}
+	upload.PhotoID = &id
+	return upload, nil
+}
+
+// TerminateUpload deletes an upload and the data stored for it. Photos created from
+// completed uploads are kept.
+func (s *TusUploadService) TerminateUpload(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	upload, err := s.GetUpload(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTusUpload(ctx, id); err != nil {
+		return err
+	}
+	if err := s.removeData(ctx, upload); err != nil {
+		log.Printf("Error removing data of upload %s: %v", id, err)
+	}
+	return nil
+}
+
+// CleanupExpired deletes uploads that were not written to within the expiry, along with
+// the data stored for them. Photos created from completed uploads are kept.
+func (s *TusUploadService) CleanupExpired(ctx context.Context) (int, error) {
+	deleted := 0
+	for {
+		uploads, err := s.repo.ListStaleTusUploads(ctx, time.Now().UTC().Add(-s.expiry), expiredTusBatch)
+		if err != nil {
+			return deleted, err
+		}
+		removed := 0
+		for _, upload := range uploads {
+			// Keep the upload when its data cannot be removed, so the next run retries.
+			if err := s.removeData(ctx, upload); err != nil {
+				log.Printf("Error removing data of expired upload %s: %v", upload.ID, err)
+				continue
+			}
+			if err := s.repo.DeleteTusUpload(ctx, upload.ID); err != nil && !errors.Is(err, interfaces.ErrNotFound) {
+				return deleted, err
+			}
+			removed++
+		}
+		deleted += removed
+		if len(uploads) < expiredTusBatch || removed == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+// removeData aborts the multipart upload of an upload, or removes its assembled file when
+// no photo was created from it, and removes its staged bytes.
+func (s *TusUploadService) removeData(ctx context.Context, upload interfaces.TusUpload) error {
+	if upload.AssembledAt == nil {
+		if err := s.storage.AbortMultipartUpload(ctx, upload.StorageKey, upload.S3UploadID); err != nil {
+			return err
+		}
+	} else if upload.PhotoID == nil {
+		if err := s.storage.Delete(ctx, upload.StorageKey); err != nil {
+			return err
+		}
+	}
+	if upload.Offset > upload.PartsBytes {
+		return s.storage.Delete(ctx, stagingKey(upload.ID))
+	}
+	return nil
+}
+
+// stagingKey is where the bytes of an upload that do not fill a part yet are kept.
+func stagingKey(id uuid.UUID) string {
+	return "tus-staging/" + id.String()
+}
+
+// cleanFileName keeps the base name of a client-supplied file name, shortened to fit the
+// photo URL.
+func cleanFileName(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" || name == ".." {
+		return tusDefaultFileName
+	}
+	if utf8.RuneCountInString(name) > maxTusFileName {
+		runes := []rune(name)
+		name = string(runes[len(runes)-maxTusFileName:])
+	}
+	return name
+}
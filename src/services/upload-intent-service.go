@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// uploadIntentGrace is how long an intent outlives its presigned request, so an upload
+	// started just before the request expired can still finish and be completed.
+	uploadIntentGrace    = time.Hour
+	maxIntentDescription = 255
+	expiredIntentBatch   = 100
+)
+
+// uploadIntentContentTypes are the content types accepted for direct uploads.
+var uploadIntentContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+	"image/heic": true,
+	"image/heif": true,
+	"image/tiff": true,
+}
+
+// UploadIntentService lets clients upload photos straight to the bucket: an intent reserves
+// the photo ID and presigns the upload, and completing it creates the photo from the file.
+type UploadIntentService struct {
+	repo       interfaces.IUploadIntentRepository
+	storage    interfaces.IDirectUploadStorage
+	photos     interfaces.IPhotoService
+	quota      interfaces.IStorageQuotaService
+	maxSize    int64
+	presignTTL time.Duration
+}
+
+func NewUploadIntentService(
+	repo interfaces.IUploadIntentRepository,
+	storage interfaces.IDirectUploadStorage,
+	photos interfaces.IPhotoService,
+	quota interfaces.IStorageQuotaService,
+	maxSize int64,
+	presignTTL time.Duration,
+) *UploadIntentService {
+	return &UploadIntentService{
+		repo:       repo,
+		storage:    storage,
+		photos:     photos,
+		quota:      quota,
+		maxSize:    maxSize,
+		presignTTL: presignTTL,
+	}
+}
+
+// CreateIntent reserves a photo and presigns the upload of its file. Files that would not
+// fit in the user's quota are refused up front.
+func (s *UploadIntentService) CreateIntent(ctx context.Context, request interfaces.CreateUploadIntentRequest) (interfaces.UploadIntentTicket, error) {
+	if request.SizeBytes <= 0 || request.SizeBytes > s.maxSize {
+		return interfaces.UploadIntentTicket{}, fmt.Errorf("%w: size_bytes must be between 1 and %d", interfaces.ErrInvalidArgument, s.maxSize)
+	}
+	if !uploadIntentContentTypes[request.ContentType] {
+		return interfaces.UploadIntentTicket{}, fmt.Errorf("%w: content_type %q is not a supported image type", interfaces.ErrInvalidArgument, request.ContentType)
+	}
+	if utf8.RuneCountInString(request.Description) > maxIntentDescription {
+		return interfaces.UploadIntentTicket{}, fmt.Errorf("%w: description must be at most %d characters", interfaces.ErrInvalidArgument, maxIntentDescription)
+	}
+	if _, err := s.quota.CheckUpload(ctx, request.UserID, request.SizeBytes); err != nil {
+		return interfaces.UploadIntentTicket{}, err
+	}
+
+	id := uuid.New()
+	fileName := cleanFileName(request.FileName)
+	// Same layout as files uploaded through the service: "<user>/<photo id>--<file name>".
+	key := request.UserID.String() + "/" + id.String() + "--" + fileName
+	upload, err := s.storage.PresignUpload(ctx, interfaces.PresignUploadRequest{
+		Key:         key,
+		ContentType: request.ContentType,
+		SizeBytes:   request.SizeBytes,
+		Expires:     s.presignTTL,
+	})
+	if err != nil {
+		return interfaces.UploadIntentTicket{}, err
+	}
+	intent, err := s.repo.CreateUploadIntent(ctx, interfaces.CreateUploadIntentRepoRequest{
+		ID:          id,
+		OwnerID:     request.UserID,
+		StorageKey:  key,
+		FileName:    fileName,
+		Description: request.Description,
+		ContentType: request.ContentType,
+		SizeBytes:   request.SizeBytes,
+		ExpiresAt:   time.Now().UTC().Add(s.presignTTL + uploadIntentGrace),
+	})
+	if err != nil {
+		return interfaces.UploadIntentTicket{}, err
+	}
+	return interfaces.UploadIntentTicket{Intent: intent, Upload: upload}, nil
+}
+
+func (s *UploadIntentService) CompleteIntent(ctx context.Context, userID uuid.UUID, id uuid.UUID) (string, error) {
+	intent, err := s.repo.GetUploadIntent(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if intent.OwnerID != userID {
+		return "", interfaces.ErrNotFound
+	}
+	if intent.CompletedAt != nil {
+		return intent.ID.String(), nil
+	}
+	if !time.Now().Before(intent.ExpiresAt) {
+		return "", fmt.Errorf("%w: upload intent has expired", interfaces.ErrNotFound)
+	}
+	intent, err = s.repo.ClaimUploadIntent(ctx, id)
+	if errors.Is(err, interfaces.ErrConflict) {
+		return "", fmt.Errorf("%w: upload intent is being completed by another request", interfaces.ErrConflict)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := s.repo.ReleaseUploadIntent(context.WithoutCancel(ctx), id); err != nil {
+			log.Printf("Error releasing upload intent %s: %v", id, err)
+		}
+	}()
+
+	// An earlier completion may have created the photo and failed to record it.
+	if _, err := s.photos.GetPhoto(ctx, userID, id); err == nil {
+		if err := s.repo.MarkUploadIntentCompleted(ctx, id); err != nil {
+			return "", err
+		}
+		return id.String(), nil
+	}
+
+	object, err := s.storage.HeadObject(ctx, intent.StorageKey)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return "", fmt.Errorf("%w: the file has not been uploaded yet", interfaces.ErrConflict)
+	}
+	if err != nil {
+		return "", err
+	}
+	if object.SizeBytes != intent.SizeBytes {
+		return "", fmt.Errorf("%w: the uploaded file has %d bytes, expected %d", interfaces.ErrInvalidArgument, object.SizeBytes, intent.SizeBytes)
+	}
+	header, err := s.storage.GetObjectHead(ctx, intent.StorageKey, min(object.SizeBytes, metadataHeaderBytes))
+	if err != nil {
+		return "", err
+	}
+	photoID, err := s.photos.CreateStoredPhoto(ctx, interfaces.CreateStoredPhotoRequest{
+		PhotoID:     intent.ID,
+		UserID:      intent.OwnerID,
+		Description: intent.Description,
+		FileName:    intent.FileName,
+		FileData:    header,
+		SizeBytes:   object.SizeBytes,
+		URL:         intent.StorageKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.MarkUploadIntentCompleted(ctx, id); err != nil {
+		return "", err
+	}
+	return photoID, nil
+}
+
+// CleanupExpired deletes intents that were not completed in time, along with any file
+// uploaded for them.
+func (s *UploadIntentService) CleanupExpired(ctx context.Context) (int, error) {
+	deleted := 0
+	for {
+		intents, err := s.repo.ListExpiredUploadIntents(ctx, expiredIntentBatch)
+		if err != nil {
+			return deleted, err
+		}
+		removed := 0
+		for _, intent := range intents {
+			// Keep the intent when its file cannot be removed, so the next run retries.
+			if err := s.storage.Delete(ctx, intent.StorageKey); err != nil {
+				log.Printf("Error removing file of expired upload intent %s: %v", intent.ID, err)
+				continue
+			}
+			if err := s.repo.DeleteUploadIntent(ctx, intent.ID); err != nil {
+				return deleted, err
+			}
+			removed++
+		}
+		deleted += removed
+		if len(intents) < expiredIntentBatch || removed == 0 {
+			return deleted, nil
+		}
+	}
+}
@@ -0,0 +1,504 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// webhookSecretMarker starts every signing secret, like apiKeyMarker for keys.
+	webhookSecretMarker = "whsec_"
+	webhookSecretBytes  = 32
+	maxWebhookURL       = 2048
+	// webhookDeliveryAttempts is how often a delivery is tried. With the worker pool's
+	// backoff the last attempt comes about an hour and a half after the first.
+	webhookDeliveryAttempts = 10
+	// maxWebhookResponseBody is how much of an endpoint's answer is kept in the log.
+	maxWebhookResponseBody = 1 << 10
+
+	webhookIDHeader        = "X-Webhook-Id"
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookPayload is the body POSTed to webhooks.
+type webhookPayload struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookService manages webhooks and sends them events. Each delivery is a job, so it
+// is retried with the worker pool's backoff; a webhook whose deliveries keep failing
+// every attempt is disabled.
+type WebhookService struct {
+	repo         interfaces.IWebhookRepository
+	client       *http.Client
+	disableAfter int
+	// allowInsecure lets webhooks use http and reach non-public addresses, for
+	// development.
+	allowInsecure bool
+}
+
+// NewWebhookService gives endpoints timeout to answer and disables them after
+// disableAfter deliveries in a row have failed. Unless allowInsecure is set, webhooks
+// must use https and may only reach public addresses: the owner reads the answers in the
+// delivery log, so the service must not be made to call into its own network.
+func NewWebhookService(repo interfaces.IWebhookRepository, timeout time.Duration, disableAfter int, allowInsecure bool) *WebhookService {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowInsecure {
+		// A proxy would make the connection on the service's behalf, unchecked.
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			// Checked for every connection, so a name that resolves to another address
+			// after it was validated is refused too.
+			Control: refuseNonPublicAddress,
+		}).DialContext
+	}
+	return &WebhookService{
+		repo: repo,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// A redirect is an answer like any other non-2xx status.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		disableAfter:  max(disableAfter, 1),
+		allowInsecure: allowInsecure,
+	}
+}
+
+// CreateWebhook registers an endpoint. The signing secret is only returned here.
+func (s *WebhookService) CreateWebhook(ctx context.Context, request interfaces.CreateWebhookRequest) (interfaces.CreatedWebhook, error) {
+	if err := s.validateWebhookURL(request.URL); err != nil {
+		return interfaces.CreatedWebhook{}, err
+	}
+	events, err := validateWebhookEvents(request.Events)
+	if err != nil {
+		return interfaces.CreatedWebhook{}, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return interfaces.CreatedWebhook{}, err
+	}
+	webhook, err := s.repo.CreateWebhook(ctx, interfaces.CreateWebhookRepoRequest{
+		OwnerID:  request.Owner.UserID,
+		APIKeyID: request.Owner.APIKeyID,
+		URL:      request.URL,
+		Secret:   secret,
+		Events:   events,
+	})
+	if err != nil {
+		return interfaces.CreatedWebhook{}, err
+	}
+	return interfaces.CreatedWebhook{Webhook: webhook, Secret: secret}, nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, owner interfaces.WebhookOwner) ([]interfaces.Webhook, error) {
+	return s.repo.ListWebhooks(ctx, owner.UserID, owner.APIKeyID)
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, owner interfaces.WebhookOwner, id uuid.UUID) (interfaces.Webhook, error) {
+	return s.ownedWebhook(ctx, owner, id)
+}
+
+func (s *WebhookService) UpdateWebhook(ctx context.Context, request interfaces.UpdateWebhookRequest) (interfaces.Webhook, error) {
+	if request.URL == nil && request.Events == nil && request.Enabled == nil {
+		return interfaces.Webhook{}, fmt.Errorf("%w: nothing to update", interfaces.ErrInvalidArgument)
+	}
+	repoRequest := interfaces.UpdateWebhookRepoRequest{
+		ID:      request.ID,
+		URL:     request.URL,
+		Enabled: request.Enabled,
+	}
+	if request.URL != nil {
+		if err := s.validateWebhookURL(*request.URL); err != nil {
+			return interfaces.Webhook{}, err
+		}
+	}
+	if request.Events != nil {
+		events, err := validateWebhookEvents(request.Events)
+		if err != nil {
+			return interfaces.Webhook{}, err
+		}
+		repoRequest.Events = events
+	}
+
+	if _, err := s.ownedWebhook(ctx, request.Owner, request.ID); err != nil {
+		return interfaces.Webhook{}, err
+	}
+	if err := s.repo.UpdateWebhook(ctx, repoRequest); err != nil {
+		return interfaces.Webhook{}, err
+	}
+	return s.repo.GetWebhook(ctx, request.ID)
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, owner interfaces.WebhookOwner, id uuid.UUID) error {
+	if _, err := s.ownedWebhook(ctx, owner, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteWebhook(ctx, id)
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, owner interfaces.WebhookOwner, webhookID uuid.UUID, limit int) ([]interfaces.WebhookDelivery, error) {
+	if _, err := s.ownedWebhook(ctx, owner, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListWebhookDeliveries(ctx, webhookID, limit)
+}
+
+// Redeliver queues the event of a delivery again. The new delivery keeps the event ID,
+// so receivers can tell it from a new event.
+func (s *WebhookService) Redeliver(ctx context.Context, owner interfaces.WebhookOwner, webhookID uuid.UUID, deliveryID uuid.UUID) (interfaces.WebhookDelivery, error) {
+	webhook, err := s.ownedWebhook(ctx, owner, webhookID)
+	if err != nil {
+		return interfaces.WebhookDelivery{}, err
+	}
+	if !webhook.Enabled {
+		return interfaces.WebhookDelivery{}, fmt.Errorf("%w: the webhook is disabled; enable it first", interfaces.ErrConflict)
+	}
+	delivery, err := s.repo.GetWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		return interfaces.WebhookDelivery{}, err
+	}
+	if delivery.WebhookID != webhookID {
+		return interfaces.WebhookDelivery{}, interfaces.ErrNotFound
+	}
+	return s.createDelivery(ctx, webhookID, delivery.EventID, delivery.EventType, delivery.Payload)
+}
+
+// Publish queues a delivery of the event to every webhook of the owner subscribed to it.
+func (s *WebhookService) Publish(ctx context.Context, event interfaces.Event) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	webhooks, err := s.repo.ListWebhooksForEvent(ctx, event.OwnerID, event.Type)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	payload, err := json.Marshal(webhookPayload{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Data,
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, webhook := range webhooks {
+		if _, err := s.createDelivery(ctx, webhook.ID, event.ID, event.Type, payload); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Deliver POSTs a delivery to its webhook once. Deliveries that were settled already,
+// or whose webhook is gone or disabled, are not sent.
+func (s *WebhookService) Deliver(ctx context.Context, deliveryID uuid.UUID) error {
+	delivery, err := s.repo.GetWebhookDelivery(ctx, deliveryID)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status != interfaces.WebhookDeliveryPending {
+		return nil
+	}
+	webhook, err := s.repo.GetWebhook(ctx, delivery.WebhookID)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !webhook.Enabled {
+		return s.repo.SetWebhookDeliveryStatus(ctx, deliveryID, interfaces.WebhookDeliveryFailed, "the webhook is disabled")
+	}
+
+	attempt := s.send(ctx, webhook, delivery)
+	if err := s.repo.RecordWebhookAttempt(ctx, deliveryID, attempt); err != nil {
+		return err
+	}
+	if attempt.Status != interfaces.WebhookDeliverySucceeded {
+		return errors.New(attempt.Error)
+	}
+	// The delivery went through; a failure to reset the count only matters if it keeps
+	// happening.
+	_ = s.repo.ResetWebhookFailures(ctx, webhook.ID)
+	return nil
+}
+
+// FailDelivery settles a delivery that failed its last attempt and counts it against the
+// webhook, which is disabled once too many deliveries in a row have failed.
+func (s *WebhookService) FailDelivery(ctx context.Context, deliveryID uuid.UUID, cause error) error {
+	delivery, err := s.repo.GetWebhookDelivery(ctx, deliveryID)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	lastError := ""
+	if delivery.LastError == "" && cause != nil {
+		lastError = cause.Error()
+	}
+	if err := s.repo.SetWebhookDeliveryStatus(ctx, deliveryID, interfaces.WebhookDeliveryFailed, lastError); err != nil {
+		return err
+	}
+	enabled, err := s.repo.RecordWebhookFailure(ctx, delivery.WebhookID, s.disableAfter)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		log.Printf("Webhook %s is disabled after %d failed deliveries in a row", delivery.WebhookID, s.disableAfter)
+	}
+	return nil
+}
+
+// DeliverWebhookJobs handles JobKindDeliverWebhook jobs with s.
+func DeliverWebhookJobs(s interfaces.IWebhookService) interfaces.JobHandler {
+	deliveryID := func(job interfaces.Job) (uuid.UUID, error) {
+		var payload interfaces.DeliverWebhookPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return uuid.Nil, err
+		}
+		return uuid.Parse(payload.DeliveryID)
+	}
+	return interfaces.JobHandler{
+		Run: func(ctx context.Context, job interfaces.Job) error {
+			id, err := deliveryID(job)
+			if err != nil {
+				return err
+			}
+			return s.Deliver(ctx, id)
+		},
+		Dead: func(ctx context.Context, job interfaces.Job, cause error) {
+			id, err := deliveryID(job)
+			if err != nil {
+				return
+			}
+			if err := s.FailDelivery(ctx, id, cause); err != nil {
+				log.Printf("Error failing webhook delivery %s: %v", id, err)
+			}
+		},
+	}
+}
+
+func (s *WebhookService) createDelivery(ctx context.Context, webhookID uuid.UUID, eventID uuid.UUID, eventType string, payload json.RawMessage) (interfaces.WebhookDelivery, error) {
+	id := uuid.New()
+	job, err := json.Marshal(interfaces.DeliverWebhookPayload{DeliveryID: id.String()})
+	if err != nil {
+		return interfaces.WebhookDelivery{}, err
+	}
+	return s.repo.CreateWebhookDelivery(ctx, interfaces.CreateWebhookDeliveryRepoRequest{
+		ID:         id,
+		EndpointID: webhookID,
+		EventID:    eventID,
+		EventType:  eventType,
+		Payload:    payload,
+		Job: interfaces.NewJob{
+			Kind:        interfaces.JobKindDeliverWebhook,
+			Payload:     job,
+			MaxAttempts: webhookDeliveryAttempts,
+		},
+	})
+}
+
+// send POSTs the payload, signed with the webhook's secret, and reports how it went. Any
+// 2xx answer counts as success.
+func (s *WebhookService) send(ctx context.Context, webhook interfaces.Webhook, delivery interfaces.WebhookDelivery) interfaces.WebhookAttempt {
+	failed := interfaces.WebhookAttempt{Status: interfaces.WebhookDeliveryPending}
+	// Webhooks registered before the rules were tightened are held to them too.
+	if err := s.validateWebhookURL(webhook.URL); err != nil {
+		failed.Error = err.Error()
+		return failed
+	}
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		failed.Error = err.Error()
+		return failed
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "photo-service-webhooks")
+	req.Header.Set(webhookIDHeader, delivery.ID.String())
+	req.Header.Set(webhookEventHeader, delivery.EventType)
+	req.Header.Set(webhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhookSignatureHeader, signWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		failed.Error = err.Error()
+		return failed
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+	// Drain the rest so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt := interfaces.WebhookAttempt{
+		Status:         interfaces.WebhookDeliverySucceeded,
+		ResponseStatus: resp.StatusCode,
+		ResponseBody:   string(bytes.ToValidUTF8(body, nil)),
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Status = interfaces.WebhookDeliveryPending
+		attempt.Error = fmt.Sprintf("the endpoint answered %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+func (s *WebhookService) ownedWebhook(ctx context.Context, owner interfaces.WebhookOwner, id uuid.UUID) (interfaces.Webhook, error) {
+	webhook, err := s.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return interfaces.Webhook{}, err
+	}
+	if webhook.OwnerID != owner.UserID {
+		return interfaces.Webhook{}, interfaces.ErrNotFound
+	}
+	// API keys only see their own webhooks.
+	if owner.APIKeyID != nil && (webhook.APIKeyID == nil || *webhook.APIKeyID != *owner.APIKeyID) {
+		return interfaces.Webhook{}, interfaces.ErrNotFound
+	}
+	return webhook, nil
+}
+
+// signWebhook signs "<timestamp>.<body>" with HMAC-SHA256. Receivers recompute it and
+// reject old timestamps, so a captured request cannot be replayed later.
+func signWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookService) validateWebhookURL(raw string) error {
+	schemes := "https"
+	if s.allowInsecure {
+		schemes = "http or https"
+	}
+	invalid := fmt.Errorf("%w: url must be an absolute %s URL of at most %d characters", interfaces.ErrInvalidArgument, schemes, maxWebhookURL)
+	if len(raw) > maxWebhookURL {
+		return invalid
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return invalid
+	}
+	if parsed.Scheme != "https" && !(s.allowInsecure && parsed.Scheme == "http") {
+		return invalid
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("%w: url cannot contain credentials", interfaces.ErrInvalidArgument)
+	}
+	if s.allowInsecure {
+		return nil
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: url must point to a public host", interfaces.ErrInvalidArgument)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublicAddr(addr) {
+		return fmt.Errorf("%w: url must point to a public host", interfaces.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// errNonPublicAddress refuses a webhook connection to the service's own network.
+var errNonPublicAddress = errors.New("webhook address is not public")
+
+// refuseNonPublicAddress is a net.Dialer Control function that only lets connections to
+// public addresses through.
+func refuseNonPublicAddress(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// isPublicAddr reports whether addr can be reached from the internet: not loopback,
+// private, link-local (which includes cloud metadata endpoints), unspecified or multicast.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
+
+// validateWebhookEvents checks the subscribed event types, dropping duplicates.
+func validateWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: subscribe to at least one event", interfaces.ErrInvalidArgument)
+	}
+	known := make(map[string]bool, len(interfaces.EventTypes))
+	for _, event := range interfaces.EventTypes {
+		known[event] = true
+	}
+	seen := make(map[string]bool, len(events))
+	valid := make([]string, 0, len(events))
+	for _, event := range events {
+		if !known[event] {
+			return nil, fmt.Errorf("%w: unknown event %q", interfaces.ErrInvalidArgument, event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			valid = append(valid, event)
+		}
+	}
+	return valid, nil
+}
+
+func newWebhookSecret() (string, error) {
+	raw := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("could not generate webhook secret")
+	}
+	return webhookSecretMarker + base64.RawURLEncoding.EncodeToString(raw), nil
+}
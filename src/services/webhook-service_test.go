@@ -0,0 +1,537 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"photo-service/src/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// fakeWebhookRepo keeps webhooks and deliveries in memory.
+type fakeWebhookRepo struct {
+	mu         sync.Mutex
+	webhooks   map[uuid.UUID]*interfaces.Webhook
+	deliveries map[uuid.UUID]*interfaces.WebhookDelivery
+	jobs       []interfaces.NewJob
+}
+
+func newFakeWebhookRepo() *fakeWebhookRepo {
+	return &fakeWebhookRepo{
+		webhooks:   map[uuid.UUID]*interfaces.Webhook{},
+		deliveries: map[uuid.UUID]*interfaces.WebhookDelivery{},
+	}
+}
+
+func (r *fakeWebhookRepo) CreateWebhook(ctx context.Context, req interfaces.CreateWebhookRepoRequest) (interfaces.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook := &interfaces.Webhook{
+		ID:        uuid.New(),
+		OwnerID:   req.OwnerID,
+		APIKeyID:  req.APIKeyID,
+		URL:       req.URL,
+		Events:    req.Events,
+		Enabled:   true,
+		CreatedAt: time.Now(),
+		Secret:    req.Secret,
+	}
+	r.webhooks[webhook.ID] = webhook
+	return *webhook, nil
+}
+
+func (r *fakeWebhookRepo) GetWebhook(ctx context.Context, id uuid.UUID) (interfaces.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return interfaces.Webhook{}, interfaces.ErrNotFound
+	}
+	return *webhook, nil
+}
+
+func (r *fakeWebhookRepo) ListWebhooks(ctx context.Context, ownerID uuid.UUID, apiKeyID *uuid.UUID) ([]interfaces.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var webhooks []interfaces.Webhook
+	for _, webhook := range r.webhooks {
+		if webhook.OwnerID == ownerID {
+			webhooks = append(webhooks, *webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (r *fakeWebhookRepo) ListWebhooksForEvent(ctx context.Context, ownerID uuid.UUID, eventType string) ([]interfaces.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var webhooks []interfaces.Webhook
+	for _, webhook := range r.webhooks {
+		if webhook.OwnerID != ownerID || !webhook.Enabled {
+			continue
+		}
+		for _, event := range webhook.Events {
+			if event == eventType {
+				webhooks = append(webhooks, *webhook)
+			}
+		}
+	}
+	return webhooks, nil
+}
+
+func (r *fakeWebhookRepo) UpdateWebhook(ctx context.Context, req interfaces.UpdateWebhookRepoRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook, ok := r.webhooks[req.ID]
+	if !ok {
+		return interfaces.ErrNotFound
+	}
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = req.Events
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+		webhook.ConsecutiveFailures = 0
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.webhooks, id)
+	return nil
+}
+
+func (r *fakeWebhookRepo) ResetWebhookFailures(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if webhook, ok := r.webhooks[id]; ok {
+		webhook.ConsecutiveFailures = 0
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) RecordWebhookFailure(ctx context.Context, id uuid.UUID, disableAfter int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return false, interfaces.ErrNotFound
+	}
+	webhook.ConsecutiveFailures++
+	if webhook.ConsecutiveFailures >= disableAfter {
+		webhook.Enabled = false
+	}
+	return webhook.Enabled, nil
+}
+
+func (r *fakeWebhookRepo) CreateWebhookDelivery(ctx context.Context, req interfaces.CreateWebhookDeliveryRepoRequest) (interfaces.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery := &interfaces.WebhookDelivery{
+		ID:        req.ID,
+		WebhookID: req.EndpointID,
+		EventID:   req.EventID,
+		EventType: req.EventType,
+		Payload:   req.Payload,
+		Status:    interfaces.WebhookDeliveryPending,
+		CreatedAt: time.Now(),
+	}
+	r.deliveries[delivery.ID] = delivery
+	r.jobs = append(r.jobs, req.Job)
+	return *delivery, nil
+}
+
+func (r *fakeWebhookRepo) GetWebhookDelivery(ctx context.Context, id uuid.UUID) (interfaces.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return interfaces.WebhookDelivery{}, interfaces.ErrNotFound
+	}
+	return *delivery, nil
+}
+
+func (r *fakeWebhookRepo) ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]interfaces.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deliveries []interfaces.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *fakeWebhookRepo) RecordWebhookAttempt(ctx context.Context, id uuid.UUID, attempt interfaces.WebhookAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return interfaces.ErrNotFound
+	}
+	now := time.Now()
+	delivery.Status = attempt.Status
+	delivery.Attempts++
+	delivery.ResponseStatus = nil
+	if attempt.ResponseStatus != 0 {
+		status := attempt.ResponseStatus
+		delivery.ResponseStatus = &status
+	}
+	delivery.ResponseBody = attempt.ResponseBody
+	delivery.LastError = attempt.Error
+	delivery.LastAttemptAt = &now
+	return nil
+}
+
+func (r *fakeWebhookRepo) SetWebhookDeliveryStatus(ctx context.Context, id uuid.UUID, status string, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return interfaces.ErrNotFound
+	}
+	delivery.Status = status
+	if lastError != "" {
+		delivery.LastError = lastError
+	}
+	return nil
+}
+
+// receivedWebhook is a request an httptest receiver got.
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts an httptest receiver answering with handler and recording requests.
+func newReceiver(t *testing.T, handler http.HandlerFunc) (*httptest.Server, <-chan receivedWebhook) {
+	t.Helper()
+	received := make(chan receivedWebhook, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedWebhook{header: r.Header.Clone(), body: body}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// newTestWebhookService allows insecure webhooks, since httptest receivers listen on
+// loopback over http.
+func newTestWebhookService(repo *fakeWebhookRepo, disableAfter int) *WebhookService {
+	return NewWebhookService(repo, 5*time.Second, disableAfter, true)
+}
+
+// publishTo registers a webhook for url and publishes one photo.created event to it.
+func publishTo(t *testing.T, s *WebhookService, repo *fakeWebhookRepo, url string) (interfaces.CreatedWebhook, interfaces.WebhookDelivery) {
+	t.Helper()
+	ctx := context.Background()
+	owner := interfaces.WebhookOwner{UserID: uuid.New()}
+	webhook, err := s.CreateWebhook(ctx, interfaces.CreateWebhookRequest{
+		Owner:  owner,
+		URL:    url,
+		Events: []string{interfaces.EventPhotoCreated},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	err = s.Publish(ctx, interfaces.Event{
+		Type:    interfaces.EventPhotoCreated,
+		OwnerID: owner.UserID,
+		Data:    interfaces.PhotoEventData{PhotoID: uuid.New(), Status: interfaces.PhotoStatusProcessing},
+	})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	deliveries, _ := repo.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	return webhook, deliveries[0]
+}
+
+func TestDeliverSignsTimestampAndBody(t *testing.T) {
+	server, received := newReceiver(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "thanks")
+	})
+	repo := newFakeWebhookRepo()
+	s := newTestWebhookService(repo, 5)
+	webhook, delivery := publishTo(t, s, repo, server.URL)
+
+	before := time.Now().Unix()
+	if err := s.Deliver(context.Background(), delivery.ID); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	request := <-received
+
+	timestamp, err := strconv.ParseInt(request.header.Get(webhookTimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header %q: %v", request.header.Get(webhookTimestampHeader), err)
+	}
+	if timestamp < before || timestamp > time.Now().Unix() {
+		t.Errorf("timestamp %d is not the time of sending", timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "." + string(request.body)))
+	want := "v1=" + hex.EncodeToString(mac.Sum(nil))
+	if got := request.header.Get(webhookSignatureHeader); got != want {
+		t.Errorf("signature %q, want %q", got, want)
+	}
+	if got := request.header.Get(webhookIDHeader); got != delivery.ID.String() {
+		t.Errorf("delivery ID header %q, want %s", got, delivery.ID)
+	}
+	if got := request.header.Get(webhookEventHeader); got != interfaces.EventPhotoCreated {
+		t.Errorf("event header %q", got)
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(request.body, &payload); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if payload.ID != delivery.EventID || payload.Type != interfaces.EventPhotoCreated {
+		t.Errorf("payload %+v does not describe event %s", payload, delivery.EventID)
+	}
+
+	settled, _ := repo.GetWebhookDelivery(context.Background(), delivery.ID)
+	if settled.Status != interfaces.WebhookDeliverySucceeded || settled.ResponseBody != "thanks" {
+		t.Errorf("delivery %+v, want succeeded with the answer logged", settled)
+	}
+}
+
+func TestDeliverCountsNon2xxAndRedirectsAsFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{"server error", http.StatusInternalServerError},
+		{"not found", http.StatusNotFound},
+		{"redirect", http.StatusFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			followed := make(chan struct{}, 1)
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				followed <- struct{}{}
+			}))
+			defer target.Close()
+			server, _ := newReceiver(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Location", target.URL)
+				w.WriteHeader(test.status)
+			})
+			repo := newFakeWebhookRepo()
+			s := newTestWebhookService(repo, 5)
+			_, delivery := publishTo(t, s, repo, server.URL)
+
+			if err := s.Deliver(context.Background(), delivery.ID); err == nil {
+				t.Fatal("Deliver succeeded, want an error")
+			}
+			select {
+			case <-followed:
+				t.Error("the redirect was followed")
+			default:
+			}
+			attempted, _ := repo.GetWebhookDelivery(context.Background(), delivery.ID)
+			if attempted.Status != interfaces.WebhookDeliveryPending {
+				t.Errorf("status %q, want pending for a retry", attempted.Status)
+			}
+			if attempted.ResponseStatus == nil || *attempted.ResponseStatus != test.status {
+				t.Errorf("response status %v, want %d", attempted.ResponseStatus, test.status)
+			}
+		})
+	}
+}
+
+func TestFailedDeliveryJobIsRetried(t *testing.T) {
+	server, _ := newReceiver(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	repo := newFakeWebhookRepo()
+	s := newTestWebhookService(repo, 5)
+	publishTo(t, s, repo, server.URL)
+
+	job := repo.jobs[0]
+	if job.Kind != interfaces.JobKindDeliverWebhook || job.MaxAttempts != webhookDeliveryAttempts {
+		t.Fatalf("queued job %+v", job)
+	}
+	handler := DeliverWebhookJobs(s)
+	if err := handler.Run(context.Background(), interfaces.Job{Kind: job.Kind, Payload: job.Payload}); err == nil {
+		t.Fatal("the job succeeded, want an error so it is retried")
+	}
+
+	// An endpoint that cannot be reached fails the same way.
+	server.Close()
+	if err := handler.Run(context.Background(), interfaces.Job{Kind: job.Kind, Payload: job.Payload}); err == nil {
+		t.Fatal("the job succeeded against a closed receiver")
+	}
+}
+
+func TestFailDeliveryDisablesWebhookAfterFailuresInARow(t *testing.T) {
+	server, received := newReceiver(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	repo := newFakeWebhookRepo()
+	const disableAfter = 3
+	s := newTestWebhookService(repo, disableAfter)
+	ctx := context.Background()
+	webhook, first := publishTo(t, s, repo, server.URL)
+
+	deliveries := []uuid.UUID{first.ID}
+	for i := 1; i <= disableAfter; i++ {
+		err := s.Publish(ctx, interfaces.Event{Type: interfaces.EventPhotoCreated, OwnerID: webhook.OwnerID})
+		if err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	all, _ := repo.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	for _, delivery := range all {
+		if delivery.ID != first.ID {
+			deliveries = append(deliveries, delivery.ID)
+		}
+	}
+
+	for i := 0; i < disableAfter; i++ {
+		cause := s.Deliver(ctx, deliveries[i])
+		if cause == nil {
+			t.Fatal("Deliver succeeded against a failing receiver")
+		}
+		if err := s.FailDelivery(ctx, deliveries[i], cause); err != nil {
+			t.Fatalf("FailDelivery: %v", err)
+		}
+		failed, _ := repo.GetWebhookDelivery(ctx, deliveries[i])
+		if failed.Status != interfaces.WebhookDeliveryFailed {
+			t.Errorf("delivery status %q, want failed", failed.Status)
+		}
+		current, _ := repo.GetWebhook(ctx, webhook.ID)
+		if wantEnabled := i+1 < disableAfter; current.Enabled != wantEnabled {
+			t.Fatalf("after %d failures enabled = %v, want %v", i+1, current.Enabled, wantEnabled)
+		}
+	}
+
+	// Deliveries of a disabled webhook are settled without being sent.
+	for len(received) > 0 {
+		<-received
+	}
+	last := deliveries[disableAfter]
+	if err := s.Deliver(ctx, last); err != nil {
+		t.Fatalf("Deliver to a disabled webhook: %v", err)
+	}
+	if len(received) != 0 {
+		t.Error("a disabled webhook was sent a delivery")
+	}
+	skipped, _ := repo.GetWebhookDelivery(ctx, last)
+	if skipped.Status != interfaces.WebhookDeliveryFailed {
+		t.Errorf("delivery status %q, want failed", skipped.Status)
+	}
+}
+
+func TestRedeliverKeepsEventID(t *testing.T) {
+	server, received := newReceiver(t, func(w http.ResponseWriter, r *http.Request) {})
+	repo := newFakeWebhookRepo()
+	s := newTestWebhookService(repo, 5)
+	ctx := context.Background()
+	webhook, delivery := publishTo(t, s, repo, server.URL)
+	owner := interfaces.WebhookOwner{UserID: webhook.OwnerID}
+	if err := s.Deliver(ctx, delivery.ID); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	first := <-received
+
+	again, err := s.Redeliver(ctx, owner, webhook.ID, delivery.ID)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if again.ID == delivery.ID || again.EventID != delivery.EventID {
+		t.Fatalf("redelivery %s of event %s, want a new delivery of event %s", again.ID, again.EventID, delivery.EventID)
+	}
+	if len(repo.jobs) != 2 {
+		t.Fatalf("%d jobs queued, want one more for the redelivery", len(repo.jobs))
+	}
+	if err := s.Deliver(ctx, again.ID); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	second := <-received
+	if string(second.body) != string(first.body) {
+		t.Errorf("redelivered body %s, want %s", second.body, first.body)
+	}
+	if second.header.Get(webhookIDHeader) == first.header.Get(webhookIDHeader) {
+		t.Error("the redelivery reused the delivery ID")
+	}
+
+	if _, err := s.Redeliver(ctx, interfaces.WebhookOwner{UserID: uuid.New()}, webhook.ID, delivery.ID); !errors.Is(err, interfaces.ErrNotFound) {
+		t.Errorf("another owner's redeliver error %v, want ErrNotFound", err)
+	}
+	disabled := false
+	if _, err := s.UpdateWebhook(ctx, interfaces.UpdateWebhookRequest{Owner: owner, ID: webhook.ID, Enabled: &disabled}); err != nil {
+		t.Fatalf("UpdateWebhook: %v", err)
+	}
+	if _, err := s.Redeliver(ctx, owner, webhook.ID, delivery.ID); !errors.Is(err, interfaces.ErrConflict) {
+		t.Errorf("redeliver to a disabled webhook error %v, want ErrConflict", err)
+	}
+}
+
+func TestWebhookURLsMustBePublicHTTPS(t *testing.T) {
+	s := NewWebhookService(newFakeWebhookRepo(), time.Second, 5, false)
+	refused := []string{
+		"http://example.com/hook",
+		"https://localhost/hook",
+		"https://127.0.0.1/hook",
+		"https://[::1]/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://10.1.2.3/hook",
+		"https://192.168.0.1/hook",
+		"https://0.0.0.0/hook",
+		"https://[::ffff:172.16.0.1]/hook",
+	}
+	for _, url := range refused {
+		if err := s.validateWebhookURL(url); !errors.Is(err, interfaces.ErrInvalidArgument) {
+			t.Errorf("%s: error %v, want ErrInvalidArgument", url, err)
+		}
+	}
+	if err := s.validateWebhookURL("https://example.com/hook"); err != nil {
+		t.Errorf("public https URL refused: %v", err)
+	}
+
+	insecure := NewWebhookService(newFakeWebhookRepo(), time.Second, 5, true)
+	if err := insecure.validateWebhookURL("http://127.0.0.1:8080/hook"); err != nil {
+		t.Errorf("local http URL refused with insecure webhooks allowed: %v", err)
+	}
+}
+
+func TestWebhookConnectionsToNonPublicAddressesAreRefused(t *testing.T) {
+	// Names are only resolved when connecting, so the check has to hold there too.
+	for _, address := range []string{"127.0.0.1:443", "[::1]:443", "169.254.169.254:80", "10.0.0.5:443", "[fd00::1]:443", "224.0.0.1:80"} {
+		if err := refuseNonPublicAddress("tcp", address, nil); !errors.Is(err, errNonPublicAddress) {
+			t.Errorf("%s: error %v, want errNonPublicAddress", address, err)
+		}
+	}
+	if err := refuseNonPublicAddress("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("public address refused: %v", err)
+	}
+
+	server, received := newReceiver(t, func(w http.ResponseWriter, r *http.Request) {})
+	s := NewWebhookService(newFakeWebhookRepo(), time.Second, 5, false)
+	attempt := s.send(context.Background(), interfaces.Webhook{URL: server.URL}, interfaces.WebhookDelivery{ID: uuid.New()})
+	if attempt.Status == interfaces.WebhookDeliverySucceeded {
+		t.Fatal("a loopback receiver was reached")
+	}
+	if len(received) != 0 {
+		t.Error("the loopback receiver got a request")
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"photo-service/src/interfaces"
+)
+
+const (
+	// jobTimeout bounds one attempt of a job. It stays under the lease the repository
+	// takes, so no other worker claims a job that is still running.
+	jobTimeout = 4 * time.Minute
+	// jobBaseBackoff is the delay before the first retry; it doubles with every attempt
+	// up to jobMaxBackoff.
+	jobBaseBackoff = 10 * time.Second
+	jobMaxBackoff  = time.Hour
+)
+
+// WorkerPool runs queued jobs on a fixed number of workers. Failed jobs are retried with
+// exponential backoff; a job that fails its last attempt is moved to the dead state.
+type WorkerPool struct {
+	repo         interfaces.IJobRepository
+	workers      int
+	pollInterval time.Duration
+	handlers     map[string]interfaces.JobHandler
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewWorkerPool polls for jobs every pollInterval while the queue is empty.
+func NewWorkerPool(repo interfaces.IJobRepository, workers int, pollInterval time.Duration) *WorkerPool {
+	return &WorkerPool{
+		repo:         repo,
+		workers:      max(workers, 1),
+		pollInterval: pollInterval,
+		handlers:     map[string]interfaces.JobHandler{},
+		stop:         make(chan struct{}),
+	}
+}
+
+// Register sets the handler of a job kind. Handlers are registered before Start.
+func (p *WorkerPool) Register(kind string, handler interfaces.JobHandler) {
+	p.handlers[kind] = handler
+}
+
+func (p *WorkerPool) Start() {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+}
+
+// Shutdown stops claiming jobs and waits for running ones to finish, or for ctx to end.
+// Jobs cut short are claimed again once their lease runs out.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	close(p.stop)
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+		jobs, err := p.repo.ClaimJobs(context.Background(), 1)
+		if err != nil || len(jobs) == 0 {
+			select {
+			case <-p.stop:
+				return
+			case <-time.After(p.pollInterval):
+			}
+			continue
+		}
+		for _, job := range jobs {
+			p.run(job)
+		}
+	}
+}
+
+func (p *WorkerPool) run(job interfaces.Job) {
+	ctx := context.Background()
+	handler, ok := p.handlers[job.Kind]
+	if !ok {
+		log.Printf("No handler for job %d of kind %q", job.ID, job.Kind)
+		if err := p.repo.BuryJob(ctx, job.ID, "unknown job kind"); err != nil {
+			log.Printf("Error burying job %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	err := p.attempt(ctx, handler, job)
+	if err == nil {
+		if err := p.repo.CompleteJob(ctx, job.ID); err != nil {
+			log.Printf("Error completing job %d: %v", job.ID, err)
+		}
+		return
+	}
+	if !job.LastAttempt() {
+		delay := jobBackoff(job.Attempts)
+		log.Printf("Job %d of kind %q failed attempt %d of %d, retrying in %v: %v", job.ID, job.Kind, job.Attempts, job.MaxAttempts, delay, err)
+		if err := p.repo.RetryJob(ctx, job.ID, delay, err.Error()); err != nil {
+			log.Printf("Error rescheduling job %d: %v", job.ID, err)
+		}
+		return
+	}
+	log.Printf("Job %d of kind %q failed its last attempt: %v", job.ID, job.Kind, err)
+	if err := p.repo.BuryJob(ctx, job.ID, err.Error()); err != nil {
+		log.Printf("Error burying job %d: %v", job.ID, err)
+		return
+	}
+	if handler.Dead != nil {
+		handler.Dead(ctx, job, err)
+	}
+}
+
+// attempt runs a job once, turning a panic into an error.
+func (p *WorkerPool) attempt(ctx context.Context, handler interfaces.JobHandler, job interfaces.Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler.Run(ctx, job)
+}
+
+// jobBackoff is the delay after the given failed attempt, with up to a fifth of jitter so
+// jobs that failed together do not retry together.
+func jobBackoff(attempt int) time.Duration {
+	delay := jobMaxBackoff
+	if shift := max(attempt-1, 0); shift < 20 {
+		delay = min(jobBaseBackoff<<shift, jobMaxBackoff)
+	}
+	return delay - time.Duration(rand.Int64N(int64(delay/5)+1))
+}
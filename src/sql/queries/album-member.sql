@@ -0,0 +1,33 @@
+-- name: UpsertAlbumMember :one
+INSERT INTO album_member (album_id, user_id, role, added_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (album_id, user_id) DO UPDATE
+SET role = EXCLUDED.role,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetAlbumMember :one
+SELECT * FROM album_member
+WHERE album_id = $1
+  AND user_id = $2;
+
+-- name: ListAlbumMembers :many
+SELECT * FROM album_member
+WHERE album_id = $1
+ORDER BY created_at, user_id;
+
+-- name: DeleteAlbumMember :execrows
+DELETE FROM album_member
+WHERE album_id = $1
+  AND user_id = $2;
+
+-- name: IsPhotoSharedWithUser :one
+-- A photo is shared with everyone who owns or is a member of an album it is in.
+SELECT EXISTS (
+    SELECT 1
+    FROM album_photo ap
+    JOIN album a ON a.id = ap.album_id
+    LEFT JOIN album_member am ON am.album_id = a.id AND am.user_id = sqlc.arg(user_id)
+    WHERE ap.photo_id = sqlc.arg(photo_id)
+      AND (a.owner_id = sqlc.arg(user_id) OR am.user_id IS NOT NULL)
+)::boolean AS shared;
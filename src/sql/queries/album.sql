@@ -0,0 +1,146 @@
+-- name: CreateAlbum :one
+INSERT INTO album (owner_id, title, description)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetAlbum :one
+SELECT
+    a.id,
+    a.owner_id,
+    a.title,
+    a.description,
+    (SELECT p.id FROM photo p WHERE p.id = a.cover_photo_id AND p.deleted_at IS NULL) AS cover_photo_id,
+    a.created_at,
+    a.updated_at,
+    (
+        SELECT count(*) FROM album_photo ap
+        JOIN photo p ON p.id = ap.photo_id
+        WHERE ap.album_id = a.id AND p.deleted_at IS NULL
+    )::integer AS photo_count
+FROM album a
+WHERE a.id = $1;
+
+-- name: GetAlbumForUpdate :one
+SELECT id, owner_id, title, description, cover_photo_id, created_at, updated_at
+FROM album
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListAlbums :many
+-- Lists the albums the user owns or is a member of, with the user's role in each.
+SELECT
+    a.id,
+    a.owner_id,
+    a.title,
+    a.description,
+    (SELECT p.id FROM photo p WHERE p.id = a.cover_photo_id AND p.deleted_at IS NULL) AS cover_photo_id,
+    a.created_at,
+    a.updated_at,
+    (
+        SELECT count(*) FROM album_photo ap
+        JOIN photo p ON p.id = ap.photo_id
+        WHERE ap.album_id = a.id AND p.deleted_at IS NULL
+    )::integer AS photo_count,
+    COALESCE(am.role, 'owner')::text AS role
+FROM album a
+LEFT JOIN album_member am ON am.album_id = a.id AND am.user_id = sqlc.arg(user_id)
+WHERE a.owner_id = sqlc.arg(user_id)
+   OR am.user_id IS NOT NULL
+ORDER BY a.created_at DESC, a.id;
+
+-- name: UpdateAlbum :execrows
+UPDATE album
+SET title = COALESCE(sqlc.narg(title), title),
+    description = CASE WHEN sqlc.arg(set_description)::boolean THEN sqlc.narg(description) ELSE description END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteAlbum :execrows
+DELETE FROM album
+WHERE id = $1;
+
+-- name: AddAlbumPhotos :execrows
+-- Appends the photos in the given order; photos already in the album are skipped.
+INSERT INTO album_photo (album_id, photo_id, position)
+SELECT
+    sqlc.arg(album_id),
+    u.photo_id,
+    (SELECT COALESCE(max(ap.position), 0) FROM album_photo ap WHERE ap.album_id = sqlc.arg(album_id)) + u.ordinality
+FROM unnest(sqlc.arg(photo_ids)::uuid[]) WITH ORDINALITY AS u(photo_id, ordinality)
+JOIN photo p ON p.id = u.photo_id
+WHERE p.owner_id = sqlc.arg(owner_id)
+  AND p.deleted_at IS NULL
+ON CONFLICT (album_id, photo_id) DO NOTHING;
+
+-- name: RemoveAlbumPhoto :execrows
+DELETE FROM album_photo
+WHERE album_id = $1
+  AND photo_id = $2;
+
+-- name: ClearAlbumCover :exec
+UPDATE album
+SET cover_photo_id = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND cover_photo_id = $2;
+
+-- name: SetAlbumCover :execrows
+UPDATE album
+SET cover_photo_id = sqlc.arg(photo_id)::uuid,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(album_id)
+  AND EXISTS (
+    SELECT 1 FROM album_photo ap
+    JOIN photo p ON p.id = ap.photo_id
+    WHERE ap.album_id = sqlc.arg(album_id)
+      AND ap.photo_id = sqlc.arg(photo_id)
+      AND p.deleted_at IS NULL
+  );
+
+-- name: ListAlbumPhotoIDs :many
+SELECT ap.photo_id
+FROM album_photo ap
+JOIN photo p ON p.id = ap.photo_id
+WHERE ap.album_id = $1
+  AND p.deleted_at IS NULL;
+
+-- name: ReorderAlbumPhotos :execrows
+-- Numbers the photos in the given order. Photos in the trash are not listed; they follow,
+-- in their previous order, so they come back after the others when restored.
+WITH ordered AS (
+    SELECT u.photo_id, u.ordinality AS position
+    FROM unnest(sqlc.arg(photo_ids)::uuid[]) WITH ORDINALITY AS u(photo_id, ordinality)
+    UNION ALL
+    SELECT ap.photo_id,
+           cardinality(sqlc.arg(photo_ids)::uuid[]) + row_number() OVER (ORDER BY ap.position, ap.added_at, ap.photo_id)
+    FROM album_photo ap
+    JOIN photo p ON p.id = ap.photo_id
+    WHERE ap.album_id = sqlc.arg(album_id)
+      AND p.deleted_at IS NOT NULL
+)
+UPDATE album_photo ap
+SET position = ordered.position
+FROM ordered
+WHERE ap.album_id = sqlc.arg(album_id)
+  AND ap.photo_id = ordered.photo_id;
+
+-- name: ListAlbumPhotos :many
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private,
+    ap.position
+FROM album_photo ap
+JOIN photo p ON p.id = ap.photo_id
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE ap.album_id = $1
+  AND p.deleted_at IS NULL
+ORDER BY ap.position, ap.added_at, p.id
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,24 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_key (name, key_prefix, key_hash, scopes, owner_id, created_by, expires_at, on_behalf_of)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_key
+WHERE key_hash = $1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_key
+ORDER BY created_at DESC, id;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_key
+SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+WHERE id = $1;
+
+-- name: TouchAPIKey :exec
+-- Records a use of the key, at most once a minute to keep writes off the request path.
+UPDATE api_key
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute');
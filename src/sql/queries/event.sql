@@ -0,0 +1,14 @@
+-- name: LockEventSeq :exec
+-- Held until the transaction ends. Notifications are sent at commit, so publishers take
+-- turns to keep events arriving in the order they were numbered.
+SELECT pg_advisory_xact_lock(hashtext('event_seq'));
+
+-- name: NotifyEvent :one
+-- Numbers an event and sends it to every instance listening on the events channel.
+WITH next AS (SELECT nextval('event_seq') AS seq)
+SELECT next.seq
+FROM next, pg_notify('events', json_build_object('seq', next.seq, 'event', sqlc.arg(event)::json)::text);
+
+-- name: CurrentEventSeq :one
+-- The number of the latest event; events published from now on are numbered higher.
+SELECT last_value FROM event_seq;
@@ -0,0 +1,51 @@
+-- name: ClaimIdempotencyKey :one
+-- Takes the key for a new request. A key already taken is only taken over when it has
+-- expired, or when the request holding it stopped without finishing and this is a retry
+-- of it. Returns no row when the key is not free.
+INSERT INTO idempotency_key (owner_id, scope, key, fingerprint, locked_until)
+VALUES (
+    sqlc.arg(owner_id),
+    sqlc.arg(scope),
+    sqlc.arg(key),
+    sqlc.arg(fingerprint),
+    CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(lease_seconds)::double precision)
+)
+ON CONFLICT (owner_id, scope, key) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint,
+    response_status = NULL,
+    response_content_type = NULL,
+    response_body = NULL,
+    response_location = NULL,
+    locked_until = EXCLUDED.locked_until,
+    completed_at = NULL,
+    created_at = CURRENT_TIMESTAMP
+WHERE idempotency_key.created_at < sqlc.arg(expired_before)
+   OR (idempotency_key.completed_at IS NULL
+       AND idempotency_key.locked_until < CURRENT_TIMESTAMP
+       AND idempotency_key.fingerprint = EXCLUDED.fingerprint)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_key
+WHERE owner_id = $1 AND scope = $2 AND key = $3;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_key
+SET response_status = sqlc.arg(response_status),
+    response_content_type = sqlc.arg(response_content_type),
+    response_body = sqlc.arg(response_body),
+    response_location = sqlc.arg(response_location),
+    locked_until = NULL,
+    completed_at = CURRENT_TIMESTAMP
+WHERE owner_id = sqlc.arg(owner_id) AND scope = sqlc.arg(scope) AND key = sqlc.arg(key);
+
+-- name: ReleaseIdempotencyKey :exec
+-- Frees the key of a request that did not finish, so a retry can run it again.
+DELETE FROM idempotency_key
+WHERE owner_id = $1 AND scope = $2 AND key = $3
+  AND completed_at IS NULL;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_key
+WHERE created_at < $1
+  AND (completed_at IS NOT NULL OR locked_until < CURRENT_TIMESTAMP);
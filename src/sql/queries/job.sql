@@ -0,0 +1,43 @@
+-- name: EnqueueJob :one
+INSERT INTO job (kind, payload, max_attempts)
+VALUES ($1, $2, $3)
+RETURNING id;
+
+-- name: ClaimJobs :many
+-- Claims up to max_jobs runnable jobs, oldest first: pending jobs that are due and running
+-- jobs whose worker let the lock lapse. Jobs claimed by other workers are skipped.
+UPDATE job
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_until = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(lease_seconds)::double precision),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id FROM job
+    WHERE (status = 'pending' AND run_at <= CURRENT_TIMESTAMP)
+       OR (status = 'running' AND locked_until < CURRENT_TIMESTAMP)
+    ORDER BY run_at
+    LIMIT sqlc.arg(max_jobs)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+DELETE FROM job
+WHERE id = $1;
+
+-- name: RetryJob :exec
+UPDATE job
+SET status = 'pending',
+    run_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(delay_seconds)::double precision),
+    locked_until = NULL,
+    last_error = sqlc.arg(last_error),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: BuryJob :exec
+UPDATE job
+SET status = 'dead',
+    locked_until = NULL,
+    last_error = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
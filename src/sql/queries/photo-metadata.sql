@@ -1,4 +1,106 @@
 -- name: CreatePhotoMetadata :one
-INSERT INTO photo_metadata (id, location, created_at)
-VALUES ($1, ST_MakePoint($2::double precision, $3::double precision), $4)
+-- Re-extraction keeps manual edits and never replaces a known value with NULL.
+INSERT INTO photo_metadata AS pm (id, location, location_source, altitude, created_at, created_at_source, camera_make, camera_model)
+VALUES (
+    sqlc.arg(id),
+    ST_SetSRID(ST_MakePoint(sqlc.narg(longitude)::double precision, sqlc.narg(latitude)::double precision), 4326)::geography,
+    CASE WHEN sqlc.narg(longitude)::double precision IS NOT NULL THEN sqlc.arg(source)::varchar END,
+    sqlc.narg(altitude)::double precision,
+    sqlc.narg(created_at)::timestamp,
+    CASE WHEN sqlc.narg(created_at)::timestamp IS NOT NULL THEN sqlc.arg(source)::varchar END,
+    sqlc.narg(camera_make),
+    sqlc.narg(camera_model)
+)
+ON CONFLICT (id) DO UPDATE SET
+    location = CASE WHEN pm.location_source = 'manual' OR EXCLUDED.location IS NULL THEN pm.location ELSE EXCLUDED.location END,
+    location_source = CASE WHEN pm.location_source = 'manual' OR EXCLUDED.location IS NULL THEN pm.location_source ELSE EXCLUDED.location_source END,
+    altitude = CASE WHEN pm.location_source = 'manual' OR EXCLUDED.location IS NULL THEN pm.altitude ELSE EXCLUDED.altitude END,
+    created_at = CASE WHEN pm.created_at_source = 'manual' OR EXCLUDED.created_at IS NULL THEN pm.created_at ELSE EXCLUDED.created_at END,
+    created_at_source = CASE WHEN pm.created_at_source = 'manual' OR EXCLUDED.created_at IS NULL THEN pm.created_at_source ELSE EXCLUDED.created_at_source END,
+    camera_make = COALESCE(EXCLUDED.camera_make, pm.camera_make),
+    camera_model = COALESCE(EXCLUDED.camera_model, pm.camera_model)
 RETURNING *;
+
+-- name: GetPhotoMetadata :one
+SELECT
+    pm.id,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.location_source,
+    pm.created_at,
+    pm.created_at_source
+FROM photo_metadata pm
+WHERE pm.id = $1;
+
+-- name: UpdatePhotoMetadataManually :exec
+-- Only the fields flagged with set_* are touched; they are marked as manual edits.
+INSERT INTO photo_metadata AS pm (id, location, location_source, created_at, created_at_source)
+VALUES (
+    sqlc.arg(id),
+    ST_SetSRID(ST_MakePoint(sqlc.narg(longitude)::double precision, sqlc.narg(latitude)::double precision), 4326)::geography,
+    CASE WHEN sqlc.arg(set_location)::boolean THEN 'manual' END,
+    sqlc.narg(created_at)::timestamp,
+    CASE WHEN sqlc.arg(set_created_at)::boolean THEN 'manual' END
+)
+ON CONFLICT (id) DO UPDATE SET
+    location = CASE WHEN sqlc.arg(set_location)::boolean THEN EXCLUDED.location ELSE pm.location END,
+    location_source = CASE WHEN sqlc.arg(set_location)::boolean THEN EXCLUDED.location_source ELSE pm.location_source END,
+    altitude = CASE WHEN sqlc.arg(set_location)::boolean THEN NULL ELSE pm.altitude END,
+    created_at = CASE WHEN sqlc.arg(set_created_at)::boolean THEN EXCLUDED.created_at ELSE pm.created_at END,
+    created_at_source = CASE WHEN sqlc.arg(set_created_at)::boolean THEN EXCLUDED.created_at_source ELSE pm.created_at_source END;
+
+-- name: ListNearbyPhotos :many
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    ST_Y(pm.location::geometry)::double precision AS latitude,
+    ST_X(pm.location::geometry)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    ST_Distance(pm.location, origin.location)::double precision AS distance_m,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private
+FROM photo_metadata origin
+JOIN photo_metadata pm ON pm.id <> origin.id
+JOIN photo p ON p.id = pm.id
+WHERE origin.id = sqlc.arg(photo_id)
+  AND p.owner_id = sqlc.arg(viewer_id)
+  AND p.deleted_at IS NULL
+  AND pm.location IS NOT NULL
+  AND ST_DWithin(pm.location, origin.location, sqlc.arg(radius_m)::double precision)
+  AND (
+    sqlc.narg(time_window_seconds)::double precision IS NULL
+    OR pm.created_at BETWEEN origin.created_at - make_interval(secs => sqlc.narg(time_window_seconds)::double precision)
+                         AND origin.created_at + make_interval(secs => sqlc.narg(time_window_seconds)::double precision)
+  )
+ORDER BY distance_m, p.id
+LIMIT sqlc.arg(max_results);
+
+-- name: ListPhotosWithoutLocation :many
+SELECT p.id, pm.created_at AS captured_at
+FROM photo p
+JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.owner_id = sqlc.arg(owner_id)
+  AND p.deleted_at IS NULL
+  AND pm.location IS NULL
+  AND pm.location_source IS DISTINCT FROM 'manual'
+  AND pm.created_at IS NOT NULL
+ORDER BY pm.created_at, p.id;
+
+-- name: SetPhotoLocations :execrows
+UPDATE photo_metadata pm
+SET location = ST_SetSRID(ST_MakePoint(u.longitude, u.latitude), 4326)::geography,
+    location_source = 'gpx'
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(longitudes)::double precision[],
+    sqlc.arg(latitudes)::double precision[]
+) AS u(id, longitude, latitude)
+JOIN photo p ON p.id = u.id
+WHERE pm.id = u.id
+  AND p.owner_id = sqlc.arg(owner_id)
+  AND p.deleted_at IS NULL
+  AND pm.location IS NULL
+  AND pm.location_source IS DISTINCT FROM 'manual';
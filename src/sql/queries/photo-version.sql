@@ -0,0 +1,87 @@
+-- name: CreatePhotoVersion :one
+INSERT INTO photo_version (photo_id, version, storage_key, storage_version_id, sha256, size_bytes, format)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetPhotoForNewVersion :one
+-- Locks a photo that is not in the trash while a version is added.
+SELECT
+    p.id,
+    p.owner_id,
+    (SELECT COALESCE(max(v.version), 0) FROM photo_version v WHERE v.photo_id = p.id)::integer AS latest_version
+FROM photo p
+WHERE p.id = $1
+  AND p.deleted_at IS NULL
+FOR UPDATE;
+
+-- name: SetPhotoCurrentVersion :execrows
+-- Makes a version current; the photo is processing until the metadata of its file has
+-- been extracted again.
+UPDATE photo p
+SET current_version = v.version,
+    photo_url = sqlc.arg(photo_url),
+    size_bytes = v.size_bytes,
+    format = v.format,
+    status = 'processing',
+    updated_at = CURRENT_TIMESTAMP
+FROM photo_version v
+WHERE v.photo_id = p.id
+  AND p.id = sqlc.arg(photo_id)
+  AND v.version = sqlc.arg(version)
+  AND p.deleted_at IS NULL;
+
+-- name: GetPhotoVersion :one
+SELECT
+    v.photo_id,
+    v.version,
+    v.storage_key,
+    v.storage_version_id,
+    v.sha256,
+    v.size_bytes,
+    v.format,
+    v.metadata,
+    v.created_at,
+    (v.version = p.current_version)::boolean AS current
+FROM photo_version v
+JOIN photo p ON p.id = v.photo_id
+WHERE v.photo_id = $1
+  AND v.version = $2;
+
+-- name: ListPhotoVersions :many
+SELECT
+    v.photo_id,
+    v.version,
+    v.storage_key,
+    v.storage_version_id,
+    v.sha256,
+    v.size_bytes,
+    v.format,
+    v.metadata,
+    v.created_at,
+    (v.version = p.current_version)::boolean AS current
+FROM photo_version v
+JOIN photo p ON p.id = v.photo_id
+WHERE v.photo_id = $1
+ORDER BY v.version DESC;
+
+-- name: SetPhotoVersionMetadata :exec
+UPDATE photo_version
+SET metadata = $3
+WHERE photo_id = $1
+  AND version = $2;
+
+-- name: SetPhotoVersionSHA256 :exec
+-- Records the digest of a file that was stored without one.
+UPDATE photo_version
+SET sha256 = $3
+WHERE photo_id = $1
+  AND version = $2
+  AND sha256 IS NULL;
+
+-- name: SetPhotoVersionStorageID :exec
+-- Records the object version of a file stored before it was known.
+UPDATE photo_version
+SET storage_version_id = $3
+WHERE photo_id = $1
+  AND version = $2
+  AND storage_version_id IS NULL;
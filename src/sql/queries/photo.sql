@@ -1,4 +1,162 @@
 -- name: CreatePhoto :one
-INSERT INTO photo (owner_id, description, photo_url)
-VALUES ($1, $2, $3)
+INSERT INTO photo (id, owner_id, description, photo_url, search_language, format, size_bytes, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
+
+-- name: TrashPhoto :execrows
+UPDATE photo
+SET deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND deleted_at IS NULL;
+
+-- name: RestorePhoto :one
+UPDATE photo
+SET deleted_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND owner_id = $2
+  AND deleted_at IS NOT NULL
+RETURNING status;
+
+-- name: ListTrashedPhotos :many
+SELECT
+    p.id,
+    p.description,
+    p.photo_url,
+    p.status,
+    p.size_bytes,
+    p.created_at,
+    p.deleted_at::timestamp AS deleted_at
+FROM photo p
+WHERE p.owner_id = $1
+  AND p.deleted_at IS NOT NULL
+ORDER BY p.deleted_at DESC, p.id
+LIMIT $2 OFFSET $3;
+
+-- name: PurgePhotos :many
+-- Deletes up to max_photos trashed photos, of one owner when owner_id is set and only
+-- those deleted before deleted_before when that is set. Photos another purge is deleting
+-- are skipped. The statement's snapshot still shows the versions of the deleted photos,
+-- which give the bytes they stored and their files.
+WITH purged AS (
+    DELETE FROM photo
+    WHERE id IN (
+        SELECT p.id
+        FROM photo p
+        WHERE p.deleted_at IS NOT NULL
+          AND (sqlc.narg(owner_id)::uuid IS NULL OR p.owner_id = sqlc.narg(owner_id))
+          AND (sqlc.narg(deleted_before)::timestamp IS NULL OR p.deleted_at < sqlc.narg(deleted_before))
+        ORDER BY p.deleted_at
+        LIMIT sqlc.arg(max_photos)
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, owner_id, photo_url, size_bytes
+)
+SELECT
+    purged.id,
+    purged.owner_id,
+    purged.photo_url,
+    COALESCE(
+        (SELECT sum(v.size_bytes) FROM photo_version v WHERE v.photo_id = purged.id),
+        purged.size_bytes
+    )::bigint AS stored_bytes,
+    COALESCE(
+        (SELECT sum(v.size_bytes) FROM photo_version v WHERE v.photo_id = purged.id AND v.version > 1),
+        0
+    )::bigint AS version_bytes,
+    ARRAY(
+        SELECT DISTINCT v.storage_key FROM photo_version v WHERE v.photo_id = purged.id
+    )::text[] AS storage_keys
+FROM purged;
+
+-- name: SetPhotoStatus :exec
+UPDATE photo
+SET status = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: GetPhotoWithLocation :one
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.current_version,
+    v.sha256 AS current_sha256,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    pm.created_at AS captured_at
+FROM photo p
+LEFT JOIN photo_version v ON v.photo_id = p.id AND v.version = p.current_version
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.id = $1
+  AND p.deleted_at IS NULL;
+
+-- name: GetPhotoDetail :one
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.status,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private
+FROM photo p
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.id = $1
+  AND p.deleted_at IS NULL;
+
+-- name: ListPhotos :many
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.status,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private
+FROM photo p
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.owner_id = $1
+  AND p.deleted_at IS NULL
+ORDER BY p.created_at DESC, p.id
+LIMIT $2 OFFSET $3;
+
+-- name: SearchPhotos :many
+-- Full-text search over the owner's photo descriptions, best matches first.
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.status,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private,
+    ts_rank(p.search_vector, q.query)::double precision AS rank,
+    ts_headline(
+        sqlc.arg(search_language)::regconfig,
+        escape_html(coalesce(p.description, '')),
+        q.query,
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5'
+    )::text AS headline
+FROM photo p
+CROSS JOIN to_tsquery(sqlc.arg(search_language)::regconfig, sqlc.arg(query)::text) AS q(query)
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.owner_id = sqlc.arg(owner_id)
+  AND p.deleted_at IS NULL
+  AND p.search_vector @@ q.query
+ORDER BY rank DESC, p.created_at DESC, p.id
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
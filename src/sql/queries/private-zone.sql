@@ -0,0 +1,69 @@
+-- name: CreatePrivateZone :one
+-- Circles are stored as a buffered polygon; polygons are given as GeoJSON.
+INSERT INTO private_zone (owner_id, name, kind, area, radius_m)
+VALUES (
+    sqlc.arg(owner_id),
+    sqlc.arg(name),
+    sqlc.arg(kind),
+    CASE WHEN sqlc.arg(kind)::varchar = 'circle'
+        THEN ST_Buffer(
+            ST_SetSRID(ST_MakePoint(sqlc.narg(center_longitude)::double precision, sqlc.narg(center_latitude)::double precision), 4326)::geography,
+            sqlc.narg(radius_m)::double precision
+        )
+        ELSE ST_GeomFromGeoJSON(sqlc.narg(polygon_geojson)::text)::geography
+    END,
+    sqlc.narg(radius_m)::double precision
+)
+RETURNING id;
+
+-- name: GetPrivateZone :one
+SELECT
+    z.id,
+    z.owner_id,
+    z.name,
+    z.kind,
+    z.radius_m,
+    ST_AsGeoJSON(z.area)::text AS area_geojson,
+    ST_Y(ST_Centroid(z.area::geometry))::double precision AS center_latitude,
+    ST_X(ST_Centroid(z.area::geometry))::double precision AS center_longitude,
+    z.created_at,
+    z.updated_at
+FROM private_zone z
+WHERE z.id = $1;
+
+-- name: ListPrivateZones :many
+SELECT
+    z.id,
+    z.owner_id,
+    z.name,
+    z.kind,
+    z.radius_m,
+    ST_AsGeoJSON(z.area)::text AS area_geojson,
+    ST_Y(ST_Centroid(z.area::geometry))::double precision AS center_latitude,
+    ST_X(ST_Centroid(z.area::geometry))::double precision AS center_longitude,
+    z.created_at,
+    z.updated_at
+FROM private_zone z
+WHERE z.owner_id = $1
+ORDER BY z.created_at, z.id;
+
+-- name: UpdatePrivateZone :execrows
+UPDATE private_zone
+SET name = sqlc.arg(name),
+    kind = sqlc.arg(kind),
+    area = CASE WHEN sqlc.arg(kind)::varchar = 'circle'
+        THEN ST_Buffer(
+            ST_SetSRID(ST_MakePoint(sqlc.narg(center_longitude)::double precision, sqlc.narg(center_latitude)::double precision), 4326)::geography,
+            sqlc.narg(radius_m)::double precision
+        )
+        ELSE ST_GeomFromGeoJSON(sqlc.narg(polygon_geojson)::text)::geography
+    END,
+    radius_m = sqlc.narg(radius_m)::double precision,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+  AND owner_id = sqlc.arg(owner_id);
+
+-- name: DeletePrivateZone :execrows
+DELETE FROM private_zone
+WHERE id = $1
+  AND owner_id = $2;
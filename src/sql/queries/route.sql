@@ -0,0 +1,96 @@
+-- name: ListRouteSegments :many
+-- One segment per capture day, or a single segment for the whole range when per_trip is set.
+WITH points AS (
+    SELECT
+        p.id,
+        pm.created_at AS captured_at,
+        pm.location,
+        pm.altitude,
+        CASE WHEN sqlc.arg(per_trip)::boolean
+            THEN sqlc.arg(from_time)::timestamp::date
+            ELSE pm.created_at::date
+        END AS segment_day
+    FROM photo p
+    JOIN photo_metadata pm ON pm.id = p.id
+    WHERE p.owner_id = sqlc.arg(owner_id)
+      AND p.deleted_at IS NULL
+      AND pm.location IS NOT NULL
+      AND pm.created_at >= sqlc.arg(from_time)::timestamp
+      AND pm.created_at < sqlc.arg(to_time)::timestamp
+),
+steps AS (
+    SELECT
+        segment_day,
+        id,
+        captured_at,
+        location,
+        altitude,
+        ST_Distance(location, lag(location) OVER w) AS step_m,
+        altitude - lag(altitude) OVER w AS climb_m
+    FROM points
+    WINDOW w AS (PARTITION BY segment_day ORDER BY captured_at, id)
+)
+SELECT
+    segment_day::date AS segment_day,
+    count(*)::integer AS photo_count,
+    min(captured_at)::timestamp AS started_at,
+    max(captured_at)::timestamp AS ended_at,
+    ST_AsGeoJSON(ST_MakeLine(location::geometry ORDER BY captured_at, id))::text AS line_geojson,
+    COALESCE(sum(step_m), 0)::double precision AS distance_m,
+    COALESCE(sum(GREATEST(climb_m, 0)), 0)::double precision AS elevation_gain_m,
+    (count(altitude) > 0)::boolean AS has_altitude
+FROM steps
+GROUP BY segment_day
+ORDER BY segment_day;
+
+-- name: ListRoutePlaces :many
+-- Photos within cluster_radius_m of each other on the same segment are one place.
+WITH points AS (
+    SELECT
+        pm.created_at AS captured_at,
+        pm.location,
+        CASE WHEN sqlc.arg(per_trip)::boolean
+            THEN sqlc.arg(from_time)::timestamp::date
+            ELSE pm.created_at::date
+        END AS segment_day
+    FROM photo p
+    JOIN photo_metadata pm ON pm.id = p.id
+    WHERE p.owner_id = sqlc.arg(owner_id)
+      AND p.deleted_at IS NULL
+      AND pm.location IS NOT NULL
+      AND pm.created_at >= sqlc.arg(from_time)::timestamp
+      AND pm.created_at < sqlc.arg(to_time)::timestamp
+),
+segments AS (
+    SELECT
+        segment_day,
+        captured_at,
+        location,
+        avg(ST_Y(location::geometry)) OVER (PARTITION BY segment_day) AS segment_latitude
+    FROM points
+),
+clustered AS (
+    -- Web Mercator stretches distances by 1/cos(latitude), so the radius is stretched by
+    -- the same factor at the segment's mean latitude.
+    SELECT
+        segment_day,
+        captured_at,
+        location,
+        ST_ClusterDBSCAN(
+            ST_Transform(location::geometry, 3857),
+            eps := sqlc.arg(cluster_radius_m)::double precision
+                / GREATEST(cos(radians(segment_latitude)), 0.01),
+            minpoints := 1
+        ) OVER (PARTITION BY segment_day) AS cluster_id
+    FROM segments
+)
+SELECT
+    segment_day::date AS segment_day,
+    count(*)::integer AS photo_count,
+    min(captured_at)::timestamp AS arrived_at,
+    max(captured_at)::timestamp AS left_at,
+    ST_Y(ST_Centroid(ST_Collect(location::geometry)))::double precision AS latitude,
+    ST_X(ST_Centroid(ST_Collect(location::geometry)))::double precision AS longitude
+FROM clustered
+GROUP BY segment_day, cluster_id
+ORDER BY segment_day, arrived_at;
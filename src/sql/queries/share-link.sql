@@ -0,0 +1,23 @@
+-- name: CreateShareLink :one
+INSERT INTO share_link (owner_id, token_hash, photo_id, album_id, password_hash, allow_download, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetShareLink :one
+SELECT * FROM share_link
+WHERE id = $1;
+
+-- name: GetShareLinkByTokenHash :one
+SELECT * FROM share_link
+WHERE token_hash = $1;
+
+-- name: ListShareLinks :many
+SELECT * FROM share_link
+WHERE owner_id = $1
+ORDER BY created_at DESC, id;
+
+-- name: RevokeShareLink :exec
+UPDATE share_link
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND revoked_at IS NULL;
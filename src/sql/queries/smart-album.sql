@@ -0,0 +1,25 @@
+-- name: CreateSmartAlbum :one
+INSERT INTO smart_album (owner_id, title, description, query)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetSmartAlbum :one
+SELECT * FROM smart_album
+WHERE id = $1;
+
+-- name: ListSmartAlbums :many
+SELECT * FROM smart_album
+WHERE owner_id = $1
+ORDER BY created_at DESC, id;
+
+-- name: UpdateSmartAlbum :execrows
+UPDATE smart_album
+SET title = COALESCE(sqlc.narg(title), title),
+    description = CASE WHEN sqlc.arg(set_description)::boolean THEN sqlc.narg(description) ELSE description END,
+    query = CASE WHEN sqlc.arg(set_query)::boolean THEN sqlc.arg(query)::jsonb ELSE query END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteSmartAlbum :execrows
+DELETE FROM smart_album
+WHERE id = $1;
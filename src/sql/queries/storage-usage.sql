@@ -0,0 +1,45 @@
+-- name: GetStorageUsage :one
+SELECT * FROM storage_usage
+WHERE owner_id = $1;
+
+-- name: EnsureStorageUsage :exec
+INSERT INTO storage_usage (owner_id)
+VALUES ($1)
+ON CONFLICT (owner_id) DO NOTHING;
+
+-- name: AddPhotoUsage :execrows
+-- Counts a new original against the owner's usage unless that would exceed the limits; a
+-- limit of 0 means unlimited. The row lock serializes concurrent uploads of the same owner.
+UPDATE storage_usage
+SET photo_count = photo_count + 1,
+    original_bytes = original_bytes + sqlc.arg(size_bytes),
+    updated_at = CURRENT_TIMESTAMP
+WHERE owner_id = sqlc.arg(owner_id)
+  AND (sqlc.arg(max_bytes)::bigint = 0
+       OR original_bytes + version_bytes + sqlc.arg(size_bytes) <= sqlc.arg(max_bytes)::bigint)
+  AND (sqlc.arg(max_photos)::bigint = 0 OR photo_count < sqlc.arg(max_photos)::bigint);
+
+-- name: AddVersionUsage :execrows
+-- Counts another version of a photo against the owner's usage unless that would exceed the
+-- byte limit; a limit of 0 means unlimited.
+UPDATE storage_usage
+SET version_bytes = version_bytes + sqlc.arg(size_bytes),
+    updated_at = CURRENT_TIMESTAMP
+WHERE owner_id = sqlc.arg(owner_id)
+  AND (sqlc.arg(max_bytes)::bigint = 0
+       OR original_bytes + version_bytes + sqlc.arg(size_bytes) <= sqlc.arg(max_bytes)::bigint);
+
+-- name: RemovePhotoUsage :exec
+UPDATE storage_usage
+SET photo_count = GREATEST(photo_count - 1, 0),
+    original_bytes = GREATEST(original_bytes - sqlc.arg(original_bytes), 0),
+    version_bytes = GREATEST(version_bytes - sqlc.arg(version_bytes), 0),
+    updated_at = CURRENT_TIMESTAMP
+WHERE owner_id = sqlc.arg(owner_id);
+
+-- name: SetStorageTier :exec
+INSERT INTO storage_usage (owner_id, tier)
+VALUES ($1, $2)
+ON CONFLICT (owner_id) DO UPDATE
+SET tier = EXCLUDED.tier,
+    updated_at = CURRENT_TIMESTAMP;
@@ -0,0 +1,70 @@
+-- name: CreateTags :exec
+-- Creates the tags that do not exist yet for the owner.
+INSERT INTO tag (owner_id, name)
+SELECT sqlc.arg(owner_id), u.name
+FROM unnest(sqlc.arg(names)::text[]) AS u(name)
+ON CONFLICT (owner_id, lower(name)) DO NOTHING;
+
+-- name: AddPhotoTags :execrows
+INSERT INTO photo_tag (photo_id, tag_id, source)
+SELECT sqlc.arg(photo_id), t.id, sqlc.arg(source)
+FROM tag t
+WHERE t.owner_id = sqlc.arg(owner_id)
+  AND lower(t.name) = ANY(sqlc.arg(lower_names)::text[])
+ON CONFLICT (photo_id, tag_id) DO NOTHING;
+
+-- name: RemovePhotoTags :execrows
+DELETE FROM photo_tag pt
+USING tag t
+WHERE pt.tag_id = t.id
+  AND pt.photo_id = sqlc.arg(photo_id)
+  AND lower(t.name) = ANY(sqlc.arg(lower_names)::text[]);
+
+-- name: ListPhotoTags :many
+SELECT t.name
+FROM photo_tag pt
+JOIN tag t ON t.id = pt.tag_id
+WHERE pt.photo_id = $1
+ORDER BY lower(t.name);
+
+-- name: SearchTags :many
+SELECT
+    t.id,
+    t.name,
+    count(p.id)::integer AS usage_count
+FROM tag t
+LEFT JOIN photo_tag pt ON pt.tag_id = t.id
+LEFT JOIN photo p ON p.id = pt.photo_id AND p.deleted_at IS NULL
+WHERE t.owner_id = sqlc.arg(owner_id)
+  AND lower(t.name) LIKE sqlc.arg(lower_prefix)::text || '%'
+GROUP BY t.id
+ORDER BY usage_count DESC, lower(t.name)
+LIMIT sqlc.arg(max_results);
+
+-- name: ListPhotosByTags :many
+-- With match_all a photo needs every tag, otherwise any of them.
+SELECT
+    p.id,
+    p.owner_id,
+    p.description,
+    p.photo_url,
+    p.created_at,
+    p.status,
+    (pm.location IS NOT NULL)::boolean AS has_location,
+    COALESCE(ST_Y(pm.location::geometry), 0)::double precision AS latitude,
+    COALESCE(ST_X(pm.location::geometry), 0)::double precision AS longitude,
+    pm.created_at AS captured_at,
+    photo_location_is_private(p.owner_id, pm.location)::boolean AS location_private
+FROM photo p
+JOIN photo_tag pt ON pt.photo_id = p.id
+JOIN tag t ON t.id = pt.tag_id
+LEFT JOIN photo_metadata pm ON pm.id = p.id
+WHERE p.owner_id = sqlc.arg(owner_id)
+  AND p.deleted_at IS NULL
+  AND t.owner_id = sqlc.arg(owner_id)
+  AND lower(t.name) = ANY(sqlc.arg(lower_names)::text[])
+GROUP BY p.id, pm.id
+HAVING NOT sqlc.arg(match_all)::boolean
+    OR count(DISTINCT t.id) = cardinality(sqlc.arg(lower_names)::text[])
+ORDER BY p.created_at DESC, p.id
+LIMIT sqlc.arg(max_results) OFFSET sqlc.arg(skip);
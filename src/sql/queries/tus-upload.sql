@@ -0,0 +1,71 @@
+-- name: CreateTusUpload :one
+INSERT INTO tus_upload (id, owner_id, upload_length, metadata, file_name, description, storage_key, s3_upload_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetTusUpload :one
+SELECT * FROM tus_upload
+WHERE id = $1;
+
+-- name: ClaimTusUpload :one
+-- Locks the upload for a write starting at the given offset, unless another request holds
+-- the lock or the offset has moved on.
+UPDATE tus_upload
+SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(lease_seconds)::double precision),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+  AND upload_offset = sqlc.arg(upload_offset)
+  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: ReleaseTusUpload :exec
+UPDATE tus_upload
+SET locked_until = NULL
+WHERE id = $1;
+
+-- name: AddTusUploadPart :exec
+INSERT INTO tus_upload_part (upload_id, part_number, etag, size_bytes)
+VALUES ($1, $2, $3, $4);
+
+-- name: UpdateTusUploadProgress :exec
+-- Also renews the write lock, so a write holds it for as long as it makes progress.
+UPDATE tus_upload
+SET upload_offset = sqlc.arg(upload_offset),
+    parts_bytes = sqlc.arg(parts_bytes),
+    locked_until = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(lease_seconds)::double precision),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: ListTusUploadParts :many
+SELECT * FROM tus_upload_part
+WHERE upload_id = $1
+ORDER BY part_number;
+
+-- name: MarkTusUploadAssembled :exec
+UPDATE tus_upload
+SET assembled_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: SetTusUploadPhoto :exec
+UPDATE tus_upload
+SET photo_id = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteTusUpload :execrows
+DELETE FROM tus_upload
+WHERE id = $1;
+
+-- name: ListStaleTusUploads :many
+-- Uploads not written to since updated_before and not being written to now, oldest first.
+-- Assembled files a photo was created from are left out, even when the upload does not
+-- know the photo because recording it failed.
+SELECT * FROM tus_upload
+WHERE updated_at < sqlc.arg(updated_before)
+  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+  AND (assembled_at IS NULL
+       OR photo_id IS NOT NULL
+       OR NOT EXISTS (SELECT 1 FROM photo_version v WHERE v.storage_key = tus_upload.storage_key))
+ORDER BY updated_at
+LIMIT sqlc.arg(max_uploads);
@@ -0,0 +1,45 @@
+-- name: CreateUploadIntent :one
+INSERT INTO upload_intent (id, owner_id, storage_key, file_name, description, content_type, size_bytes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetUploadIntent :one
+SELECT * FROM upload_intent
+WHERE id = $1;
+
+-- name: ClaimUploadIntent :one
+-- Locks a pending intent for completion, unless it has expired or another request holds
+-- the lock.
+UPDATE upload_intent
+SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(lease_seconds)::double precision)
+WHERE id = sqlc.arg(id)
+  AND completed_at IS NULL
+  AND expires_at > CURRENT_TIMESTAMP
+  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: ReleaseUploadIntent :exec
+UPDATE upload_intent
+SET locked_until = NULL
+WHERE id = $1;
+
+-- name: MarkUploadIntentCompleted :exec
+UPDATE upload_intent
+SET completed_at = CURRENT_TIMESTAMP,
+    locked_until = NULL
+WHERE id = $1;
+
+-- name: ListExpiredUploadIntents :many
+-- Pending intents past their expiry, leaving out those whose photo was created but could
+-- not be marked completed.
+SELECT * FROM upload_intent
+WHERE completed_at IS NULL
+  AND expires_at < CURRENT_TIMESTAMP
+  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+  AND NOT EXISTS (SELECT 1 FROM photo WHERE photo.id = upload_intent.id)
+ORDER BY expires_at
+LIMIT $1;
+
+-- name: DeleteUploadIntent :exec
+DELETE FROM upload_intent
+WHERE id = $1;
@@ -0,0 +1,98 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoint (owner_id, api_key_id, url, secret, events)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoint
+WHERE id = $1;
+
+-- name: ListWebhookEndpoints :many
+-- Lists the owner's endpoints, or only those of one of its API keys.
+SELECT * FROM webhook_endpoint
+WHERE owner_id = sqlc.arg(owner_id)
+  AND (sqlc.narg(api_key_id)::uuid IS NULL OR api_key_id = sqlc.narg(api_key_id))
+ORDER BY created_at DESC, id;
+
+-- name: ListWebhookEndpointsForEvent :many
+-- Lists the enabled endpoints subscribed to an event of the owner, leaving out those
+-- whose API key is no longer valid.
+SELECT * FROM webhook_endpoint
+WHERE owner_id = sqlc.arg(owner_id)
+  AND enabled
+  AND sqlc.arg(event_type)::text = ANY(events)
+  AND (api_key_id IS NULL OR EXISTS (
+      SELECT 1 FROM api_key
+      WHERE api_key.id = webhook_endpoint.api_key_id
+        AND api_key.revoked_at IS NULL
+        AND (api_key.expires_at IS NULL OR api_key.expires_at > CURRENT_TIMESTAMP)
+  ));
+
+-- name: UpdateWebhookEndpoint :execrows
+-- Enabling an endpoint clears its failures; disabling it records when.
+UPDATE webhook_endpoint
+SET url = COALESCE(sqlc.narg(url), url),
+    events = CASE WHEN sqlc.arg(set_events)::boolean THEN sqlc.arg(events)::text[] ELSE events END,
+    enabled = COALESCE(sqlc.narg(enabled)::boolean, enabled),
+    consecutive_failures = CASE WHEN sqlc.narg(enabled)::boolean THEN 0 ELSE consecutive_failures END,
+    disabled_at = CASE
+        WHEN sqlc.narg(enabled)::boolean IS NULL THEN disabled_at
+        WHEN sqlc.narg(enabled)::boolean THEN NULL
+        ELSE COALESCE(disabled_at, CURRENT_TIMESTAMP)
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoint
+WHERE id = $1;
+
+-- name: ResetWebhookEndpointFailures :exec
+UPDATE webhook_endpoint
+SET consecutive_failures = 0
+WHERE id = $1 AND consecutive_failures > 0;
+
+-- name: RecordWebhookEndpointFailure :one
+-- Counts a failed delivery and disables the endpoint once disable_after deliveries in a
+-- row have failed. Returns whether the endpoint is still enabled.
+UPDATE webhook_endpoint
+SET consecutive_failures = consecutive_failures + 1,
+    enabled = enabled AND consecutive_failures + 1 < sqlc.arg(disable_after)::int,
+    disabled_at = CASE
+        WHEN enabled AND consecutive_failures + 1 >= sqlc.arg(disable_after)::int THEN CURRENT_TIMESTAMP
+        ELSE disabled_at
+    END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING enabled;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_delivery (id, endpoint_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_delivery
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_delivery
+WHERE endpoint_id = $1
+ORDER BY created_at DESC, id
+LIMIT $2;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_delivery
+SET status = sqlc.arg(status),
+    attempts = attempts + 1,
+    response_status = sqlc.narg(response_status),
+    response_body = sqlc.narg(response_body),
+    last_error = sqlc.narg(last_error),
+    last_attempt_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: SetWebhookDeliveryStatus :exec
+UPDATE webhook_delivery
+SET status = sqlc.arg(status),
+    last_error = COALESCE(sqlc.narg(last_error), last_error)
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Points used to be written as (latitude, longitude); PostGIS expects (x, y) = (longitude, latitude).
+UPDATE photo_metadata
+SET location = ST_SetSRID(ST_MakePoint(ST_Y(location::geometry), ST_X(location::geometry)), 4326)::geography
+WHERE location IS NOT NULL;
+
+CREATE INDEX idx_photo_metadata_location ON photo_metadata USING GIST (location);
+CREATE INDEX idx_photo_metadata_created_at ON photo_metadata (created_at);
+CREATE INDEX idx_photo_owner_id ON photo (owner_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_photo_owner_id;
+DROP INDEX IF EXISTS idx_photo_metadata_created_at;
+DROP INDEX IF EXISTS idx_photo_metadata_location;
+
+UPDATE photo_metadata
+SET location = ST_SetSRID(ST_MakePoint(ST_Y(location::geometry), ST_X(location::geometry)), 4326)::geography
+WHERE location IS NOT NULL;
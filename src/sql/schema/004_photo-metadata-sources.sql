@@ -0,0 +1,17 @@
+-- +goose Up
+-- photo_metadata.created_at holds the capture time; each value records where it came from.
+ALTER TABLE photo_metadata
+    ADD COLUMN location_source VARCHAR(16),
+    ADD COLUMN created_at_source VARCHAR(16),
+    ADD CONSTRAINT chk_photo_metadata_location_source
+        CHECK (location_source IN ('exif', 'gpx', 'manual', 'geocoder')),
+    ADD CONSTRAINT chk_photo_metadata_created_at_source
+        CHECK (created_at_source IN ('exif', 'gpx', 'manual', 'geocoder'));
+
+UPDATE photo_metadata SET location_source = 'exif' WHERE location IS NOT NULL;
+UPDATE photo_metadata SET created_at_source = 'exif' WHERE created_at IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE photo_metadata
+    DROP COLUMN created_at_source,
+    DROP COLUMN location_source;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE private_zone (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('circle', 'polygon')),
+    area GEOGRAPHY(Polygon, 4326) NOT NULL,
+    radius_m DOUBLE PRECISION,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_private_zone_owner_id ON private_zone (owner_id);
+CREATE INDEX idx_private_zone_area ON private_zone USING GIST (area);
+
+-- A location is private when it falls inside any of its owner's zones.
+-- +goose StatementBegin
+CREATE FUNCTION photo_location_is_private(p_owner_id UUID, p_location GEOGRAPHY)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT p_location IS NOT NULL AND EXISTS (
+        SELECT 1
+        FROM private_zone z
+        WHERE z.owner_id = p_owner_id
+          AND ST_Covers(z.area, p_location)
+    );
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS photo_location_is_private(UUID, GEOGRAPHY);
+DROP TABLE private_zone;
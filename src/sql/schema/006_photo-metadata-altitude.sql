@@ -0,0 +1,6 @@
+-- +goose Up
+-- Meters above sea level, from EXIF GPSAltitude. It follows the location it was recorded with.
+ALTER TABLE photo_metadata ADD COLUMN altitude DOUBLE PRECISION;
+
+-- +goose Down
+ALTER TABLE photo_metadata DROP COLUMN altitude;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE album (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(1024),
+    cover_photo_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_album_cover_photo
+        FOREIGN KEY (cover_photo_id)
+        REFERENCES photo (id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX idx_album_owner_id ON album (owner_id);
+
+CREATE TABLE album_photo (
+    album_id UUID NOT NULL,
+    photo_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (album_id, photo_id),
+    CONSTRAINT fk_album_photo_album
+        FOREIGN KEY (album_id)
+        REFERENCES album (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_album_photo_photo
+        FOREIGN KEY (photo_id)
+        REFERENCES photo (id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_album_photo_position ON album_photo (album_id, position);
+CREATE INDEX idx_album_photo_photo_id ON album_photo (photo_id);
+
+-- +goose Down
+DROP TABLE album_photo;
+DROP TABLE album;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE tag (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tag names are unique per owner regardless of case; the first spelling used is kept.
+CREATE UNIQUE INDEX idx_tag_owner_lower_name ON tag (owner_id, lower(name));
+CREATE INDEX idx_tag_owner_lower_name_prefix ON tag (owner_id, lower(name) text_pattern_ops);
+
+CREATE TABLE photo_tag (
+    photo_id UUID NOT NULL,
+    tag_id UUID NOT NULL,
+    source VARCHAR(16) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'iptc', 'xmp')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (photo_id, tag_id),
+    CONSTRAINT fk_photo_tag_photo
+        FOREIGN KEY (photo_id)
+        REFERENCES photo (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_photo_tag_tag
+        FOREIGN KEY (tag_id)
+        REFERENCES tag (id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_photo_tag_tag_id ON photo_tag (tag_id);
+
+-- +goose Down
+DROP TABLE photo_tag;
+DROP TABLE tag;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- The text-search configuration is stored per row so the generated column stays immutable.
+-- After changing SEARCH_LANGUAGE, existing rows can be re-indexed with
+-- UPDATE photo SET search_language = '<config>'.
+ALTER TABLE photo
+    ADD COLUMN search_language regconfig NOT NULL DEFAULT 'english';
+
+ALTER TABLE photo
+    ADD COLUMN search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector(search_language, coalesce(description, ''))) STORED;
+
+CREATE INDEX idx_photo_search_vector ON photo USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_photo_search_vector;
+ALTER TABLE photo DROP COLUMN search_vector;
+ALTER TABLE photo DROP COLUMN search_language;
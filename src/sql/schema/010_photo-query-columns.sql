@@ -0,0 +1,33 @@
+-- +goose Up
+-- Image format detected at upload (jpeg, png, gif, webp, heic, tiff).
+ALTER TABLE photo ADD COLUMN format VARCHAR(16);
+
+-- Older photos only have their file name to go by.
+UPDATE photo SET format = lower(substring(photo_url FROM '\.([A-Za-z0-9]+)$'));
+UPDATE photo SET format = 'jpeg' WHERE format IN ('jpg', 'jpe');
+UPDATE photo SET format = 'tiff' WHERE format = 'tif';
+UPDATE photo SET format = 'heic' WHERE format = 'heif';
+UPDATE photo SET format = NULL WHERE format NOT IN ('jpeg', 'png', 'gif', 'webp', 'heic', 'tiff');
+
+-- Camera that took the photo, from EXIF Make and Model.
+ALTER TABLE photo_metadata
+    ADD COLUMN camera_make VARCHAR(64),
+    ADD COLUMN camera_model VARCHAR(64);
+
+-- Keyset pagination over the owner's photos and the filters of the photo query endpoint.
+CREATE INDEX idx_photo_owner_created_at ON photo (owner_id, created_at DESC, id DESC);
+CREATE INDEX idx_photo_owner_format ON photo (owner_id, format);
+CREATE INDEX idx_photo_metadata_camera ON photo_metadata (lower(camera_make), lower(camera_model));
+CREATE INDEX idx_photo_metadata_camera_model ON photo_metadata (lower(camera_model));
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_photo_metadata_camera_model;
+DROP INDEX IF EXISTS idx_photo_metadata_camera;
+DROP INDEX IF EXISTS idx_photo_owner_format;
+DROP INDEX IF EXISTS idx_photo_owner_created_at;
+
+ALTER TABLE photo_metadata
+    DROP COLUMN camera_model,
+    DROP COLUMN camera_make;
+
+ALTER TABLE photo DROP COLUMN format;
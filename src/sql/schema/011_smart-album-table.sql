@@ -0,0 +1,17 @@
+-- +goose Up
+-- A smart album is a saved photo query (the POST /v1/photos/query document without
+-- paging) that is evaluated again on every read.
+CREATE TABLE smart_album (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(1024),
+    query JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_smart_album_owner_id ON smart_album (owner_id);
+
+-- +goose Down
+DROP TABLE smart_album;
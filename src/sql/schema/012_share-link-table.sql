@@ -0,0 +1,31 @@
+-- +goose Up
+-- Public links to a photo or an album. Only the SHA-256 hash of the token is stored, so a
+-- leaked database does not leak working links; passwords are bcrypt hashes.
+CREATE TABLE share_link (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL,
+    token_hash BYTEA NOT NULL,
+    photo_id UUID,
+    album_id UUID,
+    password_hash VARCHAR(255),
+    allow_download BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_share_link_target
+        CHECK ((photo_id IS NULL) <> (album_id IS NULL)),
+    CONSTRAINT fk_share_link_photo
+        FOREIGN KEY (photo_id)
+        REFERENCES photo (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_share_link_album
+        FOREIGN KEY (album_id)
+        REFERENCES album (id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_share_link_token_hash ON share_link (token_hash);
+CREATE INDEX idx_share_link_owner_id ON share_link (owner_id);
+
+-- +goose Down
+DROP TABLE share_link;
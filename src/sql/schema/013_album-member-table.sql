@@ -0,0 +1,24 @@
+-- +goose Up
+-- Users an album is shared with. The owner is not a member: album.owner_id always has full
+-- control. Viewers see the album and its photos, contributors can also add their own
+-- photos and editors can change the album.
+CREATE TABLE album_member (
+    album_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    added_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (album_id, user_id),
+    CONSTRAINT chk_album_member_role
+        CHECK (role IN ('viewer', 'contributor', 'editor')),
+    CONSTRAINT fk_album_member_album
+        FOREIGN KEY (album_id)
+        REFERENCES album (id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_album_member_user_id ON album_member (user_id);
+
+-- +goose Down
+DROP TABLE album_member;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Keys for service-to-service callers. Only the SHA-256 hash of a key is stored; the prefix
+-- is kept so keys can be told apart in listings. A key bound to an owner always acts as
+-- that user.
+CREATE TABLE api_key (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash BYTEA NOT NULL,
+    scopes TEXT[] NOT NULL,
+    owner_id UUID,
+    created_by UUID,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_api_key_scopes
+        CHECK (cardinality(scopes) > 0 AND scopes <@ ARRAY['photos:read', 'photos:write', 'admin'])
+);
+
+CREATE UNIQUE INDEX idx_api_key_key_hash ON api_key (key_hash);
+
+-- +goose Down
+DROP TABLE api_key;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Size of the original file in bytes, counted against the owner's storage quota.
+ALTER TABLE photo ADD COLUMN size_bytes BIGINT NOT NULL DEFAULT 0;
+
+-- Storage used per owner, updated in the same transaction that creates or deletes a photo.
+-- Renditions are files derived from the originals, such as thumbnails and previews. tier
+-- names one of the configured quota tiers; NULL means the default tier.
+CREATE TABLE storage_usage (
+    owner_id UUID PRIMARY KEY,
+    tier VARCHAR(32),
+    photo_count BIGINT NOT NULL DEFAULT 0,
+    original_bytes BIGINT NOT NULL DEFAULT 0,
+    rendition_bytes BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_storage_usage_non_negative
+        CHECK (photo_count >= 0 AND original_bytes >= 0 AND rendition_bytes >= 0)
+);
+
+-- The sizes of existing photos are unknown; count them so photo limits still apply.
+INSERT INTO storage_usage (owner_id, photo_count)
+SELECT owner_id, count(*) FROM photo GROUP BY owner_id;
+
+-- +goose Down
+DROP TABLE storage_usage;
+
+ALTER TABLE photo DROP COLUMN size_bytes;
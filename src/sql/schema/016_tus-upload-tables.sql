@@ -0,0 +1,48 @@
+-- +goose Up
+-- Resumable uploads (tus). The bytes received so far are upload_offset: parts_bytes of them
+-- are stored as parts of the S3 multipart upload s3_upload_id, the rest in a staging
+-- object until there are enough for another part. Once all bytes are in, the parts are
+-- assembled into storage_key (assembled_at) and the photo is created from it (photo_id).
+CREATE TABLE tus_upload (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL,
+    upload_length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    parts_bytes BIGINT NOT NULL DEFAULT 0,
+    -- The Upload-Metadata header as sent, returned on HEAD requests.
+    metadata TEXT NOT NULL DEFAULT '',
+    file_name VARCHAR(100) NOT NULL,
+    description VARCHAR(255),
+    storage_key VARCHAR(255) NOT NULL,
+    s3_upload_id TEXT NOT NULL,
+    assembled_at TIMESTAMP,
+    photo_id UUID,
+    -- Set while a request is writing to the upload, so concurrent writes are refused.
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_tus_upload_offset
+        CHECK (upload_length > 0 AND parts_bytes <= upload_offset AND upload_offset <= upload_length),
+    CONSTRAINT fk_tus_upload_photo
+        FOREIGN KEY (photo_id)
+        REFERENCES photo (id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX idx_tus_upload_owner_id ON tus_upload (owner_id);
+
+CREATE TABLE tus_upload_part (
+    upload_id UUID NOT NULL,
+    part_number INT NOT NULL,
+    etag TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    PRIMARY KEY (upload_id, part_number),
+    CONSTRAINT fk_tus_upload_part_upload
+        FOREIGN KEY (upload_id)
+        REFERENCES tus_upload (id)
+        ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE tus_upload_part;
+DROP TABLE tus_upload;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Uploads that go straight to the bucket through a presigned request. The intent reserves
+-- the photo ID (id) and the object key; completing it creates the photo from the object.
+-- Intents not completed by expires_at are deleted along with whatever was uploaded.
+CREATE TABLE upload_intent (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    file_name VARCHAR(100) NOT NULL,
+    description VARCHAR(255),
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    expires_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    -- Set while a request is completing the intent, so concurrent completions are refused.
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_upload_intent_pending ON upload_intent (expires_at)
+    WHERE completed_at IS NULL;
+
+-- +goose Down
+DROP TABLE upload_intent;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Idempotency-Key headers seen per caller and route (scope). A row without completed_at
+-- is a request in progress, which holds the key until locked_until; once it finishes,
+-- its response is kept to be replayed to retries with the same fingerprint.
+CREATE TABLE idempotency_key (
+    owner_id UUID NOT NULL,
+    scope VARCHAR(50) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    -- SHA-256 of the request, so a key reused for a different request is refused.
+    fingerprint VARCHAR(64) NOT NULL,
+    response_status INT,
+    response_content_type VARCHAR(255),
+    response_body BYTEA,
+    locked_until TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner_id, scope, key)
+);
+
+CREATE INDEX idx_idempotency_key_created_at ON idempotency_key (created_at);
+
+-- +goose Down
+DROP TABLE idempotency_key;
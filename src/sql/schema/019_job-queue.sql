@@ -0,0 +1,30 @@
+-- +goose Up
+-- processing while the background jobs that follow an upload run, failed when they gave up.
+ALTER TABLE photo ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'ready'
+    CONSTRAINT chk_photo_status CHECK (status IN ('processing', 'ready', 'failed'));
+
+-- Background jobs. Workers claim runnable jobs with FOR UPDATE SKIP LOCKED and hold them
+-- until locked_until; a job whose worker died is claimed again once that passes. Failed
+-- attempts are retried at run_at with exponential backoff until max_attempts, after which
+-- the job is kept as dead for inspection. Finished jobs are deleted.
+CREATE TABLE job (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_job_status CHECK (status IN ('pending', 'running', 'dead'))
+);
+
+CREATE INDEX idx_job_pending ON job (run_at) WHERE status = 'pending';
+CREATE INDEX idx_job_running ON job (locked_until) WHERE status = 'running';
+
+-- +goose Down
+DROP TABLE job;
+ALTER TABLE photo DROP COLUMN status;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Webhook endpoints that receive an owner's events. Endpoints created with an API key
+-- belong to that key too and stop receiving events once it is revoked or expires.
+CREATE TABLE webhook_endpoint (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL,
+    api_key_id UUID REFERENCES api_key(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    -- Signs every payload with HMAC-SHA256; shown to the owner once, when created.
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Deliveries that failed every attempt since the last success; the endpoint is
+    -- disabled when this reaches the configured limit.
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_endpoint_owner ON webhook_endpoint (owner_id);
+
+-- The delivery log: one row per event sent to an endpoint, with the outcome of its last
+-- attempt. Redeliveries are new rows carrying the same event_id.
+CREATE TABLE webhook_delivery (
+    id UUID PRIMARY KEY,
+    endpoint_id UUID NOT NULL REFERENCES webhook_endpoint(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    response_status INT,
+    response_body TEXT,
+    last_error TEXT,
+    last_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_webhook_delivery_status CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX idx_webhook_delivery_endpoint ON webhook_delivery (endpoint_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE webhook_delivery;
+DROP TABLE webhook_endpoint;
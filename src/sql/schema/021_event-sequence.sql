@@ -0,0 +1,7 @@
+-- +goose Up
+-- Numbers the events published to the events channel across instances, so event stream
+-- clients can resume after the last one they saw.
+CREATE SEQUENCE event_seq;
+
+-- +goose Down
+DROP SEQUENCE event_seq;
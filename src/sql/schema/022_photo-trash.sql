@@ -0,0 +1,16 @@
+-- +goose Up
+-- Deleted photos stay in the owner's trash until restored or purged; every read leaves
+-- them out. They keep counting against the owner's storage until purged.
+ALTER TABLE photo ADD COLUMN deleted_at TIMESTAMP;
+
+-- The trash listing and the purge of photos past retention.
+CREATE INDEX idx_photo_owner_deleted_at ON photo (owner_id, deleted_at DESC, id)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_photo_deleted_at ON photo (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_photo_deleted_at;
+DROP INDEX IF EXISTS idx_photo_owner_deleted_at;
+
+ALTER TABLE photo DROP COLUMN deleted_at;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Every file a photo has had; photo_url, size_bytes and format of the photo follow its
+-- current_version. Without bucket versioning each version is stored under its own key.
+-- With it, new versions overwrite the photo's key and storage_version_id names the S3
+-- object version. sha256 is the hex digest of the file, unknown for files that never
+-- passed through the service whole. metadata is what was extracted from the file, empty
+-- until it has been.
+CREATE TABLE photo_version (
+    photo_id UUID NOT NULL REFERENCES photo(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    storage_version_id VARCHAR(1024),
+    sha256 CHAR(64),
+    size_bytes BIGINT NOT NULL,
+    format VARCHAR(16),
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (photo_id, version)
+);
+
+ALTER TABLE photo ADD COLUMN current_version INT NOT NULL DEFAULT 1;
+
+-- Existing photos start out with their file as version 1.
+INSERT INTO photo_version (photo_id, version, storage_key, size_bytes, format, created_at)
+SELECT id, 1, photo_url, size_bytes, format, created_at FROM photo;
+
+-- +goose Down
+ALTER TABLE photo DROP COLUMN current_version;
+
+DROP TABLE photo_version;
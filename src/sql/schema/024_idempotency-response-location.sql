@@ -0,0 +1,6 @@
+-- +goose Up
+-- The Location header of a stored response, replayed with it.
+ALTER TABLE idempotency_key ADD COLUMN response_location VARCHAR(1024);
+
+-- +goose Down
+ALTER TABLE idempotency_key DROP COLUMN response_location;
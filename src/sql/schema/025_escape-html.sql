@@ -0,0 +1,13 @@
+-- +goose Up
+-- Search headlines are HTML: descriptions are escaped before ts_headline adds its <mark> tags.
+-- +goose StatementBegin
+CREATE FUNCTION escape_html(p_text TEXT)
+RETURNS TEXT
+LANGUAGE sql IMMUTABLE STRICT AS $$
+    SELECT replace(replace(replace(replace(replace(p_text,
+        '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;');
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS escape_html(TEXT);
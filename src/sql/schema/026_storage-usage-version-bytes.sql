@@ -0,0 +1,24 @@
+-- +goose Up
+-- The service stores no renditions; bytes of replaced files are counted apart from the
+-- originals instead. original_bytes keeps the first file of every photo, version_bytes the
+-- files of its later versions.
+ALTER TABLE storage_usage RENAME COLUMN rendition_bytes TO version_bytes;
+
+UPDATE storage_usage u
+SET original_bytes = GREATEST(u.original_bytes - s.version_bytes, 0),
+    version_bytes = s.version_bytes
+FROM (
+    SELECT p.owner_id, sum(v.size_bytes) AS version_bytes
+    FROM photo_version v
+    JOIN photo p ON p.id = v.photo_id
+    WHERE v.version > 1
+    GROUP BY p.owner_id
+) s
+WHERE s.owner_id = u.owner_id;
+
+-- +goose Down
+UPDATE storage_usage
+SET original_bytes = original_bytes + version_bytes,
+    version_bytes = 0;
+
+ALTER TABLE storage_usage RENAME COLUMN version_bytes TO rendition_bytes;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Keys not bound to an owner only act as the user named in X-On-Behalf-Of when they were
+-- minted for it; keys bound to an owner never can.
+ALTER TABLE api_key ADD COLUMN on_behalf_of BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE api_key ADD CONSTRAINT chk_api_key_on_behalf_of
+    CHECK (NOT (on_behalf_of AND owner_id IS NOT NULL));
+
+-- +goose Down
+ALTER TABLE api_key DROP CONSTRAINT chk_api_key_on_behalf_of;
+ALTER TABLE api_key DROP COLUMN on_behalf_of;